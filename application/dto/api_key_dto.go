@@ -0,0 +1,39 @@
+package dto
+
+import "time"
+
+// CreateAPIKeyRequest represents the API key creation request body
+type CreateAPIKeyRequest struct {
+	Name       string     `json:"name"`
+	Scopes     []string   `json:"scopes"`
+	AllowedIPs []string   `json:"allowed_ips"`
+	ExpiresAt  *time.Time `json:"expires_at"`
+}
+
+// APIKey represents the API key data in responses. It never contains the key value.
+type APIKey struct {
+	ID         string     `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	AllowedIPs []string   `json:"allowed_ips"`
+	CreatedBy  int64      `json:"created_by"`
+	CreatedAt  time.Time  `json:"created_at"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+}
+
+// CreateAPIKeyResponse represents the API key creation response
+type CreateAPIKeyResponse struct {
+	Success bool    `json:"success"`
+	Message string  `json:"message"`
+	Key     string  `json:"key"`
+	APIKey  *APIKey `json:"api_key"`
+}
+
+// APIKeyListResponse represents the API key list response
+type APIKeyListResponse struct {
+	Success bool      `json:"success"`
+	Items   []*APIKey `json:"items"`
+}
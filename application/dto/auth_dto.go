@@ -2,17 +2,74 @@ package dto
 
 import "time"
 
-// LoginRequest represents the login request body
+// LoginRequest represents the login request body. Either a password or an
+// Odoo API key may be given; the API key takes precedence.
 type LoginRequest struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
+	APIKey   string `json:"api_key"`
+}
+
+// Secret returns the credential to authenticate with
+func (r *LoginRequest) Secret() string {
+	if r.APIKey != "" {
+		return r.APIKey
+	}
+	return r.Password
+}
+
+// RefreshRequest represents the token refresh request body
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// LogoutRequest represents the logout request body
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
 }
 
 // LoginResponse represents the login response
 type LoginResponse struct {
-	Success bool   `json:"success"`
-	Message string `json:"message"`
-	User    *User  `json:"user,omitempty"`
+	Success   bool                `json:"success"`
+	Message   string              `json:"message"`
+	User      *User               `json:"user,omitempty"`
+	Token     *Token              `json:"token,omitempty"`
+	TwoFactor *TwoFactorChallenge `json:"two_factor,omitempty"`
+}
+
+// TwoFactorChallenge represents the second login step required from TOTP users
+type TwoFactorChallenge struct {
+	Required       bool      `json:"required"`
+	ChallengeToken string    `json:"challenge_token"`
+	ExpiresAt      time.Time `json:"expires_at"`
+}
+
+// TOTPLoginRequest represents the body of the TOTP login step
+type TOTPLoginRequest struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+}
+
+// TOTPCodeRequest represents a request carrying a TOTP code
+type TOTPCodeRequest struct {
+	Code string `json:"code"`
+}
+
+// TOTPEnrollmentResponse represents a started TOTP enrollment
+type TOTPEnrollmentResponse struct {
+	Success    bool   `json:"success"`
+	Message    string `json:"message"`
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+}
+
+// Token represents the token pair issued on login and refresh
+type Token struct {
+	AccessToken      string    `json:"access_token"`
+	TokenType        string    `json:"token_type"`
+	ExpiresAt        time.Time `json:"expires_at"`
+	RefreshToken     string    `json:"refresh_token"`
+	RefreshExpiresAt time.Time `json:"refresh_expires_at"`
 }
 
 // User represents the user data in responses
@@ -28,4 +85,12 @@ type User struct {
 type ErrorResponse struct {
 	Success bool   `json:"success"`
 	Message string `json:"message"`
+	Code    string `json:"code,omitempty"`
 }
+
+// ErrorCodeBackendTimeout marks errors caused by Odoo not answering in time
+const ErrorCodeBackendTimeout = "backend_timeout"
+
+// ErrorCodeBackendUnavailable marks errors caused by Odoo failing or its
+// circuit breaker being open
+const ErrorCodeBackendUnavailable = "backend_unavailable"
@@ -0,0 +1,135 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"nerp_wrapper/domain/entity"
+	"nerp_wrapper/domain/repository"
+	"net/netip"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// apiKeyPrefix marks wrapper API keys so they are recognizable in logs and secret scanners
+const apiKeyPrefix = "nerp_"
+
+var (
+	// ErrInvalidAPIKey is returned when an API key is unknown, revoked, expired or used from a disallowed address
+	ErrInvalidAPIKey = errors.New("invalid api key")
+	// ErrInvalidAPIKeyRequest is returned when an API key cannot be created with the given attributes
+	ErrInvalidAPIKeyRequest = errors.New("invalid api key request")
+)
+
+// apiKeyScopes lists the permissions that may be granted to an API key. API
+// keys act for no Odoo user, so sales:read_team, which narrows sale orders to
+// the user's teams, is not one of them.
+var apiKeyScopes = map[entity.Permission]bool{
+	entity.PermissionUsersRead:    true,
+	entity.PermissionSalesRead:    true,
+	entity.PermissionSalesReadAll: true,
+	entity.PermissionInvoicesRead: true,
+}
+
+// APIKeyService handles machine-to-machine API key business logic
+type APIKeyService struct {
+	apiKeyRepo repository.APIKeyRepository
+}
+
+// NewAPIKeyService creates a new instance of APIKeyService
+func NewAPIKeyService(apiKeyRepo repository.APIKeyRepository) *APIKeyService {
+	return &APIKeyService{apiKeyRepo: apiKeyRepo}
+}
+
+// CreateAPIKey generates a new API key. The key value is returned only once.
+func (s *APIKeyService) CreateAPIKey(tenantID, name string, scopes []entity.Permission, allowedIPs []string, expiresAt *time.Time, createdBy int64) (string, *entity.APIKey, error) {
+	if name == "" {
+		return "", nil, fmt.Errorf("%w: name is required", ErrInvalidAPIKeyRequest)
+	}
+	if len(scopes) == 0 {
+		return "", nil, fmt.Errorf("%w: at least one scope is required", ErrInvalidAPIKeyRequest)
+	}
+	for _, scope := range scopes {
+		if !apiKeyScopes[scope] {
+			return "", nil, fmt.Errorf("%w: unsupported scope %q", ErrInvalidAPIKeyRequest, scope)
+		}
+	}
+	if slices.Contains(scopes, entity.PermissionSalesRead) && !slices.Contains(scopes, entity.PermissionSalesReadAll) {
+		return "", nil, fmt.Errorf("%w: sales:read requires sales:read_all, since an api key has no own sale orders", ErrInvalidAPIKeyRequest)
+	}
+	for _, allowed := range allowedIPs {
+		if _, err := netip.ParsePrefix(allowed); err == nil {
+			continue
+		}
+		if _, err := netip.ParseAddr(allowed); err != nil {
+			return "", nil, fmt.Errorf("%w: invalid IP or CIDR %q", ErrInvalidAPIKeyRequest, allowed)
+		}
+	}
+	now := time.Now()
+	if expiresAt != nil && !expiresAt.After(now) {
+		return "", nil, fmt.Errorf("%w: expires_at must be in the future", ErrInvalidAPIKeyRequest)
+	}
+
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", nil, fmt.Errorf("failed to generate api key: %v", err)
+	}
+	value := apiKeyPrefix + base64.RawURLEncoding.EncodeToString(raw)
+
+	key := &entity.APIKey{
+		ID:         uuid.NewString(),
+		TenantID:   tenantID,
+		Name:       name,
+		Prefix:     value[:len(apiKeyPrefix)+8],
+		KeyHash:    hashAPIKey(value),
+		Scopes:     scopes,
+		AllowedIPs: allowedIPs,
+		CreatedBy:  createdBy,
+		CreatedAt:  now,
+		ExpiresAt:  expiresAt,
+	}
+	if err := s.apiKeyRepo.CreateAPIKey(key); err != nil {
+		return "", nil, err
+	}
+	return value, key, nil
+}
+
+// ListAPIKeys retrieves every API key of a tenant
+func (s *APIKeyService) ListAPIKeys(tenantID string) ([]*entity.APIKey, error) {
+	return s.apiKeyRepo.ListAPIKeys(tenantID)
+}
+
+// RevokeAPIKey revokes an API key of a tenant. Returns false if the key does not exist.
+func (s *APIKeyService) RevokeAPIKey(tenantID, id string) (bool, error) {
+	return s.apiKeyRepo.RevokeAPIKey(tenantID, id, time.Now())
+}
+
+// Authenticate verifies an API key presented from the given client address
+// and records its usage
+func (s *APIKeyService) Authenticate(value, clientIP string) (*entity.APIKey, error) {
+	key, err := s.apiKeyRepo.GetAPIKeyByHash(hashAPIKey(value))
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up api key: %v", err)
+	}
+
+	now := time.Now()
+	if key == nil || !key.IsActive(now) || !key.AllowsIP(clientIP) {
+		return nil, ErrInvalidAPIKey
+	}
+
+	if err := s.apiKeyRepo.TouchAPIKey(key.ID, now); err != nil {
+		return nil, err
+	}
+	key.LastUsedAt = &now
+	return key, nil
+}
+
+func hashAPIKey(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,136 @@
+package service_test
+
+import (
+	"errors"
+	"nerp_wrapper/application/service"
+	"nerp_wrapper/domain/entity"
+	"nerp_wrapper/infrastructure/memory"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCreateAPIKeyValidatesTheRequest(t *testing.T) {
+	past := time.Now().Add(-time.Minute)
+	tests := []struct {
+		name       string
+		keyName    string
+		scopes     []entity.Permission
+		allowedIPs []string
+		expiresAt  *time.Time
+	}{
+		{"no name", "", []entity.Permission{entity.PermissionInvoicesRead}, nil, nil},
+		{"no scope", "erp", nil, nil, nil},
+		{"admin scope", "erp", []entity.Permission{entity.PermissionAdmin}, nil, nil},
+		{"team scope", "erp", []entity.Permission{entity.PermissionSalesReadTeam}, nil, nil},
+		{"sales:read without sales:read_all", "erp", []entity.Permission{entity.PermissionSalesRead}, nil, nil},
+		{"invalid allowlist entry", "erp", []entity.Permission{entity.PermissionInvoicesRead}, []string{"10.0.0.0/33"}, nil},
+		{"expired", "erp", []entity.Permission{entity.PermissionInvoicesRead}, nil, &past},
+	}
+	keys := service.NewAPIKeyService(memory.NewMemoryAPIKeyRepository())
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := keys.CreateAPIKey("default", tt.keyName, tt.scopes, tt.allowedIPs, tt.expiresAt, 2)
+			if !errors.Is(err, service.ErrInvalidAPIKeyRequest) {
+				t.Errorf("CreateAPIKey returned %v, want ErrInvalidAPIKeyRequest", err)
+			}
+		})
+	}
+
+	scopes := []entity.Permission{entity.PermissionSalesRead, entity.PermissionSalesReadAll, entity.PermissionInvoicesRead}
+	if _, _, err := keys.CreateAPIKey("default", "erp", scopes, []string{"10.0.0.0/8", "192.0.2.1", "::1"}, nil, 2); err != nil {
+		t.Errorf("CreateAPIKey of a valid request failed: %v", err)
+	}
+}
+
+func TestAPIKeyIsStoredHashed(t *testing.T) {
+	repo := memory.NewMemoryAPIKeyRepository()
+	keys := service.NewAPIKeyService(repo)
+	value, key, err := keys.CreateAPIKey("default", "erp", []entity.Permission{entity.PermissionInvoicesRead}, nil, nil, 2)
+	if err != nil {
+		t.Fatalf("CreateAPIKey failed: %v", err)
+	}
+	if !strings.HasPrefix(value, "nerp_") || !strings.HasPrefix(value, key.Prefix) {
+		t.Errorf("key value %q does not start with nerp_ and its prefix %q", value, key.Prefix)
+	}
+	if key.KeyHash == "" || strings.Contains(key.KeyHash, value[len(key.Prefix):]) {
+		t.Errorf("key hash %q reveals the key", key.KeyHash)
+	}
+	if stored, err := repo.GetAPIKeyByHash(value); err != nil || stored != nil {
+		t.Errorf("the plain key value found %v, %v", stored, err)
+	}
+
+	authenticated, err := keys.Authenticate(value, "192.0.2.1")
+	if err != nil {
+		t.Fatalf("Authenticate failed: %v", err)
+	}
+	if authenticated.ID != key.ID || authenticated.LastUsedAt == nil {
+		t.Errorf("Authenticate returned %+v", authenticated)
+	}
+	for _, wrong := range []string{"", key.Prefix, key.KeyHash, value + "x"} {
+		if _, err := keys.Authenticate(wrong, "192.0.2.1"); !errors.Is(err, service.ErrInvalidAPIKey) {
+			t.Errorf("Authenticate(%q) returned %v, want ErrInvalidAPIKey", wrong, err)
+		}
+	}
+}
+
+func TestAPIKeyAllowlist(t *testing.T) {
+	keys := service.NewAPIKeyService(memory.NewMemoryAPIKeyRepository())
+	value, _, err := keys.CreateAPIKey("default", "erp", []entity.Permission{entity.PermissionInvoicesRead}, []string{"10.1.0.0/16", "192.0.2.1"}, nil, 2)
+	if err != nil {
+		t.Fatalf("CreateAPIKey failed: %v", err)
+	}
+	tests := []struct {
+		clientIP string
+		allowed  bool
+	}{
+		{"10.1.2.3", true},
+		{"::ffff:10.1.2.3", true},
+		{"192.0.2.1", true},
+		{"10.2.0.1", false},
+		{"192.0.2.2", false},
+		{"", false},
+		{"not-an-ip", false},
+	}
+	for _, tt := range tests {
+		_, err := keys.Authenticate(value, tt.clientIP)
+		if tt.allowed && err != nil {
+			t.Errorf("Authenticate from %q failed: %v", tt.clientIP, err)
+		}
+		if !tt.allowed && !errors.Is(err, service.ErrInvalidAPIKey) {
+			t.Errorf("Authenticate from %q returned %v, want ErrInvalidAPIKey", tt.clientIP, err)
+		}
+	}
+}
+
+func TestRevokedAndExpiredAPIKeysAreRefused(t *testing.T) {
+	keys := service.NewAPIKeyService(memory.NewMemoryAPIKeyRepository())
+	scopes := []entity.Permission{entity.PermissionInvoicesRead}
+
+	revoked, key, err := keys.CreateAPIKey("default", "erp", scopes, nil, nil, 2)
+	if err != nil {
+		t.Fatalf("CreateAPIKey failed: %v", err)
+	}
+	if found, err := keys.RevokeAPIKey("other", key.ID); err != nil || found {
+		t.Errorf("RevokeAPIKey from another tenant returned %v, %v", found, err)
+	}
+	if found, err := keys.RevokeAPIKey("default", key.ID); err != nil || !found {
+		t.Fatalf("RevokeAPIKey returned %v, %v", found, err)
+	}
+	if _, err := keys.Authenticate(revoked, "192.0.2.1"); !errors.Is(err, service.ErrInvalidAPIKey) {
+		t.Errorf("Authenticate of a revoked key returned %v, want ErrInvalidAPIKey", err)
+	}
+
+	expiresAt := time.Now().Add(20 * time.Millisecond)
+	expiring, _, err := keys.CreateAPIKey("default", "erp", scopes, nil, &expiresAt, 2)
+	if err != nil {
+		t.Fatalf("CreateAPIKey failed: %v", err)
+	}
+	if _, err := keys.Authenticate(expiring, "192.0.2.1"); err != nil {
+		t.Fatalf("Authenticate before expiry failed: %v", err)
+	}
+	time.Sleep(time.Until(expiresAt) + time.Millisecond)
+	if _, err := keys.Authenticate(expiring, "192.0.2.1"); !errors.Is(err, service.ErrInvalidAPIKey) {
+		t.Errorf("Authenticate after expiry returned %v, want ErrInvalidAPIKey", err)
+	}
+}
@@ -0,0 +1,70 @@
+package service
+
+import (
+	"log"
+	"nerp_wrapper/domain/entity"
+	"nerp_wrapper/domain/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// defaultAuditLimit caps audit listings that do not ask for a limit
+const defaultAuditLimit = 100
+
+// AuditService records logins, logouts and data reads to the audit trail
+type AuditService struct {
+	auditRepo repository.AuditRepository
+}
+
+// NewAuditService creates a new instance of AuditService
+func NewAuditService(auditRepo repository.AuditRepository) *AuditService {
+	return &AuditService{auditRepo: auditRepo}
+}
+
+// Record stores an audit event, filling in its ID and time. A failing audit
+// store is logged rather than failing the audited request.
+func (s *AuditService) Record(event *entity.AuditEvent) {
+	event.ID = uuid.NewString()
+	if event.Time.IsZero() {
+		event.Time = time.Now()
+	}
+	if err := s.auditRepo.AppendAuditEvent(event); err != nil {
+		log.Printf("Failed to record audit event %s for %q: %v", event.Action, event.Login, err)
+	}
+}
+
+// RecordLogin records the outcome of a login attempt
+func (s *AuditService) RecordLogin(action entity.AuditAction, tenantID string, userID int64, login, clientIP, detail string) {
+	s.Record(&entity.AuditEvent{
+		Action:   action,
+		TenantID: tenantID,
+		UserID:   userID,
+		Login:    login,
+		ClientIP: clientIP,
+		Detail:   detail,
+	})
+}
+
+// RecordDataRead records a read of sales or invoice data
+func (s *AuditService) RecordDataRead(principal *entity.Principal, route string, filters map[string]string, rowCount, status int, clientIP string) {
+	s.Record(&entity.AuditEvent{
+		Action:   entity.AuditActionDataRead,
+		TenantID: principal.TenantID,
+		UserID:   principal.UserID,
+		Login:    principal.Login,
+		ClientIP: clientIP,
+		Route:    route,
+		Filters:  filters,
+		RowCount: rowCount,
+		Status:   status,
+	})
+}
+
+// ListEvents retrieves audit events matching the filter, newest first
+func (s *AuditService) ListEvents(filter entity.AuditFilter) ([]*entity.AuditEvent, error) {
+	if filter.Limit <= 0 {
+		filter.Limit = defaultAuditLimit
+	}
+	return s.auditRepo.ListAuditEvents(filter)
+}
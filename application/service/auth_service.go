@@ -1,42 +1,405 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"nerp_wrapper/domain/entity"
 	"nerp_wrapper/domain/repository"
+	"time"
+)
+
+var (
+	// ErrRefreshTokenReused is returned when an already rotated refresh token is presented again
+	ErrRefreshTokenReused = errors.New("refresh token reuse detected")
+	// ErrOdooTwoFactorRequiresAPIKey is returned when a user with two-factor
+	// authentication enabled in Odoo signs in with a password
+	ErrOdooTwoFactorRequiresAPIKey = errors.New("two-factor authentication is enabled in Odoo, sign in with an Odoo API key")
 )
 
 // AuthService handles authentication business logic
 type AuthService struct {
-	authRepo repository.AuthRepository
+	tenants      repository.TenantRegistry
+	tokenRepo    repository.TokenRepository
+	tokenService *TokenService
+	policy       *entity.AccessPolicy
+	loginGuard   *LoginGuardService
+	totpService  *TOTPService
+	auditService *AuditService
+	odooTimeout  time.Duration
 }
 
-// NewAuthService creates a new instance of AuthService
-func NewAuthService(authRepo repository.AuthRepository) *AuthService {
+// NewAuthService creates a new instance of AuthService. Operations calling
+// Odoo must finish within odooTimeout.
+func NewAuthService(tenants repository.TenantRegistry, tokenRepo repository.TokenRepository, tokenService *TokenService, policy *entity.AccessPolicy, loginGuard *LoginGuardService, totpService *TOTPService, auditService *AuditService, odooTimeout time.Duration) *AuthService {
 	return &AuthService{
-		authRepo: authRepo,
+		tenants:      tenants,
+		tokenRepo:    tokenRepo,
+		tokenService: tokenService,
+		policy:       policy,
+		loginGuard:   loginGuard,
+		totpService:  totpService,
+		auditService: auditService,
+		odooTimeout:  odooTimeout,
 	}
 }
 
-// Login handles user authentication
-func (s *AuthService) Login(username, password string) (*entity.User, error) {
-	user, err := s.authRepo.Login(username, password)
+// Login handles the password step of user authentication against a tenant,
+// the default one when tenantID is empty. secretIsAPIKey tells whether secret
+// was sent as an Odoo API key rather than a password. Users with TOTP enabled
+// in Odoo must sign in with an Odoo API key, since the wrapper cannot check
+// Odoo's second factor. Users enrolled in the wrapper's TOTP then get a
+// challenge to answer through VerifyTwoFactor; everyone else gets an access
+// and refresh token pair. Returns a *LoginThrottledError while the username or
+// client IP is backed off.
+func (s *AuthService) Login(ctx context.Context, tenantID, username, secret string, secretIsAPIKey bool, clientIP string) (*entity.LoginResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.odooTimeout)
+	defer cancel()
+
+	tenant, err := s.tenants.ResolveTenant(tenantID)
 	if err != nil {
-		return nil, fmt.Errorf("login failed: %v", err)
+		return nil, err
 	}
-	return user, nil
+	authRepo, err := s.tenants.AuthRepository(tenant.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	guardKey := tenantLogin(tenant.ID, username)
+	if err := s.loginGuard.Check(guardKey, clientIP); err != nil {
+		s.auditService.RecordLogin(entity.AuditActionLoginFailure, tenant.ID, 0, username, clientIP, "throttled")
+		return nil, err
+	}
+	defer s.loginGuard.Release(guardKey, clientIP)
+
+	user, err := authRepo.Login(ctx, username, secret)
+	if err != nil {
+		if errors.Is(err, repository.ErrInvalidCredentials) {
+			s.loginGuard.RecordFailure(guardKey, clientIP)
+			s.auditService.RecordLogin(entity.AuditActionLoginFailure, tenant.ID, 0, username, clientIP, "invalid credentials")
+		} else {
+			s.auditService.RecordLogin(entity.AuditActionLoginFailure, tenant.ID, 0, username, clientIP, err.Error())
+		}
+		return nil, fmt.Errorf("login failed: %w", err)
+	}
+	// The backend login is ended again unless tokens or a challenge come out of it
+	issued := false
+	defer func() {
+		if !issued {
+			authRepo.Logout(ctx, user.ID)
+		}
+	}()
+
+	enrolled, err := s.totpService.IsEnrolled(tenant.ID, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("login failed: %w", err)
+	}
+	odooEnabled, err := authRepo.IsTwoFactorEnabled(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("login failed: %w", err)
+	}
+	if odooEnabled && !secretIsAPIKey {
+		// Odoo itself only accepts API keys over RPC from such users; this
+		// also covers databases where it does not. The wrapper's own TOTP
+		// does not stand in for Odoo's second factor.
+		s.auditService.RecordLogin(entity.AuditActionLoginFailure, tenant.ID, user.ID, user.Username, clientIP, "odoo two-factor requires an api key")
+		return nil, ErrOdooTwoFactorRequiresAPIKey
+	}
+	if enrolled {
+		// Failures are only cleared once the second factor succeeds, so
+		// repeating the password step cannot reset a code guessing attempt
+		challengeToken, claims, err := s.tokenService.IssueChallengeToken(tenant.ID, user)
+		if err != nil {
+			return nil, fmt.Errorf("login failed: %w", err)
+		}
+		s.auditService.RecordLogin(entity.AuditActionLoginChallenge, tenant.ID, user.ID, user.Username, clientIP, "")
+		issued = true
+		return &entity.LoginResult{
+			User:      user,
+			Challenge: &entity.TwoFactorChallenge{Token: challengeToken, ExpiresAt: claims.ExpiresAt},
+		}, nil
+	}
+	s.loginGuard.RecordSuccess(guardKey)
+
+	tokens, err := s.issueUserTokens(ctx, tenant.ID, user)
+	if err != nil {
+		return nil, fmt.Errorf("login failed: %w", err)
+	}
+	s.auditService.RecordLogin(entity.AuditActionLoginSuccess, tenant.ID, user.ID, user.Username, clientIP, "")
+	issued = true
+	return &entity.LoginResult{User: user, Tokens: tokens}, nil
+}
+
+// VerifyTwoFactor completes a two-factor login with a TOTP code and issues an
+// access and refresh token pair
+func (s *AuthService) VerifyTwoFactor(ctx context.Context, challengeToken, code, clientIP string) (*entity.LoginResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.odooTimeout)
+	defer cancel()
+
+	claims, err := s.tokenService.ParseChallengeToken(challengeToken)
+	if err != nil {
+		return nil, err
+	}
+	guardKey := tenantLogin(claims.TenantID, claims.Login)
+	if err := s.loginGuard.Check(guardKey, clientIP); err != nil {
+		s.auditService.RecordLogin(entity.AuditActionLoginFailure, claims.TenantID, claims.UserID, claims.Login, clientIP, "throttled")
+		return nil, err
+	}
+	defer s.loginGuard.Release(guardKey, clientIP)
+
+	// A pending enrollment is only confirmed by its owner once logged in
+	enrolled, err := s.totpService.IsEnrolled(claims.TenantID, claims.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("login failed: %w", err)
+	}
+	if !enrolled {
+		err = ErrTOTPNotEnrolled
+	} else {
+		err = s.totpService.Verify(claims.TenantID, claims.UserID, code)
+	}
+	if err != nil {
+		if errors.Is(err, ErrInvalidTOTPCode) {
+			s.loginGuard.RecordFailure(guardKey, clientIP)
+		}
+		s.auditService.RecordLogin(entity.AuditActionLoginFailure, claims.TenantID, claims.UserID, claims.Login, clientIP, err.Error())
+		return nil, err
+	}
+	s.loginGuard.RecordSuccess(guardKey)
+
+	user, err := s.getUserInfo(ctx, claims.TenantID, claims.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("login failed: %w", err)
+	}
+	tokens, err := s.issueUserTokens(ctx, claims.TenantID, user)
+	if err != nil {
+		return nil, fmt.Errorf("login failed: %w", err)
+	}
+	s.auditService.RecordLogin(entity.AuditActionLoginSuccess, claims.TenantID, user.ID, user.Username, clientIP, "totp")
+	return &entity.LoginResult{User: user, Tokens: tokens}, nil
+}
+
+// ConfirmTOTPEnrollment confirms the caller's pending TOTP enrollment with a
+// code. Like VerifyTwoFactor, wrong codes count as failed logins of the caller.
+func (s *AuthService) ConfirmTOTPEnrollment(principal *entity.Principal, code, clientIP string) error {
+	return s.checkCallerCode(principal, clientIP, func() error {
+		return s.totpService.Verify(principal.TenantID, principal.UserID, code)
+	})
+}
+
+// DisableTOTP removes the caller's TOTP enrollment after checking a current
+// code, counting wrong codes as failed logins of the caller
+func (s *AuthService) DisableTOTP(principal *entity.Principal, code, clientIP string) error {
+	return s.checkCallerCode(principal, clientIP, func() error {
+		return s.totpService.Disable(principal.TenantID, principal.UserID, code)
+	})
+}
+
+// checkCallerCode runs a TOTP code check of the caller under the login guard
+func (s *AuthService) checkCallerCode(principal *entity.Principal, clientIP string, check func() error) error {
+	guardKey := tenantLogin(principal.TenantID, principal.Login)
+	if err := s.loginGuard.Check(guardKey, clientIP); err != nil {
+		return err
+	}
+	defer s.loginGuard.Release(guardKey, clientIP)
+	if err := check(); err != nil {
+		if errors.Is(err, ErrInvalidTOTPCode) {
+			s.loginGuard.RecordFailure(guardKey, clientIP)
+		}
+		return err
+	}
+	s.loginGuard.RecordSuccess(guardKey)
+	return nil
+}
+
+// Refresh rotates a refresh token: the presented token is consumed and a new
+// pair is issued in the same family. Presenting a consumed token revokes the
+// whole family, since it means the token has leaked, as does refreshing after
+// the user's backend login went idle.
+func (s *AuthService) Refresh(ctx context.Context, refreshToken string) (*entity.AuthTokens, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.odooTimeout)
+	defer cancel()
+
+	now := time.Now()
+	stored, err := s.tokenRepo.ConsumeRefreshToken(HashRefreshToken(refreshToken), now)
+	if err != nil {
+		return nil, fmt.Errorf("refresh failed: %w", err)
+	}
+	if stored == nil || stored.RevokedAt != nil || !stored.ExpiresAt.After(now) {
+		return nil, ErrInvalidToken
+	}
+	if stored.UsedAt != nil {
+		if err := s.tokenRepo.RevokeRefreshTokenFamily(stored.FamilyID, now); err != nil {
+			return nil, fmt.Errorf("refresh failed: %w", err)
+		}
+		if err := s.endLogin(ctx, stored.TenantID, stored.UserID); err != nil {
+			return nil, fmt.Errorf("refresh failed: %w", err)
+		}
+		return nil, ErrRefreshTokenReused
+	}
+
+	// The backend login ends once idle, after which the tokens would be
+	// refused on every data route, so the family ends with it
+	authRepo, err := s.tenants.AuthRepository(stored.TenantID)
+	if err != nil {
+		return nil, fmt.Errorf("refresh failed: %w", err)
+	}
+	if !authRepo.HasSession(stored.UserID) {
+		if err := s.tokenRepo.RevokeRefreshTokenFamily(stored.FamilyID, now); err != nil {
+			return nil, fmt.Errorf("refresh failed: %w", err)
+		}
+		return nil, fmt.Errorf("%w: session expired", ErrInvalidToken)
+	}
+
+	// Permissions are re-derived so that group changes in Odoo apply on refresh
+	permissions, err := s.permissionsFor(ctx, stored.TenantID, stored.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("refresh failed: %w", err)
+	}
+
+	user := &entity.User{ID: stored.UserID, Username: stored.Login}
+	tokens, err := s.issueTokens(stored.TenantID, user, permissions, stored.FamilyID)
+	if err != nil {
+		return nil, fmt.Errorf("refresh failed: %w", err)
+	}
+	return tokens, nil
+}
+
+// Logout revokes the caller's refresh token family and blacklists the current
+// access token until it expires. The backend login is ended along with the
+// family, so it stays open when no live refresh token is given.
+func (s *AuthService) Logout(ctx context.Context, claims *entity.AccessClaims, refreshToken, clientIP string) error {
+	ctx, cancel := context.WithTimeout(ctx, s.odooTimeout)
+	defer cancel()
+
+	now := time.Now()
+	revoked := false
+	if refreshToken != "" {
+		stored, err := s.tokenRepo.GetRefreshToken(HashRefreshToken(refreshToken))
+		if err != nil {
+			return fmt.Errorf("logout failed: %w", err)
+		}
+		if stored != nil && stored.TenantID == claims.TenantID && stored.UserID == claims.UserID && stored.RevokedAt == nil {
+			if err := s.tokenRepo.RevokeRefreshTokenFamily(stored.FamilyID, now); err != nil {
+				return fmt.Errorf("logout failed: %w", err)
+			}
+			revoked = true
+		}
+	}
+
+	if err := s.tokenRepo.RevokeAccessToken(claims.TokenID, claims.ExpiresAt); err != nil {
+		return fmt.Errorf("logout failed: %w", err)
+	}
+	s.auditService.RecordLogin(entity.AuditActionLogout, claims.TenantID, claims.UserID, claims.Login, clientIP, "")
+
+	// Only a revoked family ends a backend login: without one the login
+	// lives on through its refresh token, and a repeated logout must not end
+	// another login of the user
+	if revoked {
+		if err := s.endLogin(ctx, claims.TenantID, claims.UserID); err != nil {
+			return fmt.Errorf("logout failed: %w", err)
+		}
+	}
+	return nil
+}
+
+// endLogin ends the backend login behind a revoked session, releasing the
+// user's Odoo clients once none of the user's logins remain
+func (s *AuthService) endLogin(ctx context.Context, tenantID string, userID int64) error {
+	authRepo, err := s.tenants.AuthRepository(tenantID)
+	if err != nil {
+		return err
+	}
+	return authRepo.Logout(ctx, userID)
+}
+
+// Authenticate verifies an access token, rejecting revoked ones, and returns its claims
+func (s *AuthService) Authenticate(accessToken string) (*entity.AccessClaims, error) {
+	claims, err := s.tokenService.ParseAccessToken(accessToken)
+	if err != nil {
+		return nil, err
+	}
+
+	revoked, err := s.tokenRepo.IsAccessTokenRevoked(claims.TokenID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check token revocation: %w", err)
+	}
+	if revoked {
+		return nil, fmt.Errorf("%w: token has been revoked", ErrInvalidToken)
+	}
+	return claims, nil
+}
+
+// PurgeExpiredTokens removes expired refresh tokens and access token blacklist entries
+func (s *AuthService) PurgeExpiredTokens() error {
+	return s.tokenRepo.DeleteExpired(time.Now())
 }
 
-// Logout handles user logout
-func (s *AuthService) Logout() error {
-	return s.authRepo.Logout()
+// GetUserInfo retrieves user information from a tenant
+func (s *AuthService) GetUserInfo(ctx context.Context, tenantID string, userID int64) (*entity.User, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.odooTimeout)
+	defer cancel()
+	return s.getUserInfo(ctx, tenantID, userID)
 }
 
-// GetUserInfo retrieves user information
-func (s *AuthService) GetUserInfo(userID int64) (*entity.User, error) {
-	user, err := s.authRepo.GetUserInfo(userID)
+func (s *AuthService) getUserInfo(ctx context.Context, tenantID string, userID int64) (*entity.User, error) {
+	authRepo, err := s.tenants.AuthRepository(tenantID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user info: %v", err)
+		return nil, err
+	}
+	user, err := authRepo.GetUserInfo(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user info: %w", err)
 	}
 	return user, nil
 }
+
+// issueUserTokens issues a token pair in a new family with the user's current permissions
+func (s *AuthService) issueUserTokens(ctx context.Context, tenantID string, user *entity.User) (*entity.AuthTokens, error) {
+	permissions, err := s.permissionsFor(ctx, tenantID, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	return s.issueTokens(tenantID, user, permissions, "")
+}
+
+// permissionsFor maps the user's Odoo groups to API permissions
+func (s *AuthService) permissionsFor(ctx context.Context, tenantID string, userID int64) ([]entity.Permission, error) {
+	authRepo, err := s.tenants.AuthRepository(tenantID)
+	if err != nil {
+		return nil, err
+	}
+	groups, err := authRepo.GetUserGroups(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.policy.PermissionsFor(groups), nil
+}
+
+// issueTokens creates a new access token and a refresh token in the given family
+func (s *AuthService) issueTokens(tenantID string, user *entity.User, permissions []entity.Permission, familyID string) (*entity.AuthTokens, error) {
+	accessToken, claims, err := s.tokenService.IssueAccessToken(tenantID, user, permissions)
+	if err != nil {
+		return nil, err
+	}
+
+	refreshToken, stored, err := s.tokenService.IssueRefreshToken(tenantID, user, familyID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.tokenRepo.SaveRefreshToken(stored); err != nil {
+		return nil, err
+	}
+
+	return &entity.AuthTokens{
+		AccessToken:           accessToken,
+		AccessTokenExpiresAt:  claims.ExpiresAt,
+		RefreshToken:          refreshToken,
+		RefreshTokenExpiresAt: stored.ExpiresAt,
+	}, nil
+}
+
+// tenantLogin qualifies a login with its tenant, since the same login may
+// exist in several Odoo databases
+func tenantLogin(tenantID, login string) string {
+	return tenantID + "/" + login
+}
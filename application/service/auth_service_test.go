@@ -0,0 +1,232 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"nerp_wrapper/application/service"
+	"nerp_wrapper/domain/entity"
+	"nerp_wrapper/infrastructure/jsonl"
+	"nerp_wrapper/infrastructure/memory"
+	"nerp_wrapper/infrastructure/odoo"
+	"nerp_wrapper/infrastructure/odoo/odootest"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// authFixture is an AuthService logging users into a fake Odoo
+type authFixture struct {
+	auth      *service.AuthService
+	pool      *odoo.ClientPool
+	tokenRepo *memory.MemoryTokenRepository
+}
+
+func newAuthFixture(t *testing.T) *authFixture {
+	t.Helper()
+	fake := odootest.NewServer("nerp")
+	fake.Seed(loadFixtures(t))
+	odooServer := httptest.NewServer(fake)
+	t.Cleanup(odooServer.Close)
+
+	// An idle TTL of zero lets EvictIdle drop every session
+	pool := odoo.NewClientPool(odooServer.URL, "nerp", 0, nil)
+	authRepo, err := odoo.NewOdooAuthRepository("admin", "admin", "nerp", odooServer.URL, pool)
+	if err != nil {
+		t.Fatalf("failed to create auth repository: %v", err)
+	}
+	registry := odoo.NewTenantRegistry("default")
+	if err := registry.Register(&entity.Tenant{ID: "default"}, pool, authRepo); err != nil {
+		t.Fatalf("failed to register tenant: %v", err)
+	}
+
+	auditRepo, err := jsonl.NewJSONLAuditRepository(filepath.Join(t.TempDir(), "audit.jsonl"))
+	if err != nil {
+		t.Fatalf("failed to open audit log: %v", err)
+	}
+	t.Cleanup(func() { auditRepo.Close() })
+
+	tokenRepo := memory.NewMemoryTokenRepository()
+	tokenService := service.NewTokenService([]byte(strings.Repeat("x", 40)), "nerp-wrapper", time.Minute, time.Hour)
+	auth := service.NewAuthService(
+		registry,
+		tokenRepo,
+		tokenService,
+		entity.DefaultAccessPolicy(),
+		service.NewLoginGuardService(service.DefaultLoginGuardConfig()),
+		service.NewTOTPService(memory.NewMemoryTOTPRepository(), "nerp-wrapper"),
+		service.NewAuditService(auditRepo),
+		5*time.Second,
+	)
+	return &authFixture{auth: auth, pool: pool, tokenRepo: tokenRepo}
+}
+
+func (f *authFixture) login(t *testing.T, username, password string) *entity.AuthTokens {
+	t.Helper()
+	result, err := f.auth.Login(context.Background(), "", username, password, false, "192.0.2.1")
+	if err != nil {
+		t.Fatalf("Login failed: %v", err)
+	}
+	if result.Tokens == nil {
+		t.Fatal("Login returned no tokens")
+	}
+	return result.Tokens
+}
+
+func TestRefreshAfterIdleEvictionEndsTheFamily(t *testing.T) {
+	f := newAuthFixture(t)
+	ctx := context.Background()
+	tokens := f.login(t, "sales", "sales")
+
+	f.pool.EvictIdle()
+	if _, err := f.auth.Refresh(ctx, tokens.RefreshToken); !errors.Is(err, service.ErrInvalidToken) {
+		t.Fatalf("Refresh after eviction returned %v, want ErrInvalidToken", err)
+	}
+	stored, err := f.tokenRepo.GetRefreshToken(service.HashRefreshToken(tokens.RefreshToken))
+	if err != nil || stored == nil {
+		t.Fatalf("GetRefreshToken returned %v, %v", stored, err)
+	}
+	if stored.RevokedAt == nil {
+		t.Error("refresh family was not revoked")
+	}
+
+	// Logging in again opens a new session that refreshes normally
+	tokens = f.login(t, "sales", "sales")
+	if _, err := f.auth.Refresh(ctx, tokens.RefreshToken); err != nil {
+		t.Errorf("Refresh after a new login failed: %v", err)
+	}
+}
+
+func TestLogoutEndsOnlyTheLoginWhoseFamilyItRevokes(t *testing.T) {
+	f := newAuthFixture(t)
+	ctx := context.Background()
+	first := f.login(t, "sales", "sales")
+	second := f.login(t, "sales", "sales")
+	claimsOf := func(tokens *entity.AuthTokens) *entity.AccessClaims {
+		t.Helper()
+		claims, err := f.auth.Authenticate(tokens.AccessToken)
+		if err != nil {
+			t.Fatalf("Authenticate failed: %v", err)
+		}
+		return claims
+	}
+
+	// Without a refresh token the login lives on through it
+	if err := f.auth.Logout(ctx, claimsOf(first), "", "192.0.2.1"); err != nil {
+		t.Fatalf("Logout failed: %v", err)
+	}
+	first, err := f.auth.Refresh(ctx, first.RefreshToken)
+	if err != nil {
+		t.Fatalf("Refresh after a logout without refresh token failed: %v", err)
+	}
+
+	// Logging out twice ends one login, leaving the other one usable
+	claims := claimsOf(first)
+	for range 2 {
+		if err := f.auth.Logout(ctx, claims, first.RefreshToken, "192.0.2.1"); err != nil {
+			t.Fatalf("Logout failed: %v", err)
+		}
+	}
+	if _, err := f.auth.Refresh(ctx, second.RefreshToken); err != nil {
+		t.Errorf("Refresh of the remaining login failed: %v", err)
+	}
+}
+
+func TestRefreshRotatesAndRevokesTheFamilyOnReuse(t *testing.T) {
+	f := newAuthFixture(t)
+	ctx := context.Background()
+	first := f.login(t, "sales", "sales")
+
+	second, err := f.auth.Refresh(ctx, first.RefreshToken)
+	if err != nil {
+		t.Fatalf("Refresh failed: %v", err)
+	}
+	if second.RefreshToken == first.RefreshToken || second.AccessToken == first.AccessToken {
+		t.Fatal("Refresh did not rotate the tokens")
+	}
+	claims, err := f.auth.Authenticate(second.AccessToken)
+	if err != nil {
+		t.Fatalf("refreshed access token was refused: %v", err)
+	}
+	if claims.Login != "sales" || len(claims.Permissions) == 0 {
+		t.Errorf("refreshed claims are %+v", claims)
+	}
+
+	// Presenting the rotated token again revokes its descendants
+	if _, err := f.auth.Refresh(ctx, first.RefreshToken); !errors.Is(err, service.ErrRefreshTokenReused) {
+		t.Fatalf("reusing a rotated token returned %v, want ErrRefreshTokenReused", err)
+	}
+	if _, err := f.auth.Refresh(ctx, second.RefreshToken); !errors.Is(err, service.ErrInvalidToken) {
+		t.Errorf("Refresh of the family's latest token returned %v, want ErrInvalidToken", err)
+	}
+	if _, err := f.auth.Refresh(ctx, "unknown"); !errors.Is(err, service.ErrInvalidToken) {
+		t.Errorf("Refresh of an unknown token returned %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestLogoutRevokesTheAccessToken(t *testing.T) {
+	f := newAuthFixture(t)
+	tokens := f.login(t, "sales", "sales")
+	claims, err := f.auth.Authenticate(tokens.AccessToken)
+	if err != nil {
+		t.Fatalf("Authenticate failed: %v", err)
+	}
+	if err := f.auth.Logout(context.Background(), claims, tokens.RefreshToken, "192.0.2.1"); err != nil {
+		t.Fatalf("Logout failed: %v", err)
+	}
+	if _, err := f.auth.Authenticate(tokens.AccessToken); !errors.Is(err, service.ErrInvalidToken) {
+		t.Errorf("Authenticate after logout returned %v, want ErrInvalidToken", err)
+	}
+	if _, err := f.auth.Refresh(context.Background(), tokens.RefreshToken); !errors.Is(err, service.ErrInvalidToken) {
+		t.Errorf("Refresh after logout returned %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestOdooTwoFactorUsersNeedAnAPIKey(t *testing.T) {
+	fixtures := loadFixtures(t)
+	for i := range fixtures.Users {
+		fixtures.Users[i].TwoFactorEnabled = fixtures.Users[i].Login == "sales"
+	}
+	registry := odoo.NewTenantRegistry("default")
+	if err := registry.Register(&entity.Tenant{ID: "default"}, nil, memory.NewMemoryAuthRepository(fixtures.Users)); err != nil {
+		t.Fatalf("failed to register tenant: %v", err)
+	}
+	auditRepo, err := jsonl.NewJSONLAuditRepository(filepath.Join(t.TempDir(), "audit.jsonl"))
+	if err != nil {
+		t.Fatalf("failed to open audit log: %v", err)
+	}
+	t.Cleanup(func() { auditRepo.Close() })
+	totpRepo := memory.NewMemoryTOTPRepository()
+	auth := service.NewAuthService(
+		registry,
+		memory.NewMemoryTokenRepository(),
+		service.NewTokenService([]byte(strings.Repeat("x", 40)), "nerp-wrapper", time.Minute, time.Hour),
+		entity.DefaultAccessPolicy(),
+		service.NewLoginGuardService(service.DefaultLoginGuardConfig()),
+		service.NewTOTPService(totpRepo, "nerp-wrapper"),
+		service.NewAuditService(auditRepo),
+		5*time.Second,
+	)
+	ctx := context.Background()
+
+	if _, err := auth.Login(ctx, "", "sales", "sales", false, "192.0.2.1"); !errors.Is(err, service.ErrOdooTwoFactorRequiresAPIKey) {
+		t.Fatalf("password login returned %v, want ErrOdooTwoFactorRequiresAPIKey", err)
+	}
+	result, err := auth.Login(ctx, "", "sales", "sales", true, "192.0.2.1")
+	if err != nil || result.Tokens == nil {
+		t.Fatalf("API key login returned %+v, %v", result, err)
+	}
+
+	// The wrapper's TOTP comes on top of the API key, not instead of it
+	if err := totpRepo.SaveTOTPEnrollment(&entity.TOTPEnrollment{TenantID: "default", UserID: 6, Secret: "JBSWY3DPEHPK3PXP", Confirmed: true}); err != nil {
+		t.Fatalf("SaveTOTPEnrollment failed: %v", err)
+	}
+	if _, err := auth.Login(ctx, "", "sales", "sales", false, "192.0.2.1"); !errors.Is(err, service.ErrOdooTwoFactorRequiresAPIKey) {
+		t.Errorf("password login of an enrolled user returned %v, want ErrOdooTwoFactorRequiresAPIKey", err)
+	}
+	result, err = auth.Login(ctx, "", "sales", "sales", true, "192.0.2.1")
+	if err != nil || result.Challenge == nil || result.Tokens != nil {
+		t.Errorf("API key login of an enrolled user returned %+v, %v; want a challenge", result, err)
+	}
+}
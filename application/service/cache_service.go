@@ -0,0 +1,31 @@
+package service
+
+import (
+	"nerp_wrapper/domain/entity"
+	"nerp_wrapper/domain/repository"
+)
+
+// CacheService reports the statistics of the caches in use
+type CacheService struct {
+	backend   string
+	reporters []repository.CacheStatsReporter
+}
+
+// NewCacheService creates a new instance of CacheService. backend names the
+// cache store, or is "off" when caching is disabled.
+func NewCacheService(backend string, reporters ...repository.CacheStatsReporter) *CacheService {
+	return &CacheService{backend: backend, reporters: reporters}
+}
+
+// Report returns the statistics of the caches of a tenant
+func (s *CacheService) Report(tenantID string) *entity.CacheReport {
+	report := &entity.CacheReport{Backend: s.backend, Stats: []entity.CacheStats{}}
+	for _, reporter := range s.reporters {
+		for _, stats := range reporter.CacheStats() {
+			if stats.Tenant == tenantID {
+				report.Stats = append(report.Stats, stats)
+			}
+		}
+	}
+	return report
+}
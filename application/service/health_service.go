@@ -0,0 +1,100 @@
+package service
+
+import (
+	"context"
+	"nerp_wrapper/domain/entity"
+	"nerp_wrapper/domain/repository"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// HealthService reports whether the dependencies needed to serve requests are usable
+type HealthService struct {
+	checkers     []repository.HealthChecker
+	running      []atomic.Bool // Whether a check of the checker at the same index is in flight
+	cacheTTL     time.Duration
+	checkTimeout time.Duration
+	draining     atomic.Bool
+
+	mu     sync.Mutex
+	cached *entity.ReadinessReport
+}
+
+// NewHealthService creates a new instance of HealthService. Readiness results
+// are reused for cacheTTL so frequent probes do not hit Odoo on every call.
+func NewHealthService(checkers []repository.HealthChecker, cacheTTL, checkTimeout time.Duration) *HealthService {
+	return &HealthService{checkers: checkers, running: make([]atomic.Bool, len(checkers)), cacheTTL: cacheTTL, checkTimeout: checkTimeout}
+}
+
+// Drain marks the service as shutting down so readiness fails and load
+// balancers stop sending new requests while in-flight ones complete
+func (s *HealthService) Drain() {
+	s.draining.Store(true)
+}
+
+// Readiness returns the cached report, or checks every dependency in parallel
+// once the cached report is older than the cache TTL. A dependency not
+// answering within the check timeout is reported down, and is not checked
+// again before that check has returned, so stuck checks do not pile up.
+func (s *HealthService) Readiness() *entity.ReadinessReport {
+	if s.draining.Load() {
+		return &entity.ReadinessReport{
+			Status:       entity.DependencyDown,
+			CheckedAt:    time.Now(),
+			Dependencies: []entity.DependencyStatus{},
+			Detail:       "shutting down",
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.cached != nil && time.Since(s.cached.CheckedAt) < s.cacheTTL {
+		return s.cached
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.checkTimeout)
+	defer cancel()
+	results := make([]chan entity.DependencyStatus, len(s.checkers))
+	for i, checker := range s.checkers {
+		results[i] = make(chan entity.DependencyStatus, 1)
+		if !s.running[i].CompareAndSwap(false, true) {
+			results[i] <- entity.DependencyStatus{
+				Status: entity.DependencyDown,
+				Error:  "previous health check still running",
+			}
+			continue
+		}
+		go func(i int, checker repository.HealthChecker, result chan<- entity.DependencyStatus) {
+			defer s.running[i].Store(false)
+			result <- checker.CheckHealth(ctx)
+		}(i, checker, results[i])
+	}
+
+	report := &entity.ReadinessReport{
+		Status:       entity.DependencyUp,
+		CheckedAt:    time.Now(),
+		Dependencies: make([]entity.DependencyStatus, len(s.checkers)),
+	}
+	for i, checker := range s.checkers {
+		var status entity.DependencyStatus
+		select {
+		case status = <-results[i]:
+		case <-ctx.Done():
+			status = entity.DependencyStatus{
+				Status:    entity.DependencyDown,
+				LatencyMs: s.checkTimeout.Milliseconds(),
+				Error:     "health check timed out",
+			}
+		}
+		status.Name = checker.Name()
+		if status.Status != entity.DependencyUp {
+			report.Status = entity.DependencyDown
+		}
+		report.Dependencies[i] = status
+	}
+
+	s.cached = report
+	return report
+}
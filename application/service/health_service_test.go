@@ -0,0 +1,52 @@
+package service_test
+
+import (
+	"context"
+	"nerp_wrapper/application/service"
+	"nerp_wrapper/domain/entity"
+	"nerp_wrapper/domain/repository"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// stuckChecker ignores its context, as an XML-RPC call does, and answers
+// once released
+type stuckChecker struct {
+	release chan struct{}
+	calls   atomic.Int32
+}
+
+func (c *stuckChecker) Name() string {
+	return "stuck"
+}
+
+func (c *stuckChecker) CheckHealth(ctx context.Context) entity.DependencyStatus {
+	c.calls.Add(1)
+	<-c.release
+	return entity.DependencyStatus{Status: entity.DependencyUp}
+}
+
+func TestHealthServiceDoesNotRepeatStuckChecks(t *testing.T) {
+	checker := &stuckChecker{release: make(chan struct{})}
+	healthService := service.NewHealthService([]repository.HealthChecker{checker}, 0, 20*time.Millisecond)
+
+	if report := healthService.Readiness(); report.Status != entity.DependencyDown {
+		t.Fatalf("readiness with a stuck check is %s, want down", report.Status)
+	}
+	if report := healthService.Readiness(); report.Status != entity.DependencyDown {
+		t.Fatalf("readiness while the check is still stuck is %s, want down", report.Status)
+	}
+	if calls := checker.calls.Load(); calls != 1 {
+		t.Errorf("stuck check was started %d times, want 1", calls)
+	}
+
+	close(checker.release)
+	deadline := time.Now().Add(time.Second)
+	for healthService.Readiness().Status != entity.DependencyUp {
+		if time.Now().After(deadline) {
+			t.Fatal("readiness stayed down after the check returned")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
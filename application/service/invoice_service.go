@@ -1,29 +1,40 @@
 package service
 
 import (
+	"context"
 	"nerp_wrapper/domain/entity"
-	"nerp_wrapper/infrastructure/odoo"
+	"nerp_wrapper/domain/repository"
 	"time"
 )
 
 type InvoiceService struct {
-	invoiceRepo *odoo.OdooInvoiceRepository
+	invoiceRepo    repository.InvoiceRepository
+	readTimeout    time.Duration
+	summaryTimeout time.Duration
 }
 
-func NewInvoiceService(invoiceRepo *odoo.OdooInvoiceRepository) *InvoiceService {
-	return &InvoiceService{invoiceRepo: invoiceRepo}
+// NewInvoiceService creates a new instance of InvoiceService. Listing
+// invoices must finish within readTimeout and summaries within summaryTimeout.
+func NewInvoiceService(invoiceRepo repository.InvoiceRepository, readTimeout, summaryTimeout time.Duration) *InvoiceService {
+	return &InvoiceService{invoiceRepo: invoiceRepo, readTimeout: readTimeout, summaryTimeout: summaryTimeout}
 }
 
-func (s *InvoiceService) GetAllInvoices(page, pageSize int) (*entity.InvoicePagination, error) {
-	return s.invoiceRepo.GetAllInvoices(page, pageSize)
+func (s *InvoiceService) GetAllInvoices(ctx context.Context, principal *entity.Principal, filter entity.InvoiceFilter, page, pageSize int) (*entity.InvoicePagination, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.readTimeout)
+	defer cancel()
+	return s.invoiceRepo.GetAllInvoices(ctx, principal, filter, page, pageSize)
 }
 
 // GetDailyInvoiceSummary retrieves daily invoice summary with pagination
-func (s *InvoiceService) GetDailyInvoiceSummary(page, pageSize int) (*entity.InvoiceSummaryResponse, error) {
-	return s.invoiceRepo.GetDailyInvoiceSummary(page, pageSize)
+func (s *InvoiceService) GetDailyInvoiceSummary(ctx context.Context, principal *entity.Principal, filter entity.InvoiceFilter, page, pageSize int) (*entity.InvoiceSummaryResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.summaryTimeout)
+	defer cancel()
+	return s.invoiceRepo.GetDailyInvoiceSummary(ctx, principal, filter, page, pageSize)
 }
 
 // GetPeriodInvoiceSummary retrieves invoice summary for a specific period
-func (s *InvoiceService) GetPeriodInvoiceSummary(periodType entity.PeriodType, startDate, endDate *time.Time) (*entity.PeriodInvoiceSummaryResponse, error) {
-	return s.invoiceRepo.GetPeriodInvoiceSummary(periodType, startDate, endDate)
+func (s *InvoiceService) GetPeriodInvoiceSummary(ctx context.Context, principal *entity.Principal, filter entity.InvoiceFilter, periodType entity.PeriodType, startDate, endDate *time.Time) (*entity.PeriodInvoiceSummaryResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.summaryTimeout)
+	defer cancel()
+	return s.invoiceRepo.GetPeriodInvoiceSummary(ctx, principal, filter, periodType, startDate, endDate)
 }
@@ -0,0 +1,237 @@
+package service
+
+import (
+	"fmt"
+	"nerp_wrapper/domain/entity"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LoginGuardConfig configures brute-force protection for logins
+type LoginGuardConfig struct {
+	UserThreshold   int           // Failed attempts per username before lockout
+	IPThreshold     int           // Failed attempts per client IP before lockout
+	BaseDelay       time.Duration // Delay after the first failure, doubled on each further failure
+	MaxDelay        time.Duration // Upper bound of the backoff delay
+	LockoutDuration time.Duration // Lockout length once a threshold is reached
+	ResetAfter      time.Duration // Forget failures older than this
+}
+
+// DefaultLoginGuardConfig returns the brute-force protection defaults
+func DefaultLoginGuardConfig() LoginGuardConfig {
+	return LoginGuardConfig{
+		UserThreshold:   5,
+		IPThreshold:     20,
+		BaseDelay:       time.Second,
+		MaxDelay:        time.Minute,
+		LockoutDuration: 15 * time.Minute,
+		ResetAfter:      time.Hour,
+	}
+}
+
+// LoginThrottledError is returned when a login is attempted while the username
+// or client IP is backed off or locked out
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	return fmt.Sprintf("too many failed login attempts, retry after %s", e.RetryAfter.Round(time.Second))
+}
+
+// pendingRetryAfter is the wait asked of attempts refused while others of the
+// same username or client IP are still running
+const pendingRetryAfter = time.Second
+
+// LoginGuardService tracks failed logins per username and per client IP and
+// applies exponential backoff and temporary lockouts
+type LoginGuardService struct {
+	mu       sync.Mutex
+	config   LoginGuardConfig
+	failures map[string]*entity.LoginLockout
+	pending  map[string]int
+}
+
+// NewLoginGuardService creates a new instance of LoginGuardService
+func NewLoginGuardService(config LoginGuardConfig) *LoginGuardService {
+	return &LoginGuardService{
+		config:   config,
+		failures: make(map[string]*entity.LoginLockout),
+		pending:  make(map[string]int),
+	}
+}
+
+// Check returns a LoginThrottledError if the username or client IP may not
+// attempt a login now. Otherwise the attempt is reserved until Release, so
+// that attempts running in parallel cannot outpace the backoff: a username
+// has one attempt running at a time, and a client IP no more than it has
+// failures left before its lockout.
+func (s *LoginGuardService) Check(username, clientIP string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	user, ip := userKey(username), ipKey(clientIP)
+	var retryAfter time.Duration
+	for _, key := range []string{user, ip} {
+		if state := s.state(key, now); state != nil && state.BlockedUntil.After(now) {
+			if wait := state.BlockedUntil.Sub(now); wait > retryAfter {
+				retryAfter = wait
+			}
+		}
+	}
+	if retryAfter == 0 && (s.pending[user] > 0 || s.ipExhausted(ip, now)) {
+		retryAfter = pendingRetryAfter
+	}
+	if retryAfter > 0 {
+		return &LoginThrottledError{RetryAfter: retryAfter}
+	}
+
+	s.pending[user]++
+	s.pending[ip]++
+	return nil
+}
+
+// Release ends an attempt reserved by Check. Its failure, if any, must be
+// recorded first.
+func (s *LoginGuardService) Release(username, clientIP string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, key := range []string{userKey(username), ipKey(clientIP)} {
+		if s.pending[key]--; s.pending[key] <= 0 {
+			delete(s.pending, key)
+		}
+	}
+}
+
+// ipExhausted reports whether the running attempts of a client IP would
+// reach its lockout if they all failed
+func (s *LoginGuardService) ipExhausted(key string, now time.Time) bool {
+	if s.config.IPThreshold <= 0 {
+		return false
+	}
+	attempts := s.pending[key]
+	if state := s.state(key, now); state != nil {
+		attempts += state.Attempts
+	}
+	return attempts >= s.config.IPThreshold
+}
+
+// RecordFailure counts a failed login for the username and client IP
+func (s *LoginGuardService) RecordFailure(username, clientIP string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	s.recordFailure(userKey(username), s.config.UserThreshold, now)
+	s.recordFailure(ipKey(clientIP), s.config.IPThreshold, now)
+}
+
+// RecordSuccess clears the failure count of the username. The client IP count
+// is kept so that one valid account cannot reset an IP's failures.
+func (s *LoginGuardService) RecordSuccess(username string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.failures, userKey(username))
+}
+
+// ListLockouts retrieves the usernames and client IPs with recorded failures
+func (s *LoginGuardService) ListLockouts() []entity.LoginLockout {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	lockouts := []entity.LoginLockout{}
+	for key := range s.failures {
+		if state := s.state(key, now); state != nil {
+			lockout := *state
+			lockout.Locked = lockout.Locked && lockout.BlockedUntil.After(now)
+			lockouts = append(lockouts, lockout)
+		}
+	}
+	sort.Slice(lockouts, func(i, j int) bool {
+		return lockouts[i].LastFailure.After(lockouts[j].LastFailure)
+	})
+	return lockouts
+}
+
+// ClearLockout forgets the failures of a key ("user:<tenant>/<login>" or "ip:<address>").
+// Returns false if nothing was recorded for the key.
+func (s *LoginGuardService) ClearLockout(key string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	_, exists := s.failures[key]
+	delete(s.failures, key)
+	return exists
+}
+
+// PurgeStale drops the failures of keys neither backed off nor locked out
+// that are older than ResetAfter, along with served lockouts
+func (s *LoginGuardService) PurgeStale() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for key := range s.failures {
+		s.state(key, now)
+	}
+}
+
+func (s *LoginGuardService) recordFailure(key string, threshold int, now time.Time) {
+	state := s.state(key, now)
+	if state == nil {
+		state = &entity.LoginLockout{Key: key}
+		s.failures[key] = state
+	}
+	state.Attempts++
+	state.LastFailure = now
+
+	if threshold > 0 && state.Attempts >= threshold {
+		state.Locked = true
+		state.BlockedUntil = now.Add(s.config.LockoutDuration)
+		return
+	}
+
+	delay := s.config.BaseDelay << (state.Attempts - 1)
+	if delay <= 0 || delay > s.config.MaxDelay {
+		delay = s.config.MaxDelay
+	}
+	state.BlockedUntil = now.Add(delay)
+}
+
+// state returns the recorded state of a key, dropping it once it went stale
+func (s *LoginGuardService) state(key string, now time.Time) *entity.LoginLockout {
+	state, exists := s.failures[key]
+	if !exists {
+		return nil
+	}
+	if state.BlockedUntil.Before(now) && now.Sub(state.LastFailure) > s.config.ResetAfter {
+		delete(s.failures, key)
+		return nil
+	}
+	if state.Locked && state.BlockedUntil.Before(now) {
+		// A served lockout starts a fresh count
+		delete(s.failures, key)
+		return nil
+	}
+	return state
+}
+
+// LockoutInTenant reports whether a lockout key concerns a tenant. User keys
+// carry the tenant of the login; IP keys span every tenant.
+func LockoutInTenant(key, tenantID string) bool {
+	return strings.HasPrefix(key, "ip:") || strings.HasPrefix(key, userKey(tenantLogin(tenantID, "")))
+}
+
+func userKey(username string) string {
+	return "user:" + strings.ToLower(strings.TrimSpace(username))
+}
+
+func ipKey(clientIP string) string {
+	return "ip:" + clientIP
+}
@@ -0,0 +1,181 @@
+package service_test
+
+import (
+	"errors"
+	"fmt"
+	"nerp_wrapper/application/service"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestLoginGuardReservesParallelAttempts(t *testing.T) {
+	config := service.DefaultLoginGuardConfig()
+	config.IPThreshold = 3
+	guard := service.NewLoginGuardService(config)
+
+	// Parallel attempts of one username are let through one at a time
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	passed := 0
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if guard.Check("default/admin", "192.0.2.1") == nil {
+				mu.Lock()
+				passed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	if passed != 1 {
+		t.Fatalf("%d parallel attempts of one username passed, want 1", passed)
+	}
+
+	// Its failure is recorded before the attempt is released, so the next
+	// attempt is backed off
+	guard.RecordFailure("default/admin", "192.0.2.1")
+	guard.Release("default/admin", "192.0.2.1")
+	var throttled *service.LoginThrottledError
+	if err := guard.Check("default/admin", "192.0.2.1"); !errors.As(err, &throttled) {
+		t.Fatalf("Check after a failure returned %v, want LoginThrottledError", err)
+	}
+
+	// A client IP runs no more attempts than it has failures left
+	for i := range 3 {
+		if err := guard.Check(fmt.Sprintf("default/user%d", i), "192.0.2.2"); err != nil {
+			t.Fatalf("attempt %d within the IP threshold was refused: %v", i+1, err)
+		}
+	}
+	if err := guard.Check("default/user3", "192.0.2.2"); !errors.As(err, &throttled) {
+		t.Errorf("attempt beyond the IP threshold returned %v, want LoginThrottledError", err)
+	}
+	guard.Release("default/user0", "192.0.2.2")
+	if err := guard.Check("default/user3", "192.0.2.2"); err != nil {
+		t.Errorf("attempt after a release was refused: %v", err)
+	}
+}
+
+// attempt runs a login attempt through the guard, recording a failure unless
+// it succeeds
+func attempt(guard *service.LoginGuardService, username, clientIP string, succeed bool) error {
+	if err := guard.Check(username, clientIP); err != nil {
+		return err
+	}
+	defer guard.Release(username, clientIP)
+	if succeed {
+		guard.RecordSuccess(username)
+	} else {
+		guard.RecordFailure(username, clientIP)
+	}
+	return nil
+}
+
+func shortGuardConfig() service.LoginGuardConfig {
+	return service.LoginGuardConfig{
+		UserThreshold:   3,
+		IPThreshold:     10,
+		BaseDelay:       20 * time.Millisecond,
+		MaxDelay:        30 * time.Millisecond,
+		LockoutDuration: 100 * time.Millisecond,
+		ResetAfter:      time.Hour,
+	}
+}
+
+func TestLoginGuardBacksOffAfterFailures(t *testing.T) {
+	guard := service.NewLoginGuardService(shortGuardConfig())
+
+	if err := attempt(guard, "default/admin", "192.0.2.1", false); err != nil {
+		t.Fatalf("first attempt was refused: %v", err)
+	}
+	var throttled *service.LoginThrottledError
+	err := guard.Check("default/admin", "192.0.2.1")
+	if !errors.As(err, &throttled) {
+		t.Fatalf("Check right after a failure returned %v, want LoginThrottledError", err)
+	}
+	if throttled.RetryAfter <= 0 || throttled.RetryAfter > 20*time.Millisecond {
+		t.Errorf("RetryAfter after one failure is %s, want at most the base delay", throttled.RetryAfter)
+	}
+
+	time.Sleep(25 * time.Millisecond)
+	if err := attempt(guard, "default/admin", "192.0.2.1", false); err != nil {
+		t.Fatalf("attempt after the backoff was refused: %v", err)
+	}
+	// The delay doubles, capped at MaxDelay
+	if err := guard.Check("default/admin", "192.0.2.1"); !errors.As(err, &throttled) || throttled.RetryAfter <= 20*time.Millisecond {
+		t.Errorf("Check after two failures returned %v, want a delay above the base delay", err)
+	}
+}
+
+func TestLoginGuardLockoutExpires(t *testing.T) {
+	guard := service.NewLoginGuardService(shortGuardConfig())
+
+	for i := range 3 {
+		if err := attempt(guard, "default/admin", "192.0.2.1", false); err != nil {
+			t.Fatalf("attempt %d was refused: %v", i+1, err)
+		}
+		time.Sleep(35 * time.Millisecond)
+	}
+	lockouts := guard.ListLockouts()
+	if len(lockouts) == 0 || !lockouts[0].Locked {
+		t.Fatalf("lockouts after reaching the threshold are %+v, want a locked username", lockouts)
+	}
+	if err := guard.Check("default/admin", "192.0.2.1"); err == nil {
+		t.Fatal("locked out username may attempt a login")
+	}
+
+	time.Sleep(110 * time.Millisecond)
+	if err := attempt(guard, "default/admin", "192.0.2.1", false); err != nil {
+		t.Fatalf("attempt after the lockout was refused: %v", err)
+	}
+	// A served lockout starts a fresh count, so one failure does not lock again
+	for _, lockout := range guard.ListLockouts() {
+		if lockout.Key == "user:default/admin" && (lockout.Locked || lockout.Attempts != 1) {
+			t.Errorf("username after its lockout is %+v, want one attempt and no lockout", lockout)
+		}
+	}
+}
+
+func TestLoginGuardSuccessClearsTheUsernameOnly(t *testing.T) {
+	config := shortGuardConfig()
+	config.BaseDelay, config.MaxDelay = time.Nanosecond, time.Nanosecond
+	guard := service.NewLoginGuardService(config)
+
+	for range 2 {
+		attempt(guard, "default/admin", "192.0.2.1", false)
+	}
+	attempt(guard, "default/admin", "192.0.2.1", true)
+
+	attempts := make(map[string]int)
+	for _, lockout := range guard.ListLockouts() {
+		attempts[lockout.Key] = lockout.Attempts
+	}
+	if _, exists := attempts["user:default/admin"]; exists {
+		t.Error("success left the username's failures")
+	}
+	if attempts["ip:192.0.2.1"] != 2 {
+		t.Errorf("client IP has %d failures after a success, want 2 kept", attempts["ip:192.0.2.1"])
+	}
+}
+
+func TestLoginGuardClearLockout(t *testing.T) {
+	guard := service.NewLoginGuardService(shortGuardConfig())
+	for range 3 {
+		guard.RecordFailure("default/admin", "192.0.2.1")
+	}
+	if err := guard.Check("default/admin", "192.0.2.9"); err == nil {
+		t.Fatal("locked out username may attempt a login")
+	}
+
+	if !guard.ClearLockout("user:default/admin") {
+		t.Fatal("ClearLockout did not find the username")
+	}
+	if guard.ClearLockout("user:default/admin") {
+		t.Error("ClearLockout found the username twice")
+	}
+	if err := guard.Check("default/admin", "192.0.2.9"); err != nil {
+		t.Errorf("Check after ClearLockout returned %v", err)
+	}
+}
@@ -0,0 +1,207 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"nerp_wrapper/domain/entity"
+	"nerp_wrapper/domain/repository"
+	"net/url"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// ErrRouteNotCached is returned when purging a route whose responses are not cached
+var ErrRouteNotCached = errors.New("route is not cached")
+
+// ResponseCacheService keeps the responses of expensive read routes for a
+// per-route TTL. Entries are scoped to the tenant and the caller, as record
+// scopes and company restrictions differ between users.
+type ResponseCacheService struct {
+	store repository.CacheStore
+	ttls  map[string]time.Duration
+
+	mu    sync.Mutex
+	stats map[string]*responseCacheStats
+}
+
+// responseCacheStats counts the lookups of one route of a tenant
+type responseCacheStats struct {
+	tenant string
+	route  string
+
+	hits   atomic.Int64
+	misses atomic.Int64
+	purged atomic.Int64
+	errors atomic.Int64
+}
+
+// NewResponseCacheService creates a new instance of ResponseCacheService.
+// ttls maps route paths to how long their responses are kept; routes
+// missing from it, and every route when store is nil, are not cached.
+func NewResponseCacheService(store repository.CacheStore, ttls map[string]time.Duration) *ResponseCacheService {
+	return &ResponseCacheService{store: store, ttls: ttls, stats: make(map[string]*responseCacheStats)}
+}
+
+// TTL returns how long responses of route are kept, zero when they are not cached
+func (s *ResponseCacheService) TTL(route string) time.Duration {
+	if s.store == nil {
+		return 0
+	}
+	return s.ttls[route]
+}
+
+// Key returns the cache key of a request to route by principal. Query
+// parameters are sorted, with their values, and empty ones dropped, so
+// equivalent queries share an entry.
+func (s *ResponseCacheService) Key(principal *entity.Principal, route string, query url.Values) string {
+	normalized := url.Values{}
+	for name, values := range query {
+		for _, value := range values {
+			if value != "" {
+				normalized.Add(name, value)
+			}
+		}
+	}
+	for _, values := range normalized {
+		sort.Strings(values)
+	}
+	// Encode sorts by parameter name
+	digest := sha256.Sum256([]byte(normalized.Encode()))
+
+	caller := fmt.Sprintf("user:%d", principal.UserID)
+	if principal.IsAPIKey() {
+		caller = "key:" + principal.APIKeyID
+	}
+	return responseKeyPrefix(principal.TenantID, route) + caller + ":" + hex.EncodeToString(digest[:16])
+}
+
+// responseKeyPrefix returns the prefix of the keys of a route of a tenant
+func responseKeyPrefix(tenantID, route string) string {
+	return "nerp:resp:" + tenantID + ":" + route + ":"
+}
+
+// ETag returns the strong entity tag of a response body
+func ETag(body []byte) string {
+	digest := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(digest[:16]) + `"`
+}
+
+// Get returns the cached response stored under key. A failing store is
+// logged and reported as a miss.
+func (s *ResponseCacheService) Get(ctx context.Context, tenantID, route, key string) (*entity.CachedResponse, bool) {
+	stats := s.routeStats(tenantID, route)
+	value, found, err := s.store.Get(ctx, key)
+	if err != nil {
+		stats.errors.Add(1)
+		log.Printf("Failed to read response cache for %s: %v", route, err)
+	}
+	if !found {
+		stats.misses.Add(1)
+		return nil, false
+	}
+
+	var response entity.CachedResponse
+	if err := json.Unmarshal(value, &response); err != nil {
+		stats.errors.Add(1)
+		stats.misses.Add(1)
+		return nil, false
+	}
+	stats.hits.Add(1)
+	return &response, true
+}
+
+// Put stores a response under key for the TTL of route
+func (s *ResponseCacheService) Put(ctx context.Context, tenantID, route, key string, response *entity.CachedResponse) {
+	value, err := json.Marshal(response)
+	if err == nil {
+		err = s.store.Set(ctx, key, value, s.TTL(route))
+	}
+	if err != nil {
+		s.routeStats(tenantID, route).errors.Add(1)
+		log.Printf("Failed to write response cache for %s: %v", route, err)
+	}
+}
+
+// Purge drops the cached responses of a tenant, of one route or of every
+// cached route when route is empty, and returns how many were dropped
+func (s *ResponseCacheService) Purge(ctx context.Context, tenantID, route string) (int, error) {
+	routes := []string{route}
+	if route == "" {
+		routes = make([]string, 0, len(s.ttls))
+		for cached := range s.ttls {
+			routes = append(routes, cached)
+		}
+		sort.Strings(routes)
+	} else if s.ttls[route] <= 0 {
+		return 0, fmt.Errorf("%w: %s", ErrRouteNotCached, route)
+	}
+	if s.store == nil {
+		return 0, nil
+	}
+
+	var purged int
+	for _, route := range routes {
+		count, err := s.store.DeletePrefix(ctx, responseKeyPrefix(tenantID, route))
+		purged += count
+		if err != nil {
+			return purged, fmt.Errorf("failed to purge cached responses of %s: %w", route, err)
+		}
+		s.routeStats(tenantID, route).purged.Add(int64(count))
+	}
+	return purged, nil
+}
+
+// CacheStats reports the statistics of every cached route of every tenant,
+// ordered by tenant and route
+func (s *ResponseCacheService) CacheStats() []entity.CacheStats {
+	s.mu.Lock()
+	all := make([]*responseCacheStats, 0, len(s.stats))
+	for _, stats := range s.stats {
+		all = append(all, stats)
+	}
+	s.mu.Unlock()
+
+	sort.Slice(all, func(i, j int) bool {
+		if all[i].tenant != all[j].tenant {
+			return all[i].tenant < all[j].tenant
+		}
+		return all[i].route < all[j].route
+	})
+
+	report := make([]entity.CacheStats, 0, len(all))
+	for _, stats := range all {
+		stat := entity.CacheStats{
+			Cache:         "response",
+			Tenant:        stats.tenant,
+			Kind:          stats.route,
+			Hits:          stats.hits.Load(),
+			Misses:        stats.misses.Load(),
+			Invalidations: stats.purged.Load(),
+			Errors:        stats.errors.Load(),
+		}
+		if lookups := stat.Hits + stat.Misses; lookups > 0 {
+			stat.HitRatio = float64(stat.Hits) / float64(lookups)
+		}
+		report = append(report, stat)
+	}
+	return report
+}
+
+func (s *ResponseCacheService) routeStats(tenantID, route string) *responseCacheStats {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := tenantID + " " + route
+	stats, exists := s.stats[key]
+	if !exists {
+		stats = &responseCacheStats{tenant: tenantID, route: route}
+		s.stats[key] = stats
+	}
+	return stats
+}
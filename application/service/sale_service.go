@@ -1,29 +1,63 @@
 package service
 
 import (
+	"context"
+	"errors"
 	"nerp_wrapper/domain/entity"
-	"nerp_wrapper/infrastructure/odoo"
+	"nerp_wrapper/domain/repository"
 	"time"
 )
 
+// ErrSalesScopeWithoutUser is returned when an API key is limited to its own
+// or its teams' sale orders, which it cannot have as it acts for no Odoo user
+var ErrSalesScopeWithoutUser = errors.New("api key needs the sales:read_all scope to read sale orders")
+
 type SaleService struct {
-	saleRepo *odoo.OdooSaleRepository
+	saleRepo       repository.SaleRepository
+	readTimeout    time.Duration
+	summaryTimeout time.Duration
 }
 
-func NewSaleService(saleRepo *odoo.OdooSaleRepository) *SaleService {
-	return &SaleService{saleRepo: saleRepo}
+// NewSaleService creates a new instance of SaleService. Listing orders must
+// finish within readTimeout and summaries within summaryTimeout.
+func NewSaleService(saleRepo repository.SaleRepository, readTimeout, summaryTimeout time.Duration) *SaleService {
+	return &SaleService{saleRepo: saleRepo, readTimeout: readTimeout, summaryTimeout: summaryTimeout}
 }
 
-func (s *SaleService) GetAllSaleOrders(page, pageSize int) (*entity.SaleOrderPagination, error) {
-	return s.saleRepo.GetAllSaleOrders(page, pageSize)
+func (s *SaleService) GetAllSaleOrders(ctx context.Context, principal *entity.Principal, filter entity.SaleOrderFilter, page, pageSize int) (*entity.SaleOrderPagination, error) {
+	if err := checkSalesScope(principal); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.readTimeout)
+	defer cancel()
+	return s.saleRepo.GetAllSaleOrders(ctx, principal, filter, page, pageSize)
 }
 
 // GetDailySalesSummary retrieves daily sales summary with pagination
-func (s *SaleService) GetDailySalesSummary(page, pageSize int) (*entity.SalesSummaryResponse, error) {
-	return s.saleRepo.GetDailySalesSummary(page, pageSize)
+func (s *SaleService) GetDailySalesSummary(ctx context.Context, principal *entity.Principal, filter entity.SaleOrderFilter, page, pageSize int) (*entity.SalesSummaryResponse, error) {
+	if err := checkSalesScope(principal); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.summaryTimeout)
+	defer cancel()
+	return s.saleRepo.GetDailySalesSummary(ctx, principal, filter, page, pageSize)
 }
 
 // GetPeriodSalesSummary retrieves sales summary for a specific period
-func (s *SaleService) GetPeriodSalesSummary(periodType entity.PeriodType, startDate, endDate *time.Time) (*entity.PeriodSalesSummaryResponse, error) {
-	return s.saleRepo.GetPeriodSalesSummary(periodType, startDate, endDate)
+func (s *SaleService) GetPeriodSalesSummary(ctx context.Context, principal *entity.Principal, filter entity.SaleOrderFilter, periodType entity.PeriodType, startDate, endDate *time.Time) (*entity.PeriodSalesSummaryResponse, error) {
+	if err := checkSalesScope(principal); err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(ctx, s.summaryTimeout)
+	defer cancel()
+	return s.saleRepo.GetPeriodSalesSummary(ctx, principal, filter, periodType, startDate, endDate)
+}
+
+// checkSalesScope rejects API key principals without the all-orders scope,
+// rather than letting their scope filter on a user they do not have
+func checkSalesScope(principal *entity.Principal) error {
+	if principal.IsAPIKey() && principal.SalesScope() != entity.RecordScopeAll {
+		return ErrSalesScopeWithoutUser
+	}
+	return nil
 }
@@ -0,0 +1,99 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"nerp_wrapper/application/service"
+	"nerp_wrapper/domain/entity"
+	"nerp_wrapper/infrastructure/memory"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func loadFixtures(t *testing.T) *memory.Fixtures {
+	t.Helper()
+	fixtures, err := memory.LoadFixtures(filepath.Join("..", "..", "fixtures"))
+	if err != nil {
+		t.Fatalf("failed to load fixtures: %v", err)
+	}
+	return fixtures
+}
+
+func newSaleService(t *testing.T) (*service.SaleService, *memory.Fixtures) {
+	t.Helper()
+	fixtures := loadFixtures(t)
+	return service.NewSaleService(memory.NewMemorySaleRepository(fixtures.SaleOrders), time.Second, time.Second), fixtures
+}
+
+func TestSaleServiceLimitsSalespeopleToTheirOrders(t *testing.T) {
+	saleService, fixtures := newSaleService(t)
+	principal := &entity.Principal{TenantID: "default", UserID: 6, Login: "sales", Permissions: []entity.Permission{entity.PermissionSalesRead}}
+
+	orders, err := saleService.GetAllSaleOrders(context.Background(), principal, entity.SaleOrderFilter{}, 1, 100)
+	if err != nil {
+		t.Fatalf("GetAllSaleOrders failed: %v", err)
+	}
+
+	want := 0
+	for _, order := range fixtures.SaleOrders {
+		if order.SalespersonID == 6 && order.State != "cancel" {
+			want++
+		}
+	}
+	if want == 0 {
+		t.Fatal("fixtures hold no orders of salesperson 6")
+	}
+	if orders.TotalItems != want {
+		t.Errorf("got %d orders, want the %d of salesperson 6", orders.TotalItems, want)
+	}
+	for _, order := range orders.Items {
+		if order.SalespersonID != 6 {
+			t.Errorf("order %s of salesperson %d was returned", order.Name, order.SalespersonID)
+		}
+	}
+}
+
+func TestSaleServiceFiltersByCompany(t *testing.T) {
+	saleService, fixtures := newSaleService(t)
+	principal := &entity.Principal{TenantID: "default", UserID: 2, Login: "admin", Permissions: []entity.Permission{entity.PermissionAdmin}}
+
+	orders, err := saleService.GetAllSaleOrders(context.Background(), principal, entity.SaleOrderFilter{CompanyIDs: []int64{1}}, 1, 100)
+	if err != nil {
+		t.Fatalf("GetAllSaleOrders failed: %v", err)
+	}
+
+	want := 0
+	for _, order := range fixtures.SaleOrders {
+		if order.CompanyID == 1 && order.State != "cancel" {
+			want++
+		}
+	}
+	if want == 0 || want == len(fixtures.SaleOrders) {
+		t.Fatal("fixtures do not split orders across companies")
+	}
+	if orders.TotalItems != want {
+		t.Errorf("got %d orders, want the %d of company 1", orders.TotalItems, want)
+	}
+	for _, order := range orders.Items {
+		if order.CompanyID != 1 {
+			t.Errorf("order %s of company %d was returned", order.Name, order.CompanyID)
+		}
+	}
+}
+
+func TestSaleServiceRejectsAPIKeysWithoutReadAll(t *testing.T) {
+	saleService, _ := newSaleService(t)
+	principal := &entity.Principal{TenantID: "default", Login: "api-key:report", APIKeyID: "key", Permissions: []entity.Permission{entity.PermissionSalesRead}}
+	ctx := context.Background()
+
+	if _, err := saleService.GetAllSaleOrders(ctx, principal, entity.SaleOrderFilter{}, 1, 100); !errors.Is(err, service.ErrSalesScopeWithoutUser) {
+		t.Errorf("GetAllSaleOrders returned %v, want ErrSalesScopeWithoutUser", err)
+	}
+	if _, err := saleService.GetDailySalesSummary(ctx, principal, entity.SaleOrderFilter{}, 1, 100); !errors.Is(err, service.ErrSalesScopeWithoutUser) {
+		t.Errorf("GetDailySalesSummary returned %v, want ErrSalesScopeWithoutUser", err)
+	}
+	if _, err := saleService.GetPeriodSalesSummary(ctx, principal, entity.SaleOrderFilter{}, entity.PeriodTypeMonth, nil, nil); !errors.Is(err, service.ErrSalesScopeWithoutUser) {
+		t.Errorf("GetPeriodSalesSummary returned %v, want ErrSalesScopeWithoutUser", err)
+	}
+}
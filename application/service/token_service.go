@@ -0,0 +1,183 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"nerp_wrapper/domain/entity"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+// ErrInvalidToken is returned when a token cannot be verified
+var ErrInvalidToken = errors.New("invalid token")
+
+// accessTokenClaims is the JWT payload of access and challenge tokens
+type accessTokenClaims struct {
+	Tenant      string              `json:"tenant,omitempty"`
+	Login       string              `json:"login"`
+	Permissions []entity.Permission `json:"perms,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// TokenService issues and verifies signed access and challenge tokens and opaque refresh tokens
+type TokenService struct {
+	secret     []byte
+	issuer     string
+	accessTTL  time.Duration
+	refreshTTL time.Duration
+}
+
+// NewTokenService creates a new instance of TokenService
+func NewTokenService(secret []byte, issuer string, accessTTL, refreshTTL time.Duration) *TokenService {
+	return &TokenService{
+		secret:     secret,
+		issuer:     issuer,
+		accessTTL:  accessTTL,
+		refreshTTL: refreshTTL,
+	}
+}
+
+// Token audiences keep access tokens and login challenge tokens from being
+// accepted in place of one another
+const (
+	accessTokenAudience    = "access"
+	challengeTokenAudience = "totp_challenge"
+	challengeTokenTTL      = 5 * time.Minute
+)
+
+// IssueAccessToken creates a signed access token for the given tenant user and permissions
+func (s *TokenService) IssueAccessToken(tenantID string, user *entity.User, permissions []entity.Permission) (string, *entity.AccessClaims, error) {
+	now := time.Now()
+	claims := &entity.AccessClaims{
+		TokenID:     uuid.NewString(),
+		TenantID:    tenantID,
+		UserID:      user.ID,
+		Login:       user.Username,
+		Permissions: permissions,
+		IssuedAt:    now,
+		ExpiresAt:   now.Add(s.accessTTL),
+	}
+
+	signed, err := s.sign(accessTokenAudience, claims)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to sign access token: %v", err)
+	}
+	return signed, claims, nil
+}
+
+// ParseAccessToken verifies an access token and returns its claims
+func (s *TokenService) ParseAccessToken(tokenString string) (*entity.AccessClaims, error) {
+	return s.parse(accessTokenAudience, tokenString)
+}
+
+// IssueChallengeToken creates a short-lived token proving that the user passed
+// the password step of a two-factor login
+func (s *TokenService) IssueChallengeToken(tenantID string, user *entity.User) (string, *entity.AccessClaims, error) {
+	now := time.Now()
+	claims := &entity.AccessClaims{
+		TokenID:   uuid.NewString(),
+		TenantID:  tenantID,
+		UserID:    user.ID,
+		Login:     user.Username,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(challengeTokenTTL),
+	}
+
+	signed, err := s.sign(challengeTokenAudience, claims)
+	if err != nil {
+		return "", nil, fmt.Errorf("failed to sign challenge token: %v", err)
+	}
+	return signed, claims, nil
+}
+
+// ParseChallengeToken verifies a two-factor challenge token and returns its claims
+func (s *TokenService) ParseChallengeToken(tokenString string) (*entity.AccessClaims, error) {
+	return s.parse(challengeTokenAudience, tokenString)
+}
+
+func (s *TokenService) sign(audience string, claims *entity.AccessClaims) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, accessTokenClaims{
+		Tenant:      claims.TenantID,
+		Login:       claims.Login,
+		Permissions: claims.Permissions,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        claims.TokenID,
+			Issuer:    s.issuer,
+			Audience:  jwt.ClaimStrings{audience},
+			Subject:   strconv.FormatInt(claims.UserID, 10),
+			IssuedAt:  jwt.NewNumericDate(claims.IssuedAt),
+			ExpiresAt: jwt.NewNumericDate(claims.ExpiresAt),
+		},
+	})
+	return token.SignedString(s.secret)
+}
+
+func (s *TokenService) parse(audience, tokenString string) (*entity.AccessClaims, error) {
+	var payload accessTokenClaims
+	_, err := jwt.ParseWithClaims(tokenString, &payload, func(token *jwt.Token) (interface{}, error) {
+		return s.secret, nil
+	},
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithIssuer(s.issuer),
+		jwt.WithAudience(audience),
+		jwt.WithExpirationRequired(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	userID, err := strconv.ParseInt(payload.Subject, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid subject", ErrInvalidToken)
+	}
+
+	claims := &entity.AccessClaims{
+		TokenID:     payload.ID,
+		TenantID:    payload.Tenant,
+		UserID:      userID,
+		Login:       payload.Login,
+		Permissions: payload.Permissions,
+		ExpiresAt:   payload.ExpiresAt.Time,
+	}
+	if payload.IssuedAt != nil {
+		claims.IssuedAt = payload.IssuedAt.Time
+	}
+	return claims, nil
+}
+
+// IssueRefreshToken creates an opaque refresh token belonging to the given family.
+// A new family is started when familyID is empty.
+func (s *TokenService) IssueRefreshToken(tenantID string, user *entity.User, familyID string) (string, *entity.RefreshToken, error) {
+	raw := make([]byte, 32)
+	if _, err := rand.Read(raw); err != nil {
+		return "", nil, fmt.Errorf("failed to generate refresh token: %v", err)
+	}
+	if familyID == "" {
+		familyID = uuid.NewString()
+	}
+
+	value := base64.RawURLEncoding.EncodeToString(raw)
+	now := time.Now()
+	return value, &entity.RefreshToken{
+		TokenHash: HashRefreshToken(value),
+		FamilyID:  familyID,
+		TenantID:  tenantID,
+		UserID:    user.ID,
+		Login:     user.Username,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(s.refreshTTL),
+	}, nil
+}
+
+// HashRefreshToken returns the storage hash of a refresh token value
+func HashRefreshToken(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
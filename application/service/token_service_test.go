@@ -0,0 +1,90 @@
+package service_test
+
+import (
+	"errors"
+	"nerp_wrapper/application/service"
+	"nerp_wrapper/domain/entity"
+	"strings"
+	"testing"
+	"time"
+)
+
+var testSecret = []byte(strings.Repeat("s", 32))
+
+func TestTokenServiceRoundTrip(t *testing.T) {
+	tokens := service.NewTokenService(testSecret, "nerp_wrapper", time.Minute, time.Hour)
+	user := &entity.User{ID: 6, Username: "sales"}
+	permissions := []entity.Permission{entity.PermissionSalesRead}
+
+	signed, issued, err := tokens.IssueAccessToken("acme", user, permissions)
+	if err != nil {
+		t.Fatalf("IssueAccessToken failed: %v", err)
+	}
+	claims, err := tokens.ParseAccessToken(signed)
+	if err != nil {
+		t.Fatalf("ParseAccessToken failed: %v", err)
+	}
+	if claims.TokenID != issued.TokenID || claims.TenantID != "acme" || claims.UserID != 6 || claims.Login != "sales" {
+		t.Errorf("parsed claims are %+v, want %+v", claims, issued)
+	}
+	if len(claims.Permissions) != 1 || claims.Permissions[0] != entity.PermissionSalesRead {
+		t.Errorf("parsed permissions are %v", claims.Permissions)
+	}
+}
+
+func TestTokenServiceRejectsForeignTokens(t *testing.T) {
+	tokens := service.NewTokenService(testSecret, "nerp_wrapper", time.Minute, time.Hour)
+	user := &entity.User{ID: 6, Username: "sales"}
+	access, _, _ := tokens.IssueAccessToken("acme", user, nil)
+	challenge, _, _ := tokens.IssueChallengeToken("acme", user)
+	otherIssuer, _, _ := service.NewTokenService(testSecret, "someone_else", time.Minute, time.Hour).IssueAccessToken("acme", user, nil)
+	otherSecret, _, _ := service.NewTokenService([]byte(strings.Repeat("o", 32)), "nerp_wrapper", time.Minute, time.Hour).IssueAccessToken("acme", user, nil)
+	expired, _, _ := service.NewTokenService(testSecret, "nerp_wrapper", -time.Minute, time.Hour).IssueAccessToken("acme", user, nil)
+
+	tests := []struct {
+		name  string
+		parse func(string) (*entity.AccessClaims, error)
+		token string
+	}{
+		{"challenge token as access token", tokens.ParseAccessToken, challenge},
+		{"access token as challenge token", tokens.ParseChallengeToken, access},
+		{"other issuer", tokens.ParseAccessToken, otherIssuer},
+		{"other secret", tokens.ParseAccessToken, otherSecret},
+		{"expired", tokens.ParseAccessToken, expired},
+		{"tampered", tokens.ParseAccessToken, strings.Replace(access, ".", ".e30", 1)},
+		{"not a token", tokens.ParseAccessToken, "abc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.parse(tt.token); !errors.Is(err, service.ErrInvalidToken) {
+				t.Errorf("parse returned %v, want ErrInvalidToken", err)
+			}
+		})
+	}
+	if _, err := tokens.ParseChallengeToken(challenge); err != nil {
+		t.Errorf("ParseChallengeToken of a challenge token failed: %v", err)
+	}
+}
+
+func TestTokenServiceIssuesRefreshTokensInFamilies(t *testing.T) {
+	tokens := service.NewTokenService(testSecret, "nerp_wrapper", time.Minute, time.Hour)
+	user := &entity.User{ID: 6, Username: "sales"}
+
+	value, stored, err := tokens.IssueRefreshToken("acme", user, "")
+	if err != nil {
+		t.Fatalf("IssueRefreshToken failed: %v", err)
+	}
+	if stored.FamilyID == "" || stored.TokenHash != service.HashRefreshToken(value) || strings.Contains(stored.TokenHash, value) {
+		t.Errorf("stored refresh token is %+v", stored)
+	}
+	if lifetime := stored.ExpiresAt.Sub(stored.IssuedAt); lifetime != time.Hour {
+		t.Errorf("refresh token lives %s, want 1h", lifetime)
+	}
+	next, rotated, err := tokens.IssueRefreshToken("acme", user, stored.FamilyID)
+	if err != nil {
+		t.Fatalf("IssueRefreshToken failed: %v", err)
+	}
+	if rotated.FamilyID != stored.FamilyID || next == value {
+		t.Errorf("rotated token %+v is not a new token of family %s", rotated, stored.FamilyID)
+	}
+}
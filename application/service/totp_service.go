@@ -0,0 +1,172 @@
+package service
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"nerp_wrapper/domain/entity"
+	"nerp_wrapper/domain/repository"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RFC 6238 parameters compatible with common authenticator apps
+const (
+	totpPeriod = 30 * time.Second
+	totpDigits = 6
+	totpSkew   = 1 // Accepted steps before and after the current one
+)
+
+var (
+	// ErrInvalidTOTPCode is returned when a TOTP code does not match or was already used
+	ErrInvalidTOTPCode = errors.New("invalid totp code")
+	// ErrTOTPAlreadyEnrolled is returned when enrolling a user with a confirmed enrollment
+	ErrTOTPAlreadyEnrolled = errors.New("totp already enrolled")
+	// ErrTOTPNotEnrolled is returned when the user has no enrollment to confirm or verify
+	ErrTOTPNotEnrolled = errors.New("totp not enrolled")
+)
+
+// TOTPService handles wrapper-side TOTP two-factor enrollment and verification
+type TOTPService struct {
+	mu       sync.Mutex
+	totpRepo repository.TOTPRepository
+	issuer   string
+}
+
+// NewTOTPService creates a new instance of TOTPService
+func NewTOTPService(totpRepo repository.TOTPRepository, issuer string) *TOTPService {
+	return &TOTPService{
+		totpRepo: totpRepo,
+		issuer:   issuer,
+	}
+}
+
+// IsEnrolled reports whether the user has a confirmed enrollment
+func (s *TOTPService) IsEnrolled(tenantID string, userID int64) (bool, error) {
+	enrollment, err := s.totpRepo.GetTOTPEnrollment(tenantID, userID)
+	if err != nil {
+		return false, err
+	}
+	return enrollment != nil && enrollment.Confirmed, nil
+}
+
+// BeginEnrollment generates a new secret for the user and returns it with its
+// otpauth:// provisioning URI. The enrollment stays pending until confirmed.
+func (s *TOTPService) BeginEnrollment(tenantID string, userID int64, login string) (*entity.TOTPEnrollment, string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, err := s.totpRepo.GetTOTPEnrollment(tenantID, userID)
+	if err != nil {
+		return nil, "", err
+	}
+	if existing != nil && existing.Confirmed {
+		return nil, "", ErrTOTPAlreadyEnrolled
+	}
+
+	raw := make([]byte, 20)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, "", fmt.Errorf("failed to generate totp secret: %v", err)
+	}
+
+	enrollment := &entity.TOTPEnrollment{
+		TenantID:  tenantID,
+		UserID:    userID,
+		Secret:    base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(raw),
+		CreatedAt: time.Now(),
+	}
+	if err := s.totpRepo.SaveTOTPEnrollment(enrollment); err != nil {
+		return nil, "", err
+	}
+	return enrollment, s.provisioningURI(enrollment.Secret, login), nil
+}
+
+// Verify checks a code against the user's enrollment. A pending enrollment is
+// confirmed by its first valid code; each time step is accepted only once.
+func (s *TOTPService) Verify(tenantID string, userID int64, code string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	enrollment, err := s.totpRepo.GetTOTPEnrollment(tenantID, userID)
+	if err != nil {
+		return err
+	}
+	if enrollment == nil {
+		return ErrTOTPNotEnrolled
+	}
+
+	step, ok := matchTOTP(enrollment.Secret, code, time.Now())
+	if !ok || step <= enrollment.LastUsedStep {
+		return ErrInvalidTOTPCode
+	}
+
+	enrollment.LastUsedStep = step
+	if !enrollment.Confirmed {
+		now := time.Now()
+		enrollment.Confirmed = true
+		enrollment.ConfirmedAt = &now
+	}
+	return s.totpRepo.SaveTOTPEnrollment(enrollment)
+}
+
+// Disable removes the user's enrollment after verifying a current code
+func (s *TOTPService) Disable(tenantID string, userID int64, code string) error {
+	if err := s.Verify(tenantID, userID, code); err != nil {
+		return err
+	}
+	return s.totpRepo.DeleteTOTPEnrollment(tenantID, userID)
+}
+
+func (s *TOTPService) provisioningURI(secret, login string) string {
+	query := url.Values{}
+	query.Set("secret", secret)
+	query.Set("issuer", s.issuer)
+	query.Set("algorithm", "SHA1")
+	query.Set("digits", fmt.Sprint(totpDigits))
+	query.Set("period", fmt.Sprint(int(totpPeriod.Seconds())))
+
+	label := url.PathEscape(s.issuer + ":" + login)
+	return "otpauth://totp/" + label + "?" + query.Encode()
+}
+
+// matchTOTP returns the time step matching the code within the allowed skew
+func matchTOTP(secret, code string, now time.Time) (int64, bool) {
+	code = strings.TrimSpace(code)
+	if len(code) != totpDigits {
+		return 0, false
+	}
+	key, err := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return 0, false
+	}
+
+	current := now.Unix() / int64(totpPeriod.Seconds())
+	for offset := int64(-totpSkew); offset <= totpSkew; offset++ {
+		step := current + offset
+		if subtle.ConstantTimeCompare([]byte(totpCode(key, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// totpCode computes the RFC 4226 HOTP value for a time step
+func totpCode(key []byte, step int64) string {
+	var counter [8]byte
+	binary.BigEndian.PutUint64(counter[:], uint64(step))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(counter[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, value%1000000)
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"encoding/base32"
+	"errors"
+	"nerp_wrapper/infrastructure/memory"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA-1 seed of the RFC 6238 test vectors
+var rfc6238Secret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestTOTPCodeMatchesRFC6238(t *testing.T) {
+	// The last six digits of the RFC 6238 appendix B SHA-1 values
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, tt := range tests {
+		step, ok := matchTOTP(rfc6238Secret, tt.code, time.Unix(tt.unix, 0))
+		if !ok || step != tt.unix/30 {
+			t.Errorf("matchTOTP(%q) at %d returned %d, %v; want %d", tt.code, tt.unix, step, ok, tt.unix/30)
+		}
+	}
+}
+
+func TestTOTPAcceptsOneStepOfSkew(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	key, _ := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(rfc6238Secret)
+	current := now.Unix() / 30
+
+	for offset := int64(-3); offset <= 3; offset++ {
+		step, ok := matchTOTP(rfc6238Secret, totpCode(key, current+offset), now)
+		accepted := offset >= -1 && offset <= 1
+		if ok != accepted || (ok && step != current+offset) {
+			t.Errorf("code of step offset %d returned %d, %v; want accepted %v", offset, step, ok, accepted)
+		}
+	}
+	for _, code := range []string{"", "05047", "0504711", "abcdef"} {
+		if _, ok := matchTOTP(rfc6238Secret, code, now); ok {
+			t.Errorf("matchTOTP accepted %q", code)
+		}
+	}
+}
+
+func TestTOTPVerifyRejectsReplay(t *testing.T) {
+	totp := NewTOTPService(memory.NewMemoryTOTPRepository(), "nerp-wrapper")
+	if err := totp.Verify("default", 6, "000000"); !errors.Is(err, ErrTOTPNotEnrolled) {
+		t.Fatalf("Verify without enrollment returned %v, want ErrTOTPNotEnrolled", err)
+	}
+	enrollment, _, err := totp.BeginEnrollment("default", 6, "sales")
+	if err != nil {
+		t.Fatalf("BeginEnrollment failed: %v", err)
+	}
+	key, _ := base32.StdEncoding.WithPadding(base32.NoPadding).DecodeString(enrollment.Secret)
+	current := time.Now().Unix() / 30
+
+	if err := totp.Verify("default", 6, totpCode(key, current)); err != nil {
+		t.Fatalf("Verify of the current code failed: %v", err)
+	}
+	if enrolled, err := totp.IsEnrolled("default", 6); err != nil || !enrolled {
+		t.Errorf("IsEnrolled after the first code returned %v, %v", enrolled, err)
+	}
+	if _, _, err := totp.BeginEnrollment("default", 6, "sales"); !errors.Is(err, ErrTOTPAlreadyEnrolled) {
+		t.Errorf("BeginEnrollment of a confirmed user returned %v, want ErrTOTPAlreadyEnrolled", err)
+	}
+
+	// Neither the used step nor an earlier one within the window is accepted again
+	for _, step := range []int64{current, current - 1} {
+		if err := totp.Verify("default", 6, totpCode(key, step)); !errors.Is(err, ErrInvalidTOTPCode) {
+			t.Errorf("Verify of step %d after step %d returned %v, want ErrInvalidTOTPCode", step, current, err)
+		}
+	}
+	if err := totp.Verify("default", 6, totpCode(key, current+1)); err != nil {
+		t.Errorf("Verify of the next step failed: %v", err)
+	}
+}
@@ -1,14 +1,20 @@
 package auth
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"nerp_wrapper/domain/repository"
+	odooinfra "nerp_wrapper/infrastructure/odoo"
 
 	odoo "github.com/skilld-labs/go-odoo"
 )
 
 // OdooAuthService handles authentication with Odoo
 type OdooAuthService struct {
-	client *odoo.Client
+	client   *odoo.Client
+	url      string
+	database string
 }
 
 // NewOdooAuthService creates a new instance of OdooAuthService
@@ -22,22 +28,19 @@ func NewOdooAuthService(admin, password, database, url string) (*OdooAuthService
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Odoo client: %v", err)
 	}
-	return &OdooAuthService{client: client}, nil
+	return &OdooAuthService{client: client, url: url, database: database}, nil
 }
 
-// Login authenticates a user with Odoo
-func (s *OdooAuthService) Login(username, password string) (bool, error) {
-	// Search for user with matching credentials
-	criteria := odoo.NewCriteria().
-		Add("login", "=", username).
-		Add("password", "=", password)
-
-	users, err := s.client.FindResUsers(criteria)
+// Login authenticates a user with Odoo using a password or API key
+func (s *OdooAuthService) Login(ctx context.Context, username, secret string) (bool, error) {
+	_, err := odooinfra.Authenticate(ctx, s.url, s.database, username, secret)
+	if errors.Is(err, repository.ErrInvalidCredentials) {
+		return false, nil
+	}
 	if err != nil {
-		return false, fmt.Errorf("failed to search for user: %v", err)
+		return false, err
 	}
-
-	return users != nil, nil
+	return true, nil
 }
 
 // Logout handles user logout
@@ -0,0 +1,48 @@
+// Command e2e exercises every route of a running wrapper, typically one
+// pointed at cmd/fakeodoo or started with DATA_SOURCE=fixtures, and exits
+// non-zero when any check fails. Expectations are derived from the fixtures.
+package main
+
+import (
+	"flag"
+	"log"
+	"nerp_wrapper/infrastructure/memory"
+	"nerp_wrapper/interfaces/http/routetest"
+	"net/http"
+	"os"
+	"time"
+)
+
+// logReporter logs the outcome of each check and counts failures
+type logReporter struct {
+	failed int
+}
+
+func (r *logReporter) Logf(format string, args ...interface{}) {
+	log.Printf(format, args...)
+}
+
+func (r *logReporter) Errorf(format string, args ...interface{}) {
+	r.failed++
+	log.Printf(format, args...)
+}
+
+func main() {
+	baseURL := flag.String("api", "http://localhost:3000", "base URL of the running wrapper")
+	fixturesDir := flag.String("fixtures", "fixtures", "directory holding the fixtures the backend serves")
+	flag.Parse()
+
+	fixtures, err := memory.LoadFixtures(*fixturesDir)
+	if err != nil {
+		log.Fatalf("Failed to load fixtures: %v", err)
+	}
+
+	report := &logReporter{}
+	routetest.Run(*baseURL, &http.Client{Timeout: 30 * time.Second}, fixtures, report)
+
+	if report.failed > 0 {
+		log.Printf("%d checks failed", report.failed)
+		os.Exit(1)
+	}
+	log.Println("All checks passed")
+}
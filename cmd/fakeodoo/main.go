@@ -0,0 +1,33 @@
+// Command fakeodoo serves the fixture data over Odoo's XML-RPC API so the
+// wrapper can be run and exercised end to end without a real Odoo instance.
+package main
+
+import (
+	"flag"
+	"log"
+	"nerp_wrapper/infrastructure/memory"
+	"nerp_wrapper/infrastructure/odoo/odootest"
+	"net/http"
+)
+
+func main() {
+	addr := flag.String("addr", ":8069", "address to listen on")
+	database := flag.String("db", "nerp", "database name clients must authenticate against")
+	fixturesDir := flag.String("fixtures", "fixtures", "directory holding users.json, sale_orders.json and invoices.json")
+	delay := flag.Duration("delay", 0, "time every object call waits before answering, to simulate a slow Odoo")
+	failures := flag.Int("failures", 0, "number of object calls answered with 502 before serving normally, -1 for all")
+	flag.Parse()
+
+	fixtures, err := memory.LoadFixtures(*fixturesDir)
+	if err != nil {
+		log.Fatalf("Failed to load fixtures: %v", err)
+	}
+
+	server := odootest.NewServer(*database)
+	server.Seed(fixtures)
+	server.SetDelay(*delay)
+	server.SetFailures(*failures)
+
+	log.Printf("Fake Odoo serving database %s on %s", *database, *addr)
+	log.Fatal(http.ListenAndServe(*addr, server))
+}
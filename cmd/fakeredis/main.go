@@ -0,0 +1,24 @@
+// Command fakeredis serves an in-memory keyspace over the Redis protocol so
+// the wrapper's redis cache backend can be run without a real Redis server.
+package main
+
+import (
+	"flag"
+	"log"
+	"nerp_wrapper/infrastructure/cache/resptest"
+	"net"
+)
+
+func main() {
+	addr := flag.String("addr", ":6379", "address to listen on")
+	password := flag.String("password", "", "password clients must send with AUTH, empty for none")
+	flag.Parse()
+
+	listener, err := net.Listen("tcp", *addr)
+	if err != nil {
+		log.Fatalf("Failed to listen: %v", err)
+	}
+
+	log.Printf("Fake Redis serving on %s", *addr)
+	log.Fatal(resptest.NewServer(*password).Serve(listener))
+}
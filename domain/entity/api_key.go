@@ -0,0 +1,56 @@
+package entity
+
+import (
+	"net/netip"
+	"time"
+)
+
+// APIKey represents a machine-to-machine credential. Only the hash of the
+// key value is stored; Prefix identifies the key in listings.
+type APIKey struct {
+	ID         string
+	TenantID   string
+	Name       string
+	Prefix     string
+	KeyHash    string
+	Scopes     []Permission
+	AllowedIPs []string
+	CreatedBy  int64
+	CreatedAt  time.Time
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	RevokedAt  *time.Time
+}
+
+// IsActive reports whether the key is neither revoked nor expired at the given time
+func (k *APIKey) IsActive(now time.Time) bool {
+	if k.RevokedAt != nil {
+		return false
+	}
+	return k.ExpiresAt == nil || k.ExpiresAt.After(now)
+}
+
+// AllowsIP reports whether the key may be used from the given address.
+// An empty allowlist allows every address; entries are addresses or CIDR prefixes.
+func (k *APIKey) AllowsIP(ip string) bool {
+	if len(k.AllowedIPs) == 0 {
+		return true
+	}
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return false
+	}
+	addr = addr.Unmap()
+	for _, allowed := range k.AllowedIPs {
+		if prefix, err := netip.ParsePrefix(allowed); err == nil {
+			if prefix.Contains(addr) {
+				return true
+			}
+			continue
+		}
+		if allowedAddr, err := netip.ParseAddr(allowed); err == nil && allowedAddr.Unmap() == addr {
+			return true
+		}
+	}
+	return false
+}
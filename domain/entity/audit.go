@@ -0,0 +1,64 @@
+package entity
+
+import "time"
+
+// AuditAction identifies the kind of an audit event
+type AuditAction string
+
+const (
+	AuditActionLoginSuccess   AuditAction = "login.success"
+	AuditActionLoginFailure   AuditAction = "login.failure"
+	AuditActionLoginChallenge AuditAction = "login.challenge" // Password accepted, TOTP code pending
+	AuditActionLogout         AuditAction = "logout"
+	AuditActionDataRead       AuditAction = "data.read"
+)
+
+// AuditEvent represents a single entry of the audit trail
+type AuditEvent struct {
+	ID       string            `json:"id"`
+	Time     time.Time         `json:"time"`
+	Action   AuditAction       `json:"action"`
+	TenantID string            `json:"tenant_id,omitempty"`
+	UserID   int64             `json:"user_id,omitempty"`
+	Login    string            `json:"login,omitempty"`
+	ClientIP string            `json:"client_ip,omitempty"`
+	Route    string            `json:"route,omitempty"`
+	Filters  map[string]string `json:"filters,omitempty"`
+	RowCount int               `json:"row_count"`
+	Status   int               `json:"status,omitempty"`
+	Detail   string            `json:"detail,omitempty"`
+}
+
+// AuditFilter selects audit events. Zero fields match everything.
+type AuditFilter struct {
+	TenantID string
+	UserID   int64
+	Login    string
+	Action   AuditAction
+	From     *time.Time
+	To       *time.Time
+	Limit    int
+}
+
+// Matches reports whether the event satisfies the filter, ignoring Limit
+func (f *AuditFilter) Matches(event *AuditEvent) bool {
+	if f.TenantID != "" && event.TenantID != f.TenantID {
+		return false
+	}
+	if f.UserID != 0 && event.UserID != f.UserID {
+		return false
+	}
+	if f.Login != "" && event.Login != f.Login {
+		return false
+	}
+	if f.Action != "" && event.Action != f.Action {
+		return false
+	}
+	if f.From != nil && event.Time.Before(*f.From) {
+		return false
+	}
+	if f.To != nil && event.Time.After(*f.To) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,32 @@
+package entity
+
+import "time"
+
+// CacheStats represents the hit and miss counts of one kind of cached data
+// of a tenant, such as the partners of its master data
+type CacheStats struct {
+	Cache         string  `json:"cache"`
+	Tenant        string  `json:"tenant"`
+	Kind          string  `json:"kind"`
+	Hits          int64   `json:"hits"`
+	Misses        int64   `json:"misses"`
+	Invalidations int64   `json:"invalidations"`
+	Errors        int64   `json:"errors"`
+	HitRatio      float64 `json:"hit_ratio"`
+}
+
+// CacheReport represents the cache backend in use and its statistics
+type CacheReport struct {
+	Backend string       `json:"backend"`
+	Stats   []CacheStats `json:"stats"`
+}
+
+// CachedResponse represents a response body kept by the response cache,
+// with the audit row count of the request that produced it
+type CachedResponse struct {
+	Body        []byte    `json:"body"`
+	ContentType string    `json:"content_type"`
+	ETag        string    `json:"etag"`
+	RowCount    int       `json:"row_count"`
+	StoredAt    time.Time `json:"stored_at"`
+}
@@ -0,0 +1,10 @@
+package entity
+
+// EnrichmentWarning reports related records that could not be loaded for a
+// list. The fields they fill, such as partner or journal names, are left
+// empty on the items referring to them.
+type EnrichmentWarning struct {
+	Model string  `json:"model"`
+	IDs   []int64 `json:"ids"`
+	Error string  `json:"error"`
+}
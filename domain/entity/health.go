@@ -0,0 +1,35 @@
+package entity
+
+import "time"
+
+// DependencyState is the outcome of a readiness check
+type DependencyState string
+
+const (
+	DependencyUp   DependencyState = "up"
+	DependencyDown DependencyState = "down"
+)
+
+// DependencyStatus represents the result of checking one backing service
+type DependencyStatus struct {
+	Name      string          `json:"name"`
+	Status    DependencyState `json:"status"`
+	Version   string          `json:"version,omitempty"`
+	UID       int64           `json:"uid,omitempty"`
+	LatencyMs int64           `json:"latency_ms"`
+	Error     string          `json:"error,omitempty"`
+	Breaker   string          `json:"breaker,omitempty"`
+}
+
+// ReadinessReport represents the readiness of the service and each of its dependencies
+type ReadinessReport struct {
+	Status       DependencyState    `json:"status"`
+	CheckedAt    time.Time          `json:"checked_at"`
+	Dependencies []DependencyStatus `json:"dependencies"`
+	Detail       string             `json:"detail,omitempty"`
+}
+
+// Ready reports whether every dependency is up
+func (r *ReadinessReport) Ready() bool {
+	return r.Status == DependencyUp
+}
@@ -25,13 +25,60 @@ type Invoice struct {
 	JournalName    string
 	CurrencyID     int64
 	CurrencyName   string
+	CompanyID      int64
+	CompanyName    string
 	Note           string
 }
 
+// InvoiceFilter narrows the invoices returned by list and summary queries
+type InvoiceFilter struct {
+	CompanyIDs   []int64 // Only invoices of these companies; every company when empty
+	Consolidated bool    // Break summary totals down per company
+	IncludeItems bool    // List each invoice under its day in period summaries
+
+	// The following narrow invoice lists only
+	MoveTypes       []string   // Only invoices of these types
+	States          []string   // Only invoices in these states; every state but cancel when empty
+	PaymentStates   []string   // Only invoices with these payment states
+	JournalID       int64      // Only invoices of this journal
+	PartnerID       int64      // Only invoices of this partner
+	CurrencyID      int64      // Only invoices in this currency
+	InvoiceDateFrom *time.Time // Only invoices dated on or after this day
+	InvoiceDateTo   *time.Time // Only invoices dated on or before this day
+	DueDateFrom     *time.Time // Only invoices due on or after this day
+	DueDateTo       *time.Time // Only invoices due on or before this day
+}
+
+// InvoiceMoveTypes lists the journal entry types of invoices and refunds
+var InvoiceMoveTypes = []string{"out_invoice", "out_refund", "in_invoice", "in_refund"}
+
+// InvoiceStates lists the states of an invoice
+var InvoiceStates = []string{"draft", "posted", "cancel"}
+
+// InvoicePaymentStates lists the payment states of an invoice
+var InvoicePaymentStates = []string{"not_paid", "partial", "paid", "reversed"}
+
+// DerivePaymentState derives the payment state of an invoice from its state
+// and residual amount, for data that does not carry it such as fixtures.
+// Reversals cannot be told apart and are reported as paid.
+func (i *Invoice) DerivePaymentState() string {
+	switch {
+	case i.State != "posted":
+		return "not_paid"
+	case i.AmountResidual == 0:
+		return "paid"
+	case i.AmountResidual < i.AmountTotal:
+		return "partial"
+	default:
+		return "not_paid"
+	}
+}
+
 type InvoicePagination struct {
-	Items      []*Invoice `json:"items"`
-	Page       int        `json:"page"`
-	PageSize   int        `json:"page_size"`
-	TotalItems int        `json:"total_items"`
-	TotalPages int        `json:"total_pages"`
+	Items      []*Invoice          `json:"items"`
+	Page       int                 `json:"page"`
+	PageSize   int                 `json:"page_size"`
+	TotalItems int                 `json:"total_items"`
+	TotalPages int                 `json:"total_pages"`
+	Warnings   []EnrichmentWarning `json:"warnings,omitempty"`
 }
@@ -16,7 +16,7 @@ type DailyInvoiceSummary struct {
 	Date         time.Time        `json:"date"`
 	TotalAmount  float64          `json:"total_amount"`
 	InvoiceCount int              `json:"invoice_count"`
-	Invoices     []InvoiceSummary `json:"invoices"`
+	Invoices     []InvoiceSummary `json:"invoices,omitempty"`
 }
 
 // InvoiceSummaryResponse represents the paginated response for invoice summary
@@ -28,13 +28,22 @@ type InvoiceSummaryResponse struct {
 	TotalPages int                   `json:"total_pages"`
 }
 
+// CompanyInvoiceSummary represents the invoice totals of one company in a consolidated summary
+type CompanyInvoiceSummary struct {
+	CompanyID    int64   `json:"company_id"`
+	CompanyName  string  `json:"company_name"`
+	TotalAmount  float64 `json:"total_amount"`
+	InvoiceCount int     `json:"invoice_count"`
+}
+
 // PeriodInvoiceSummaryResponse represents the response for period-based invoice summary
 type PeriodInvoiceSummaryResponse struct {
-	Period       string                `json:"period"`
-	PeriodType   PeriodType            `json:"period_type"`
-	DateRange    DateRange             `json:"date_range"`
-	Items        []DailyInvoiceSummary `json:"items"`
-	TotalAmount  float64               `json:"total_amount"`
-	InvoiceCount int                   `json:"invoice_count"`
-	AverageDaily float64               `json:"average_daily"`
+	Period       string                  `json:"period"`
+	PeriodType   PeriodType              `json:"period_type"`
+	DateRange    DateRange               `json:"date_range"`
+	Items        []DailyInvoiceSummary   `json:"items"`
+	TotalAmount  float64                 `json:"total_amount"`
+	InvoiceCount int                     `json:"invoice_count"`
+	AverageDaily float64                 `json:"average_daily"`
+	Companies    []CompanyInvoiceSummary `json:"companies,omitempty"`
 }
@@ -0,0 +1,12 @@
+package entity
+
+import "time"
+
+// LoginLockout represents the failed login state of a username or client IP
+type LoginLockout struct {
+	Key          string    `json:"key"`
+	Attempts     int       `json:"attempts"`
+	LastFailure  time.Time `json:"last_failure"`
+	BlockedUntil time.Time `json:"blocked_until"`
+	Locked       bool      `json:"locked"`
+}
@@ -0,0 +1,61 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+// DateRange returns the start and end of the period ending at now. A custom
+// range is used instead when both customStart and customEnd are given.
+func (p PeriodType) DateRange(now time.Time, customStart, customEnd *time.Time) (time.Time, time.Time) {
+	if customStart != nil && customEnd != nil {
+		return *customStart, *customEnd
+	}
+
+	switch p {
+	case PeriodTypeDay:
+		return now.AddDate(0, 0, -1), now
+	case PeriodTypeWeek:
+		return now.AddDate(0, 0, -7), now
+	case PeriodTypeQuarter:
+		return now.AddDate(0, 0, -90), now
+	case PeriodTypeMonthly:
+		start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+		return start, start.AddDate(0, 1, 0).Add(-time.Second)
+	case PeriodTypeYearly:
+		start := time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location())
+		return start, start.AddDate(1, 0, 0).Add(-time.Second)
+	default:
+		return now.AddDate(0, 0, -30), now // Default to last 30 days
+	}
+}
+
+// Describe returns the human readable name of the period between start and end
+func (p PeriodType) Describe(start, end time.Time) string {
+	switch p {
+	case PeriodTypeDay:
+		return "Last 24 Hours"
+	case PeriodTypeWeek:
+		return "Last 7 Days"
+	case PeriodTypeMonth:
+		return "Last 30 Days"
+	case PeriodTypeQuarter:
+		return "Last 90 Days"
+	case PeriodTypeMonthly:
+		return end.Format("January 2006")
+	case PeriodTypeYearly:
+		return end.Format("2006")
+	default:
+		return fmt.Sprintf("%s to %s", start.Format("2006-01-02"), end.Format("2006-01-02"))
+	}
+}
+
+// PeriodDays returns the number of days between start and end, at least one,
+// used to compute daily averages
+func PeriodDays(start, end time.Time) float64 {
+	days := end.Sub(start).Hours() / 24
+	if days < 1 {
+		days = 1
+	}
+	return days
+}
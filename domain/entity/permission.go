@@ -0,0 +1,48 @@
+package entity
+
+// Permission represents an API permission granted to a principal
+type Permission string
+
+const (
+	PermissionUsersRead     Permission = "users:read"      // Read user profiles
+	PermissionSalesRead     Permission = "sales:read"      // Read sale orders and summaries
+	PermissionSalesReadTeam Permission = "sales:read_team" // Read sale orders of the user's sales teams
+	PermissionSalesReadAll  Permission = "sales:read_all"  // Read every sale order, not only the user's own
+	PermissionInvoicesRead  Permission = "invoices:read"   // Read invoices and summaries
+	PermissionAdmin         Permission = "admin"           // Administer the wrapper
+)
+
+// AccessPolicy maps Odoo group external IDs (module.name) to API permissions
+type AccessPolicy struct {
+	Groups map[string][]Permission `yaml:"groups" json:"groups"`
+}
+
+// PermissionsFor returns the de-duplicated permissions granted by the given groups
+func (p *AccessPolicy) PermissionsFor(groups []string) []Permission {
+	seen := make(map[Permission]bool)
+	var permissions []Permission
+	for _, group := range groups {
+		for _, permission := range p.Groups[group] {
+			if !seen[permission] {
+				seen[permission] = true
+				permissions = append(permissions, permission)
+			}
+		}
+	}
+	return permissions
+}
+
+// DefaultAccessPolicy returns the policy used when no policy file is configured
+func DefaultAccessPolicy() *AccessPolicy {
+	return &AccessPolicy{
+		Groups: map[string][]Permission{
+			"base.group_system":                        {PermissionAdmin},
+			"sales_team.group_sale_salesman":           {PermissionSalesRead},
+			"sales_team.group_sale_salesman_all_leads": {PermissionSalesRead, PermissionSalesReadAll},
+			"sales_team.group_sale_manager":            {PermissionSalesRead, PermissionSalesReadAll},
+			"account.group_account_invoice":            {PermissionInvoicesRead},
+			"account.group_account_readonly":           {PermissionInvoicesRead},
+			"account.group_account_manager":            {PermissionInvoicesRead},
+		},
+	}
+}
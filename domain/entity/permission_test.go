@@ -0,0 +1,40 @@
+package entity
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestDefaultAccessPolicy(t *testing.T) {
+	tests := []struct {
+		name   string
+		groups []string
+		want   []Permission
+	}{
+		{"internal user", []string{"base.group_user"}, nil},
+		{"salesperson", []string{"base.group_user", "sales_team.group_sale_salesman"}, []Permission{PermissionSalesRead}},
+		{"sales manager", []string{"sales_team.group_sale_salesman", "sales_team.group_sale_manager"}, []Permission{PermissionSalesRead, PermissionSalesReadAll}},
+		{"billing", []string{"account.group_account_invoice"}, []Permission{PermissionInvoicesRead}},
+		{"administrator", []string{"base.group_system"}, []Permission{PermissionAdmin}},
+		{"unknown group", []string{"stock.group_stock_user"}, nil},
+	}
+	policy := DefaultAccessPolicy()
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := policy.PermissionsFor(tt.groups); !slices.Equal(got, tt.want) {
+				t.Errorf("PermissionsFor(%v) = %v, want %v", tt.groups, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPrincipalHasPermission(t *testing.T) {
+	salesperson := &Principal{Permissions: []Permission{PermissionSalesRead}}
+	if !salesperson.HasPermission(PermissionSalesRead) || salesperson.HasPermission(PermissionInvoicesRead) || salesperson.HasPermission(PermissionUsersRead) {
+		t.Errorf("salesperson permissions are not enforced as granted: %v", salesperson.Permissions)
+	}
+	admin := &Principal{Permissions: []Permission{PermissionAdmin}}
+	if !admin.HasPermission(PermissionUsersRead) || !admin.HasPermission(PermissionSalesReadAll) {
+		t.Error("admin lacks a permission")
+	}
+}
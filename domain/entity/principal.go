@@ -0,0 +1,47 @@
+package entity
+
+// Principal represents the authenticated caller of a request. Callers
+// authenticated with an API key have an APIKeyID and no Odoo user.
+type Principal struct {
+	TenantID    string
+	UserID      int64
+	Login       string
+	Permissions []Permission
+	APIKeyID    string
+}
+
+// IsAPIKey reports whether the principal authenticated with an API key
+func (p *Principal) IsAPIKey() bool {
+	return p.APIKeyID != ""
+}
+
+// HasPermission reports whether the principal has been granted a permission
+func (p *Principal) HasPermission(permission Permission) bool {
+	for _, granted := range p.Permissions {
+		if granted == permission || granted == PermissionAdmin {
+			return true
+		}
+	}
+	return false
+}
+
+// RecordScope represents which records of a model a principal may see
+type RecordScope string
+
+const (
+	RecordScopeOwn  RecordScope = "own"  // Only records assigned to the principal
+	RecordScopeTeam RecordScope = "team" // Records of the principal's teams and own records
+	RecordScopeAll  RecordScope = "all"  // Every record
+)
+
+// SalesScope returns the sale order visibility of the principal
+func (p *Principal) SalesScope() RecordScope {
+	switch {
+	case p.HasPermission(PermissionSalesReadAll):
+		return RecordScopeAll
+	case p.HasPermission(PermissionSalesReadTeam):
+		return RecordScopeTeam
+	default:
+		return RecordScopeOwn
+	}
+}
@@ -19,15 +19,39 @@ type SaleOrder struct {
 	ClientOrderRef    string
 	SalespersonID     int64
 	SalespersonName   string
+	CompanyID         int64
+	CompanyName       string
 	AmountTotal       float64
 	State             string
 	Note              string
 }
 
+// SaleOrderFilter narrows the sale orders returned by list and summary queries
+type SaleOrderFilter struct {
+	CompanyIDs   []int64 // Only orders of these companies; every company when empty
+	Consolidated bool    // Break summary totals down per company
+	IncludeItems bool    // List each order under its day in period summaries
+
+	// The following narrow sale order lists only
+	States        []string   // Only orders in these states; every state but cancel when empty
+	PartnerID     int64      // Only orders of this customer
+	SalespersonID int64      // Only orders of this salesperson
+	TeamID        int64      // Only orders of this sales team
+	DateFrom      *time.Time // Only orders placed on or after this day
+	DateTo        *time.Time // Only orders placed on or before this day
+	AmountMin     *float64   // Only orders totalling at least this amount
+	AmountMax     *float64   // Only orders totalling at most this amount
+	Search        string     // Only orders whose name, customer reference or customer name contains this text
+}
+
+// SaleOrderStates lists the states of a sale order
+var SaleOrderStates = []string{"draft", "sent", "sale", "done", "cancel"}
+
 type SaleOrderPagination struct {
-	Items      []*SaleOrder `json:"items"`
-	Page       int          `json:"page"`
-	PageSize   int          `json:"page_size"`
-	TotalItems int          `json:"total_items"`
-	TotalPages int          `json:"total_pages"`
+	Items      []*SaleOrder        `json:"items"`
+	Page       int                 `json:"page"`
+	PageSize   int                 `json:"page_size"`
+	TotalItems int                 `json:"total_items"`
+	TotalPages int                 `json:"total_pages"`
+	Warnings   []EnrichmentWarning `json:"warnings,omitempty"`
 }
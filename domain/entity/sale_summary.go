@@ -34,7 +34,7 @@ type DailySalesSummary struct {
 	Date        time.Time          `json:"date"`
 	TotalAmount float64            `json:"total_amount"`
 	OrderCount  int                `json:"order_count"`
-	Orders      []SaleOrderSummary `json:"orders"`
+	Orders      []SaleOrderSummary `json:"orders,omitempty"`
 }
 
 // SalesSummaryResponse represents the paginated response for sales summary
@@ -46,13 +46,22 @@ type SalesSummaryResponse struct {
 	TotalPages int                 `json:"total_pages"`
 }
 
+// CompanySalesSummary represents the sales totals of one company in a consolidated summary
+type CompanySalesSummary struct {
+	CompanyID   int64   `json:"company_id"`
+	CompanyName string  `json:"company_name"`
+	TotalAmount float64 `json:"total_amount"`
+	OrderCount  int     `json:"order_count"`
+}
+
 // PeriodSalesSummaryResponse represents the response for period-based sales summary
 type PeriodSalesSummaryResponse struct {
-	Period       string              `json:"period"`
-	PeriodType   PeriodType          `json:"period_type"`
-	DateRange    DateRange           `json:"date_range"`
-	Items        []DailySalesSummary `json:"items"`
-	TotalAmount  float64             `json:"total_amount"`
-	OrderCount   int                 `json:"order_count"`
-	AverageDaily float64             `json:"average_daily"`
+	Period       string                `json:"period"`
+	PeriodType   PeriodType            `json:"period_type"`
+	DateRange    DateRange             `json:"date_range"`
+	Items        []DailySalesSummary   `json:"items"`
+	TotalAmount  float64               `json:"total_amount"`
+	OrderCount   int                   `json:"order_count"`
+	AverageDaily float64               `json:"average_daily"`
+	Companies    []CompanySalesSummary `json:"companies,omitempty"`
 }
@@ -0,0 +1,9 @@
+package entity
+
+// Tenant represents one Odoo database served by the wrapper, typically one
+// legal entity. Requests select it by subdomain, X-Tenant header or token claim.
+type Tenant struct {
+	ID        string `json:"id"`
+	Name      string `json:"name"`
+	Subdomain string `json:"subdomain"`
+}
@@ -0,0 +1,36 @@
+package entity
+
+import "time"
+
+// AccessClaims represents the claims carried by a signed access token
+type AccessClaims struct {
+	TokenID     string
+	TenantID    string
+	UserID      int64
+	Login       string
+	Permissions []Permission
+	IssuedAt    time.Time
+	ExpiresAt   time.Time
+}
+
+// AuthTokens represents the tokens issued to an authenticated user
+type AuthTokens struct {
+	AccessToken           string
+	AccessTokenExpiresAt  time.Time
+	RefreshToken          string
+	RefreshTokenExpiresAt time.Time
+}
+
+// RefreshToken represents a stored refresh token. Only the hash of the
+// token value is kept; tokens rotated from one another share a FamilyID.
+type RefreshToken struct {
+	TokenHash string
+	FamilyID  string
+	TenantID  string
+	UserID    int64
+	Login     string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	RevokedAt *time.Time
+}
@@ -0,0 +1,29 @@
+package entity
+
+import "time"
+
+// TOTPEnrollment represents a user's wrapper-side TOTP two-factor enrollment.
+// An enrollment only protects logins once it has been confirmed with a code.
+type TOTPEnrollment struct {
+	TenantID     string
+	UserID       int64
+	Secret       string
+	Confirmed    bool
+	CreatedAt    time.Time
+	ConfirmedAt  *time.Time
+	LastUsedStep int64
+}
+
+// LoginResult represents the outcome of the password step of a login: either
+// the issued tokens, or a challenge when a second factor is required
+type LoginResult struct {
+	User      *User
+	Tokens    *AuthTokens
+	Challenge *TwoFactorChallenge
+}
+
+// TwoFactorChallenge represents a pending TOTP login step
+type TwoFactorChallenge struct {
+	Token     string
+	ExpiresAt time.Time
+}
@@ -0,0 +1,24 @@
+package repository
+
+import (
+	"nerp_wrapper/domain/entity"
+	"time"
+)
+
+// APIKeyRepository defines the interface for API key storage
+type APIKeyRepository interface {
+	// CreateAPIKey stores a new API key
+	CreateAPIKey(key *entity.APIKey) error
+
+	// ListAPIKeys retrieves every API key of a tenant, newest first
+	ListAPIKeys(tenantID string) ([]*entity.APIKey, error)
+
+	// GetAPIKeyByHash retrieves an API key by the hash of its value. Returns nil if not found.
+	GetAPIKeyByHash(keyHash string) (*entity.APIKey, error)
+
+	// RevokeAPIKey marks an API key of a tenant as revoked. Returns false if the key does not exist.
+	RevokeAPIKey(tenantID, id string, revokedAt time.Time) (bool, error)
+
+	// TouchAPIKey records the time an API key was last used
+	TouchAPIKey(id string, usedAt time.Time) error
+}
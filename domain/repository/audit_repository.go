@@ -0,0 +1,12 @@
+package repository
+
+import "nerp_wrapper/domain/entity"
+
+// AuditRepository defines the interface for the append-only audit trail
+type AuditRepository interface {
+	// AppendAuditEvent stores an audit event. Stored events are never modified.
+	AppendAuditEvent(event *entity.AuditEvent) error
+
+	// ListAuditEvents retrieves the events matching the filter, newest first
+	ListAuditEvents(filter entity.AuditFilter) ([]*entity.AuditEvent, error)
+}
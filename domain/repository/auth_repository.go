@@ -1,15 +1,28 @@
 package repository
 
-import "nerp_wrapper/domain/entity"
+import (
+	"context"
+	"nerp_wrapper/domain/entity"
+)
 
 // AuthRepository defines the interface for authentication operations
 type AuthRepository interface {
-	// Login authenticates a user with the given credentials
-	Login(username, password string) (*entity.User, error)
+	// Login authenticates a user with the given password or API key
+	Login(ctx context.Context, username, secret string) (*entity.User, error)
 
-	// Logout handles user logout
-	Logout() error
+	// Logout ends one login of the user in the backend
+	Logout(ctx context.Context, userID int64) error
+
+	// HasSession reports whether the user still has a login in the backend,
+	// keeping it from expiring as idle
+	HasSession(userID int64) bool
 
 	// GetUserInfo retrieves user information by ID
-	GetUserInfo(userID int64) (*entity.User, error)
+	GetUserInfo(ctx context.Context, userID int64) (*entity.User, error)
+
+	// GetUserGroups retrieves the external IDs (module.name) of the user's groups
+	GetUserGroups(ctx context.Context, userID int64) ([]string, error)
+
+	// IsTwoFactorEnabled reports whether the user has enabled two-factor authentication in the backend
+	IsTwoFactorEnabled(ctx context.Context, userID int64) (bool, error)
 }
@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+	"nerp_wrapper/domain/entity"
+	"time"
+)
+
+// CacheStore defines the interface for a key-value cache whose entries expire
+type CacheStore interface {
+	// Name identifies the backend in cache reports
+	Name() string
+
+	// Get returns the value of key, reporting whether it was found
+	Get(ctx context.Context, key string) ([]byte, bool, error)
+
+	// GetMany returns the values of the keys that were found
+	GetMany(ctx context.Context, keys []string) (map[string][]byte, error)
+
+	// Set stores value under key until ttl passes
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+
+	// SetMany stores every value under its key until ttl passes
+	SetMany(ctx context.Context, values map[string][]byte, ttl time.Duration) error
+
+	// Delete removes the keys, ignoring missing ones
+	Delete(ctx context.Context, keys ...string) error
+
+	// DeletePrefix removes every key starting with prefix and returns how
+	// many were removed
+	DeletePrefix(ctx context.Context, prefix string) (int, error)
+}
+
+// CacheStatsReporter defines the interface for caches reporting their hit
+// and miss statistics
+type CacheStatsReporter interface {
+	CacheStats() []entity.CacheStats
+}
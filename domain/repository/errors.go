@@ -0,0 +1,21 @@
+package repository
+
+import "errors"
+
+// ErrSessionExpired is returned when the caller no longer has a backend session
+// and has to log in again
+var ErrSessionExpired = errors.New("session expired")
+
+// ErrInvalidCredentials is returned when the backend rejects the given login and secret
+var ErrInvalidCredentials = errors.New("invalid credentials")
+
+// ErrUnknownTenant is returned when a request names a tenant that is not configured
+var ErrUnknownTenant = errors.New("unknown tenant")
+
+// ErrBackendTimeout is returned when the backend does not answer before the
+// operation's deadline
+var ErrBackendTimeout = errors.New("backend did not respond in time")
+
+// ErrBackendUnavailable is returned when the backend keeps failing and calls
+// are rejected without being attempted
+var ErrBackendUnavailable = errors.New("backend unavailable")
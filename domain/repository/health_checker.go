@@ -0,0 +1,16 @@
+package repository
+
+import (
+	"context"
+	"nerp_wrapper/domain/entity"
+)
+
+// HealthChecker defines the interface for probing a backing service
+type HealthChecker interface {
+	// Name identifies the dependency in readiness reports
+	Name() string
+
+	// CheckHealth probes the dependency and reports whether it is usable,
+	// giving up when ctx is done
+	CheckHealth(ctx context.Context) entity.DependencyStatus
+}
@@ -0,0 +1,20 @@
+package repository
+
+import (
+	"context"
+	"nerp_wrapper/domain/entity"
+	"time"
+)
+
+// InvoiceRepository defines the interface for reading invoices
+type InvoiceRepository interface {
+	// GetAllInvoices retrieves a page of invoices that are not cancelled
+	GetAllInvoices(ctx context.Context, principal *entity.Principal, filter entity.InvoiceFilter, page, pageSize int) (*entity.InvoicePagination, error)
+
+	// GetDailyInvoiceSummary retrieves a page of posted invoices grouped by day
+	GetDailyInvoiceSummary(ctx context.Context, principal *entity.Principal, filter entity.InvoiceFilter, page, pageSize int) (*entity.InvoiceSummaryResponse, error)
+
+	// GetPeriodInvoiceSummary retrieves the posted invoices of a period grouped
+	// by day, or of the custom range when both dates are given
+	GetPeriodInvoiceSummary(ctx context.Context, principal *entity.Principal, filter entity.InvoiceFilter, periodType entity.PeriodType, customStartDate, customEndDate *time.Time) (*entity.PeriodInvoiceSummaryResponse, error)
+}
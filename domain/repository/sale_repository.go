@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"context"
+	"nerp_wrapper/domain/entity"
+	"time"
+)
+
+// SaleRepository defines the interface for reading sale orders. Every method
+// only returns the orders the principal is allowed to see.
+type SaleRepository interface {
+	// GetAllSaleOrders retrieves a page of sale orders that are not cancelled
+	GetAllSaleOrders(ctx context.Context, principal *entity.Principal, filter entity.SaleOrderFilter, page, pageSize int) (*entity.SaleOrderPagination, error)
+
+	// GetDailySalesSummary retrieves a page of confirmed sale orders grouped by day
+	GetDailySalesSummary(ctx context.Context, principal *entity.Principal, filter entity.SaleOrderFilter, page, pageSize int) (*entity.SalesSummaryResponse, error)
+
+	// GetPeriodSalesSummary retrieves the confirmed sale orders of a period
+	// grouped by day, or of the custom range when both dates are given
+	GetPeriodSalesSummary(ctx context.Context, principal *entity.Principal, filter entity.SaleOrderFilter, periodType entity.PeriodType, customStartDate, customEndDate *time.Time) (*entity.PeriodSalesSummaryResponse, error)
+}
@@ -0,0 +1,17 @@
+package repository
+
+import "nerp_wrapper/domain/entity"
+
+// TenantRegistry defines the interface for looking up tenants and their repositories
+type TenantRegistry interface {
+	// ResolveTenant retrieves a tenant by ID, or the default tenant when id is
+	// empty. Returns ErrUnknownTenant if no tenant has the ID.
+	ResolveTenant(id string) (*entity.Tenant, error)
+
+	// TenantForHost retrieves the tenant whose subdomain is the first label of
+	// the host. Returns nil if none matches.
+	TenantForHost(host string) *entity.Tenant
+
+	// AuthRepository retrieves the auth repository of a tenant
+	AuthRepository(tenantID string) (AuthRepository, error)
+}
@@ -0,0 +1,31 @@
+package repository
+
+import (
+	"nerp_wrapper/domain/entity"
+	"time"
+)
+
+// TokenRepository defines the interface for refresh token and access token revocation storage
+type TokenRepository interface {
+	// SaveRefreshToken stores a newly issued refresh token
+	SaveRefreshToken(token *entity.RefreshToken) error
+
+	// ConsumeRefreshToken marks a refresh token as used and returns it as it was
+	// before consumption, so callers can detect reuse. Returns nil if not found.
+	ConsumeRefreshToken(tokenHash string, usedAt time.Time) (*entity.RefreshToken, error)
+
+	// GetRefreshToken retrieves a refresh token by hash. Returns nil if not found.
+	GetRefreshToken(tokenHash string) (*entity.RefreshToken, error)
+
+	// RevokeRefreshTokenFamily revokes every refresh token of a family
+	RevokeRefreshTokenFamily(familyID string, revokedAt time.Time) error
+
+	// RevokeAccessToken blacklists an access token until it expires
+	RevokeAccessToken(tokenID string, expiresAt time.Time) error
+
+	// IsAccessTokenRevoked reports whether an access token has been blacklisted
+	IsAccessTokenRevoked(tokenID string) (bool, error)
+
+	// DeleteExpired removes refresh tokens and blacklist entries expired before the given time
+	DeleteExpired(before time.Time) error
+}
@@ -0,0 +1,15 @@
+package repository
+
+import "nerp_wrapper/domain/entity"
+
+// TOTPRepository defines the interface for TOTP enrollment storage
+type TOTPRepository interface {
+	// GetTOTPEnrollment retrieves the enrollment of a user. Returns nil if not enrolled.
+	GetTOTPEnrollment(tenantID string, userID int64) (*entity.TOTPEnrollment, error)
+
+	// SaveTOTPEnrollment creates or replaces the enrollment of a user
+	SaveTOTPEnrollment(enrollment *entity.TOTPEnrollment) error
+
+	// DeleteTOTPEnrollment removes the enrollment of a user
+	DeleteTOTPEnrollment(tenantID string, userID int64) error
+}
@@ -0,0 +1,140 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LRUCacheStore implements CacheStore interface in process memory. It keeps
+// at most capacity entries, evicting the least recently used one first.
+type LRUCacheStore struct {
+	mu       sync.Mutex
+	capacity int
+	order    *list.List
+	entries  map[string]*list.Element
+}
+
+type lruEntry struct {
+	key       string
+	value     []byte
+	expiresAt time.Time
+}
+
+// NewLRUCacheStore creates a new instance of LRUCacheStore
+func NewLRUCacheStore(capacity int) *LRUCacheStore {
+	return &LRUCacheStore{
+		capacity: max(capacity, 1),
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// Name identifies the backend in cache reports
+func (s *LRUCacheStore) Name() string {
+	return "memory"
+}
+
+// Get returns the value of key, reporting whether it was found
+func (s *LRUCacheStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	value, ok := s.get(key, time.Now())
+	return value, ok, nil
+}
+
+// GetMany returns the values of the keys that were found
+func (s *LRUCacheStore) GetMany(ctx context.Context, keys []string) (map[string][]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	values := make(map[string][]byte, len(keys))
+	for _, key := range keys {
+		if value, ok := s.get(key, now); ok {
+			values[key] = value
+		}
+	}
+	return values, nil
+}
+
+// Set stores value under key until ttl passes
+func (s *LRUCacheStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.set(key, value, time.Now().Add(ttl))
+	return nil
+}
+
+// SetMany stores every value under its key until ttl passes
+func (s *LRUCacheStore) SetMany(ctx context.Context, values map[string][]byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	expiresAt := time.Now().Add(ttl)
+	for key, value := range values {
+		s.set(key, value, expiresAt)
+	}
+	return nil
+}
+
+// Delete removes the keys, ignoring missing ones
+func (s *LRUCacheStore) Delete(ctx context.Context, keys ...string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, key := range keys {
+		if element, exists := s.entries[key]; exists {
+			s.remove(element)
+		}
+	}
+	return nil
+}
+
+// DeletePrefix removes every key starting with prefix
+func (s *LRUCacheStore) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var deleted int
+	for key, element := range s.entries {
+		if strings.HasPrefix(key, prefix) {
+			s.remove(element)
+			deleted++
+		}
+	}
+	return deleted, nil
+}
+
+func (s *LRUCacheStore) get(key string, now time.Time) ([]byte, bool) {
+	element, exists := s.entries[key]
+	if !exists {
+		return nil, false
+	}
+	entry := element.Value.(*lruEntry)
+	if !now.Before(entry.expiresAt) {
+		s.remove(element)
+		return nil, false
+	}
+	s.order.MoveToFront(element)
+	return entry.value, true
+}
+
+func (s *LRUCacheStore) set(key string, value []byte, expiresAt time.Time) {
+	if element, exists := s.entries[key]; exists {
+		entry := element.Value.(*lruEntry)
+		entry.value, entry.expiresAt = value, expiresAt
+		s.order.MoveToFront(element)
+		return
+	}
+
+	s.entries[key] = s.order.PushFront(&lruEntry{key: key, value: value, expiresAt: expiresAt})
+	for s.order.Len() > s.capacity {
+		s.remove(s.order.Back())
+	}
+}
+
+func (s *LRUCacheStore) remove(element *list.Element) {
+	s.order.Remove(element)
+	delete(s.entries, element.Value.(*lruEntry).key)
+}
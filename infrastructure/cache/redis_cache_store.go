@@ -0,0 +1,316 @@
+package cache
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RedisCacheStore implements CacheStore interface on a server speaking the
+// Redis protocol (RESP), such as Redis, Valkey or KeyDB. Connections are
+// opened on demand and up to poolSize idle ones are kept.
+type RedisCacheStore struct {
+	addr     string
+	password string
+	db       int
+	timeout  time.Duration
+	idle     chan *respConn
+}
+
+// NewRedisCacheStore creates a new instance of RedisCacheStore. Commands
+// without a deadline of their own give up after timeout.
+func NewRedisCacheStore(addr, password string, db, poolSize int, timeout time.Duration) *RedisCacheStore {
+	return &RedisCacheStore{
+		addr:     addr,
+		password: password,
+		db:       db,
+		timeout:  timeout,
+		idle:     make(chan *respConn, max(poolSize, 1)),
+	}
+}
+
+// Name identifies the backend in cache reports
+func (s *RedisCacheStore) Name() string {
+	return "redis"
+}
+
+// Ping checks that the server answers
+func (s *RedisCacheStore) Ping(ctx context.Context) error {
+	_, err := s.do(ctx, []string{"PING"})
+	return err
+}
+
+// Get returns the value of key, reporting whether it was found
+func (s *RedisCacheStore) Get(ctx context.Context, key string) ([]byte, bool, error) {
+	replies, err := s.do(ctx, []string{"GET", key})
+	if err != nil {
+		return nil, false, err
+	}
+	value, ok := replies[0].([]byte)
+	return value, ok, nil
+}
+
+// GetMany returns the values of the keys that were found
+func (s *RedisCacheStore) GetMany(ctx context.Context, keys []string) (map[string][]byte, error) {
+	values := make(map[string][]byte, len(keys))
+	if len(keys) == 0 {
+		return values, nil
+	}
+	replies, err := s.do(ctx, append([]string{"MGET"}, keys...))
+	if err != nil {
+		return nil, err
+	}
+	items, _ := replies[0].([]interface{})
+	if len(items) != len(keys) {
+		return nil, fmt.Errorf("redis: MGET returned %d values for %d keys", len(items), len(keys))
+	}
+	for i, item := range items {
+		if value, ok := item.([]byte); ok {
+			values[keys[i]] = value
+		}
+	}
+	return values, nil
+}
+
+// Set stores value under key until ttl passes
+func (s *RedisCacheStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	_, err := s.do(ctx, setCommand(key, value, ttl))
+	return err
+}
+
+// SetMany stores every value under its key until ttl passes, sending the
+// commands in one pipeline
+func (s *RedisCacheStore) SetMany(ctx context.Context, values map[string][]byte, ttl time.Duration) error {
+	if len(values) == 0 {
+		return nil
+	}
+	commands := make([][]string, 0, len(values))
+	for key, value := range values {
+		commands = append(commands, setCommand(key, value, ttl))
+	}
+	_, err := s.do(ctx, commands...)
+	return err
+}
+
+// Delete removes the keys, ignoring missing ones
+func (s *RedisCacheStore) Delete(ctx context.Context, keys ...string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+	_, err := s.do(ctx, append([]string{"DEL"}, keys...))
+	return err
+}
+
+// DeletePrefix removes every key starting with prefix, walking the keyspace
+// with SCAN so the server is not blocked
+func (s *RedisCacheStore) DeletePrefix(ctx context.Context, prefix string) (int, error) {
+	pattern := globEscaper.Replace(prefix) + "*"
+	var deleted int
+	cursor := "0"
+	for {
+		replies, err := s.do(ctx, []string{"SCAN", cursor, "MATCH", pattern, "COUNT", "500"})
+		if err != nil {
+			return deleted, err
+		}
+		page, _ := replies[0].([]interface{})
+		if len(page) != 2 {
+			return deleted, fmt.Errorf("redis: malformed SCAN reply")
+		}
+		next, _ := page[0].([]byte)
+		items, _ := page[1].([]interface{})
+
+		keys := make([]string, 0, len(items))
+		for _, item := range items {
+			if key, ok := item.([]byte); ok {
+				keys = append(keys, string(key))
+			}
+		}
+		if len(keys) > 0 {
+			replies, err := s.do(ctx, append([]string{"DEL"}, keys...))
+			if err != nil {
+				return deleted, err
+			}
+			count, _ := replies[0].(int64)
+			deleted += int(count)
+		}
+
+		if cursor = string(next); cursor == "0" || cursor == "" {
+			return deleted, nil
+		}
+	}
+}
+
+// globEscaper escapes the characters SCAN MATCH patterns treat specially
+var globEscaper = strings.NewReplacer(`\`, `\\`, "*", `\*`, "?", `\?`, "[", `\[`, "]", `\]`)
+
+func setCommand(key string, value []byte, ttl time.Duration) []string {
+	return []string{"SET", key, string(value), "PX", strconv.FormatInt(max(ttl.Milliseconds(), 1), 10)}
+}
+
+// do sends the commands in one pipeline and returns their replies. A
+// connection that failed is closed rather than reused.
+func (s *RedisCacheStore) do(ctx context.Context, commands ...[]string) ([]interface{}, error) {
+	conn, err := s.conn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	replies, err := conn.pipeline(s.deadline(ctx), commands)
+	var replyErr respError
+	if err != nil && !errors.As(err, &replyErr) {
+		conn.Close()
+		return nil, err
+	}
+	s.release(conn)
+	return replies, err
+}
+
+// conn returns an idle connection or dials a new one
+func (s *RedisCacheStore) conn(ctx context.Context) (*respConn, error) {
+	select {
+	case conn := <-s.idle:
+		return conn, nil
+	default:
+	}
+
+	dialer := net.Dialer{Timeout: s.timeout}
+	netConn, err := dialer.DialContext(ctx, "tcp", s.addr)
+	if err != nil {
+		return nil, fmt.Errorf("redis: %w", err)
+	}
+	conn := &respConn{Conn: netConn, reader: bufio.NewReader(netConn), writer: bufio.NewWriter(netConn)}
+
+	var setup [][]string
+	if s.password != "" {
+		setup = append(setup, []string{"AUTH", s.password})
+	}
+	if s.db != 0 {
+		setup = append(setup, []string{"SELECT", strconv.Itoa(s.db)})
+	}
+	if len(setup) > 0 {
+		if _, err := conn.pipeline(s.deadline(ctx), setup); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
+
+func (s *RedisCacheStore) release(conn *respConn) {
+	select {
+	case s.idle <- conn:
+	default:
+		conn.Close()
+	}
+}
+
+func (s *RedisCacheStore) deadline(ctx context.Context) time.Time {
+	if deadline, ok := ctx.Deadline(); ok {
+		return deadline
+	}
+	return time.Now().Add(s.timeout)
+}
+
+// respError is an error reply of the server
+type respError string
+
+func (e respError) Error() string {
+	return "redis: " + string(e)
+}
+
+// respConn is a connection speaking RESP2
+type respConn struct {
+	net.Conn
+	reader *bufio.Reader
+	writer *bufio.Writer
+}
+
+// pipeline writes every command before reading their replies. The first
+// error reply is returned after all replies were read, keeping the
+// connection usable.
+func (c *respConn) pipeline(deadline time.Time, commands [][]string) ([]interface{}, error) {
+	if err := c.SetDeadline(deadline); err != nil {
+		return nil, err
+	}
+	for _, command := range commands {
+		fmt.Fprintf(c.writer, "*%d\r\n", len(command))
+		for _, arg := range command {
+			fmt.Fprintf(c.writer, "$%d\r\n%s\r\n", len(arg), arg)
+		}
+	}
+	if err := c.writer.Flush(); err != nil {
+		return nil, fmt.Errorf("redis: %w", err)
+	}
+
+	replies := make([]interface{}, len(commands))
+	var firstErr error
+	for i := range commands {
+		reply, err := c.readReply()
+		if err != nil {
+			return nil, err
+		}
+		if replyErr, ok := reply.(respError); ok && firstErr == nil {
+			firstErr = replyErr
+		}
+		replies[i] = reply
+	}
+	return replies, firstErr
+}
+
+// readReply reads one reply: a string, respError, int64, []byte, nil for a
+// missing value, or []interface{} for an array
+func (c *respConn) readReply() (interface{}, error) {
+	line, err := c.reader.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("redis: %w", err)
+	}
+	if len(line) < 3 || line[len(line)-2] != '\r' {
+		return nil, fmt.Errorf("redis: malformed reply %q", line)
+	}
+	kind, body := line[0], line[1:len(line)-2]
+
+	switch kind {
+	case '+':
+		return body, nil
+	case '-':
+		return respError(body), nil
+	case ':':
+		return strconv.ParseInt(body, 10, 64)
+	case '$':
+		size, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, fmt.Errorf("redis: malformed bulk length %q", body)
+		}
+		if size < 0 {
+			return nil, nil
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(c.reader, data); err != nil {
+			return nil, fmt.Errorf("redis: %w", err)
+		}
+		return data[:size], nil
+	case '*':
+		count, err := strconv.Atoi(body)
+		if err != nil {
+			return nil, fmt.Errorf("redis: malformed array length %q", body)
+		}
+		if count < 0 {
+			return nil, nil
+		}
+		items := make([]interface{}, count)
+		for i := range items {
+			if items[i], err = c.readReply(); err != nil {
+				return nil, err
+			}
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("redis: unexpected reply %q", line)
+	}
+}
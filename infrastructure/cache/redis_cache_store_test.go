@@ -0,0 +1,96 @@
+package cache
+
+import (
+	"context"
+	"nerp_wrapper/infrastructure/cache/resptest"
+	"net"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// startRedis serves a fake Redis requiring password on a free local port
+func startRedis(t *testing.T, password string) (*resptest.Server, string) {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("failed to listen: %v", err)
+	}
+	server := resptest.NewServer(password)
+	go server.Serve(listener)
+	t.Cleanup(func() { listener.Close() })
+	return server, listener.Addr().String()
+}
+
+func TestRedisCacheStore(t *testing.T) {
+	server, addr := startRedis(t, "pw")
+	store := NewRedisCacheStore(addr, "pw", 0, 2, time.Second)
+	ctx := context.Background()
+
+	if err := store.Ping(ctx); err != nil {
+		t.Fatalf("Ping failed: %v", err)
+	}
+	values := map[string][]byte{
+		"nerp:md:default:res.partner:9":  []byte(`{"name":"Azure Interior"}`),
+		"nerp:md:default:res.partner:10": []byte(`{"name":"Deco Addict"}`),
+		"nerp:resp:default:/sales":       []byte(`{"items":[]}`),
+	}
+	if err := store.SetMany(ctx, values, time.Minute); err != nil {
+		t.Fatalf("SetMany failed: %v", err)
+	}
+
+	found, err := store.GetMany(ctx, []string{"nerp:md:default:res.partner:9", "nerp:md:default:res.partner:10", "nerp:md:default:res.partner:11"})
+	if err != nil {
+		t.Fatalf("GetMany failed: %v", err)
+	}
+	want := map[string][]byte{
+		"nerp:md:default:res.partner:9":  values["nerp:md:default:res.partner:9"],
+		"nerp:md:default:res.partner:10": values["nerp:md:default:res.partner:10"],
+	}
+	if !reflect.DeepEqual(found, want) {
+		t.Errorf("GetMany returned %q, want %q", found, want)
+	}
+	if calls := server.CallCount("MGET"); calls != 1 {
+		t.Errorf("GetMany sent %d MGET commands, want 1", calls)
+	}
+
+	removed, err := store.DeletePrefix(ctx, "nerp:md:")
+	if err != nil {
+		t.Fatalf("DeletePrefix failed: %v", err)
+	}
+	if removed != 2 {
+		t.Errorf("DeletePrefix removed %d keys, want 2", removed)
+	}
+	if _, exists, err := store.Get(ctx, "nerp:md:default:res.partner:9"); err != nil || exists {
+		t.Errorf("Get after DeletePrefix returned exists %v, error %v", exists, err)
+	}
+	if value, exists, err := store.Get(ctx, "nerp:resp:default:/sales"); err != nil || !exists || string(value) != `{"items":[]}` {
+		t.Errorf("Get of a key outside the prefix returned %q, exists %v, error %v", value, exists, err)
+	}
+}
+
+func TestRedisCacheStoreExpiresEntries(t *testing.T) {
+	_, addr := startRedis(t, "")
+	store := NewRedisCacheStore(addr, "", 0, 1, time.Second)
+	ctx := context.Background()
+
+	if err := store.Set(ctx, "short", []byte("lived"), 50*time.Millisecond); err != nil {
+		t.Fatalf("Set failed: %v", err)
+	}
+	if _, exists, err := store.Get(ctx, "short"); err != nil || !exists {
+		t.Fatalf("Get right after Set returned exists %v, error %v", exists, err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if _, exists, err := store.Get(ctx, "short"); err != nil || exists {
+		t.Errorf("Get after the TTL returned exists %v, error %v", exists, err)
+	}
+}
+
+func TestRedisCacheStoreRejectsWrongPassword(t *testing.T) {
+	_, addr := startRedis(t, "pw")
+	store := NewRedisCacheStore(addr, "wrong", 0, 1, time.Second)
+
+	if err := store.Ping(context.Background()); err == nil {
+		t.Error("Ping succeeded with a wrong password")
+	}
+}
@@ -0,0 +1,332 @@
+// Package resptest provides a fake Redis server for integration tests and
+// offline development. It speaks RESP2 and implements PING, AUTH, SELECT,
+// GET, MGET, SET (with EX and PX), DEL, KEYS, SCAN, DBSIZE and FLUSHDB over an
+// in-memory keyspace, ignoring the selected database.
+package resptest
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Server is a fake Redis instance
+type Server struct {
+	password string
+
+	mu      sync.Mutex
+	entries map[string]entry
+	calls   map[string]int
+}
+
+type entry struct {
+	value     string
+	expiresAt time.Time
+}
+
+// NewServer creates an empty fake Redis instance. A non-empty password must
+// be sent with AUTH before other commands.
+func NewServer(password string) *Server {
+	return &Server{
+		password: password,
+		entries:  make(map[string]entry),
+		calls:    make(map[string]int),
+	}
+}
+
+// CallCount returns how many times command was received
+func (s *Server) CallCount(command string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[strings.ToUpper(command)]
+}
+
+// Serve accepts connections on listener until it is closed
+func (s *Server) Serve(listener net.Listener) error {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		go s.serveConn(conn)
+	}
+}
+
+func (s *Server) serveConn(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	writer := bufio.NewWriter(conn)
+	authenticated := s.password == ""
+
+	for {
+		command, err := readCommand(reader)
+		if err != nil {
+			if !errors.Is(err, io.EOF) {
+				writeError(writer, "ERR Protocol error: "+err.Error())
+				writer.Flush()
+			}
+			return
+		}
+		if len(command) == 0 {
+			continue
+		}
+
+		name := strings.ToUpper(command[0])
+		switch {
+		case name == "AUTH":
+			if len(command) == 2 && command[1] == s.password && s.password != "" {
+				authenticated = true
+				writeSimple(writer, "OK")
+			} else {
+				writeError(writer, "WRONGPASS invalid password")
+			}
+		case !authenticated:
+			writeError(writer, "NOAUTH Authentication required.")
+		default:
+			s.execute(writer, name, command[1:])
+		}
+
+		// Flush once the pipelined commands read so far are answered
+		if reader.Buffered() == 0 {
+			if err := writer.Flush(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (s *Server) execute(w *bufio.Writer, name string, args []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls[name]++
+	now := time.Now()
+
+	switch name {
+	case "PING":
+		writeSimple(w, "PONG")
+	case "SELECT":
+		if len(args) != 1 {
+			writeError(w, "ERR wrong number of arguments for 'select' command")
+			return
+		}
+		writeSimple(w, "OK")
+	case "GET":
+		if len(args) != 1 {
+			writeError(w, "ERR wrong number of arguments for 'get' command")
+			return
+		}
+		value, ok := s.get(args[0], now)
+		writeBulk(w, value, ok)
+	case "MGET":
+		fmt.Fprintf(w, "*%d\r\n", len(args))
+		for _, key := range args {
+			value, ok := s.get(key, now)
+			writeBulk(w, value, ok)
+		}
+	case "SET":
+		s.set(w, args, now)
+	case "DEL":
+		var deleted int
+		for _, key := range args {
+			if _, ok := s.get(key, now); ok {
+				delete(s.entries, key)
+				deleted++
+			}
+		}
+		fmt.Fprintf(w, ":%d\r\n", deleted)
+	case "KEYS":
+		if len(args) != 1 {
+			writeError(w, "ERR wrong number of arguments for 'keys' command")
+			return
+		}
+		writeKeys(w, s.keys(args[0], now))
+	case "SCAN":
+		// Every key is returned in the first page, ending the iteration
+		pattern := "*"
+		for i := 1; i+1 < len(args); i += 2 {
+			if strings.ToUpper(args[i]) == "MATCH" {
+				pattern = args[i+1]
+			}
+		}
+		w.WriteString("*2\r\n")
+		writeBulk(w, "0", true)
+		writeKeys(w, s.keys(pattern, now))
+	case "DBSIZE":
+		for key := range s.entries {
+			s.get(key, now)
+		}
+		fmt.Fprintf(w, ":%d\r\n", len(s.entries))
+	case "FLUSHDB":
+		s.entries = make(map[string]entry)
+		writeSimple(w, "OK")
+	default:
+		writeError(w, fmt.Sprintf("ERR unknown command '%s'", name))
+	}
+}
+
+// set handles SET key value [EX seconds | PX milliseconds]
+func (s *Server) set(w *bufio.Writer, args []string, now time.Time) {
+	if len(args) != 2 && len(args) != 4 {
+		writeError(w, "ERR syntax error")
+		return
+	}
+	stored := entry{value: args[1]}
+	if len(args) == 4 {
+		amount, err := strconv.ParseInt(args[3], 10, 64)
+		if err != nil || amount <= 0 {
+			writeError(w, "ERR invalid expire time in 'set' command")
+			return
+		}
+		switch strings.ToUpper(args[2]) {
+		case "EX":
+			stored.expiresAt = now.Add(time.Duration(amount) * time.Second)
+		case "PX":
+			stored.expiresAt = now.Add(time.Duration(amount) * time.Millisecond)
+		default:
+			writeError(w, "ERR syntax error")
+			return
+		}
+	}
+	s.entries[args[0]] = stored
+	writeSimple(w, "OK")
+}
+
+// keys returns the live keys matching a glob pattern, in order. Callers
+// hold s.mu.
+func (s *Server) keys(pattern string, now time.Time) []string {
+	matcher, err := globRegexp(pattern)
+	if err != nil {
+		return nil
+	}
+	var keys []string
+	for key := range s.entries {
+		if _, ok := s.get(key, now); ok && matcher.MatchString(key) {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// globRegexp translates a Redis glob pattern, where * and ? match any
+// character including slashes, into a regular expression
+func globRegexp(pattern string) (*regexp.Regexp, error) {
+	var expr strings.Builder
+	expr.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		switch c := pattern[i]; c {
+		case '*':
+			expr.WriteString(".*")
+		case '?':
+			expr.WriteString(".")
+		case '[':
+			end := strings.IndexByte(pattern[i:], ']')
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated character class")
+			}
+			expr.WriteString(pattern[i : i+end+1])
+			i += end
+		case '\\':
+			if i+1 < len(pattern) {
+				i++
+			}
+			expr.WriteString(regexp.QuoteMeta(pattern[i : i+1]))
+		default:
+			expr.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	expr.WriteString("$")
+	return regexp.Compile(expr.String())
+}
+
+// get returns the value of key, dropping it once expired. Callers hold s.mu.
+func (s *Server) get(key string, now time.Time) (string, bool) {
+	stored, exists := s.entries[key]
+	if !exists {
+		return "", false
+	}
+	if !stored.expiresAt.IsZero() && !now.Before(stored.expiresAt) {
+		delete(s.entries, key)
+		return "", false
+	}
+	return stored.value, true
+}
+
+// readCommand reads a command sent as an array of bulk strings
+func readCommand(r *bufio.Reader) ([]string, error) {
+	line, err := readLine(r)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "*") {
+		// Inline command, as typed into telnet
+		return strings.Fields(line), nil
+	}
+	count, err := strconv.Atoi(line[1:])
+	if err != nil || count < 0 {
+		return nil, fmt.Errorf("invalid multibulk length")
+	}
+
+	command := make([]string, count)
+	for i := range command {
+		line, err := readLine(r)
+		if err != nil {
+			return nil, err
+		}
+		if !strings.HasPrefix(line, "$") {
+			return nil, fmt.Errorf("expected '$', got '%s'", line)
+		}
+		size, err := strconv.Atoi(line[1:])
+		if err != nil || size < 0 {
+			return nil, fmt.Errorf("invalid bulk length")
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(r, data); err != nil {
+			return nil, err
+		}
+		command[i] = string(data[:size])
+	}
+	return command, nil
+}
+
+func readLine(r *bufio.Reader) (string, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func writeSimple(w *bufio.Writer, value string) {
+	fmt.Fprintf(w, "+%s\r\n", value)
+}
+
+func writeError(w *bufio.Writer, message string) {
+	fmt.Fprintf(w, "-%s\r\n", message)
+}
+
+func writeKeys(w *bufio.Writer, keys []string) {
+	fmt.Fprintf(w, "*%d\r\n", len(keys))
+	for _, key := range keys {
+		writeBulk(w, key, true)
+	}
+}
+
+func writeBulk(w *bufio.Writer, value string, ok bool) {
+	if !ok {
+		w.WriteString("$-1\r\n")
+		return
+	}
+	fmt.Fprintf(w, "$%d\r\n%s\r\n", len(value), value)
+}
@@ -0,0 +1,590 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Config holds the application settings
+type Config struct {
+	Odoo          OdooConfig       `yaml:"odoo"`
+	Tenants       []TenantConfig   `yaml:"tenants"`
+	DefaultTenant string           `yaml:"default_tenant"`
+	Server        ServerConfig     `yaml:"server"`
+	Pagination    PaginationConfig `yaml:"pagination"`
+	Auth          AuthConfig       `yaml:"auth"`
+	Store         StoreConfig      `yaml:"store"`
+	Data          DataConfig       `yaml:"data"`
+	Timeouts      TimeoutConfig    `yaml:"timeouts"`
+	Resilience    ResilienceConfig `yaml:"resilience"`
+	Cache         CacheConfig      `yaml:"cache"`
+}
+
+// OdooConfig holds the Odoo connection settings
+type OdooConfig struct {
+	URL            string        `yaml:"url"`
+	Database       string        `yaml:"database"`
+	AdminUsername  string        `yaml:"admin_username"`
+	AdminPassword  string        `yaml:"admin_password"`
+	SessionIdleTTL time.Duration `yaml:"session_idle_ttl"`
+}
+
+// TenantConfig holds the settings of one Odoo database served by the wrapper
+type TenantConfig struct {
+	ID        string     `yaml:"id"`
+	Name      string     `yaml:"name"`
+	Subdomain string     `yaml:"subdomain"`
+	Odoo      OdooConfig `yaml:"odoo"`
+}
+
+// ServerConfig holds the HTTP server settings
+type ServerConfig struct {
+	ListenAddr        string        `yaml:"listen_addr"`
+	CORSOrigins       []string      `yaml:"cors_origins"`
+	ShutdownTimeout   time.Duration `yaml:"shutdown_timeout"`
+	ReadinessCacheTTL time.Duration `yaml:"readiness_cache_ttl"`
+	DrainPeriod       time.Duration `yaml:"drain_period"`
+
+	// ProxyHeader names the header carrying the client IP set by a reverse
+	// proxy, e.g. X-Real-IP. It is only read from requests coming from
+	// TrustedProxies (IPs or CIDR ranges).
+	ProxyHeader    string   `yaml:"proxy_header"`
+	TrustedProxies []string `yaml:"trusted_proxies"`
+}
+
+// PaginationConfig holds the page size applied to list endpoints
+type PaginationConfig struct {
+	DefaultPageSize int `yaml:"default_page_size"`
+	MaxPageSize     int `yaml:"max_page_size"`
+}
+
+// AuthConfig holds the token and login settings
+type AuthConfig struct {
+	JWTSecret             string        `yaml:"jwt_secret"`
+	AccessTokenTTL        time.Duration `yaml:"access_token_ttl"`
+	RefreshTokenTTL       time.Duration `yaml:"refresh_token_ttl"`
+	AccessPolicyFile      string        `yaml:"access_policy_file"`
+	LoginMaxAttempts      int           `yaml:"login_max_attempts"`
+	LoginMaxAttemptsPerIP int           `yaml:"login_max_attempts_per_ip"`
+}
+
+// StoreConfig selects where tokens, API keys, enrollments and audit events are kept
+type StoreConfig struct {
+	Backend      string `yaml:"backend"`
+	Path         string `yaml:"path"`
+	AuditLogPath string `yaml:"audit_log_path"`
+}
+
+// DataConfig selects where sale orders and invoices are read from. With a
+// cassette mode of record or replay, Odoo traffic is recorded to or replayed
+// from the cassette file.
+type DataConfig struct {
+	Source       string `yaml:"source"`
+	FixturesDir  string `yaml:"fixtures_dir"`
+	CassetteMode string `yaml:"cassette_mode"`
+	CassettePath string `yaml:"cassette_path"`
+}
+
+// TimeoutConfig holds the deadlines of operations calling Odoo. When one
+// passes, the request fails with 504 and its pending Odoo calls are abandoned.
+type TimeoutConfig struct {
+	Auth    time.Duration `yaml:"auth"`    // Login, two-factor verification, refresh and user info
+	Read    time.Duration `yaml:"read"`    // Sale order and invoice lists
+	Summary time.Duration `yaml:"summary"` // Daily and period summaries
+}
+
+// Longest returns the longest operation deadline
+func (t TimeoutConfig) Longest() time.Duration {
+	return max(t.Auth, t.Read, t.Summary)
+}
+
+// ResilienceConfig holds the retry and circuit breaker settings applied to
+// each tenant's Odoo instance
+type ResilienceConfig struct {
+	MaxAttempts      int           `yaml:"max_attempts"`      // Attempts of an idempotent call, including the first
+	BaseDelay        time.Duration `yaml:"base_delay"`        // Backoff ceiling before the first retry
+	MaxDelay         time.Duration `yaml:"max_delay"`         // Backoff ceiling of any retry
+	BreakerThreshold int           `yaml:"breaker_threshold"` // Consecutive failures opening the breaker
+	BreakerCooldown  time.Duration `yaml:"breaker_cooldown"`  // Time the breaker stays open
+}
+
+// CacheConfig selects where cached data is kept and how long. The memory
+// backend keeps it per instance; the redis backend shares it between
+// instances through a server speaking the Redis protocol.
+type CacheConfig struct {
+	Backend              string        `yaml:"backend"`                // memory, redis or off
+	Capacity             int           `yaml:"capacity"`               // Entries kept by the memory backend
+	RedisAddr            string        `yaml:"redis_addr"`             // host:port of the redis backend
+	RedisPassword        string        `yaml:"redis_password"`         // Sent with AUTH when not empty
+	RedisDB              int           `yaml:"redis_db"`               // Database selected after connecting
+	RedisPoolSize        int           `yaml:"redis_pool_size"`        // Idle connections kept
+	RedisTimeout         time.Duration `yaml:"redis_timeout"`          // Deadline of a command
+	MasterDataTTL        time.Duration `yaml:"master_data_ttl"`        // Lifetime of cached partners, users, journals and currencies
+	MasterDataRevalidate time.Duration `yaml:"master_data_revalidate"` // Interval between write_date checks
+
+	// ResponseTTLs maps routes to how long their responses are cached;
+	// zero disables caching of a route
+	ResponseTTLs map[string]time.Duration `yaml:"response_ttls"`
+}
+
+// ResponseCacheRoutes lists the routes whose responses may be cached
+var ResponseCacheRoutes = []string{
+	"/sales/daily-summary",
+	"/sales/period-summary",
+	"/invoices/daily-summary",
+	"/invoices/period-summary",
+}
+
+// UsesFixtures reports whether the API serves fixture data instead of Odoo
+func (c *Config) UsesFixtures() bool {
+	return c.Data.Source == "fixtures"
+}
+
+// Default returns the configuration used for settings missing from the file and environment
+func Default() *Config {
+	return &Config{
+		Odoo: OdooConfig{
+			SessionIdleTTL: 30 * time.Minute,
+		},
+		Server: ServerConfig{
+			ListenAddr:        ":3000",
+			CORSOrigins:       []string{"*"},
+			ShutdownTimeout:   30 * time.Second,
+			ReadinessCacheTTL: 10 * time.Second,
+			DrainPeriod:       5 * time.Second,
+		},
+		Pagination: PaginationConfig{
+			DefaultPageSize: 500,
+			MaxPageSize:     1000,
+		},
+		Auth: AuthConfig{
+			AccessTokenTTL:        15 * time.Minute,
+			RefreshTokenTTL:       7 * 24 * time.Hour,
+			LoginMaxAttempts:      5,
+			LoginMaxAttemptsPerIP: 20,
+		},
+		Store: StoreConfig{
+			Backend:      "memory",
+			Path:         "nerp_wrapper.db",
+			AuditLogPath: "audit.jsonl",
+		},
+		Data: DataConfig{
+			Source:       "odoo",
+			FixturesDir:  "fixtures",
+			CassetteMode: "off",
+			CassettePath: "cassettes/odoo.json",
+		},
+		Timeouts: TimeoutConfig{
+			Auth:    10 * time.Second,
+			Read:    20 * time.Second,
+			Summary: 30 * time.Second,
+		},
+		Resilience: ResilienceConfig{
+			MaxAttempts:      3,
+			BaseDelay:        200 * time.Millisecond,
+			MaxDelay:         2 * time.Second,
+			BreakerThreshold: 5,
+			BreakerCooldown:  30 * time.Second,
+		},
+		Cache: CacheConfig{
+			Backend:              "memory",
+			Capacity:             10000,
+			RedisPoolSize:        16,
+			RedisTimeout:         time.Second,
+			MasterDataTTL:        10 * time.Minute,
+			MasterDataRevalidate: 30 * time.Second,
+			ResponseTTLs: map[string]time.Duration{
+				"/sales/daily-summary":     30 * time.Second,
+				"/sales/period-summary":    30 * time.Second,
+				"/invoices/daily-summary":  30 * time.Second,
+				"/invoices/period-summary": 30 * time.Second,
+			},
+		},
+	}
+}
+
+// Load builds the configuration from the defaults, the YAML file at path (if
+// not empty) and the environment, in increasing order of precedence, and
+// validates the result. Every variable may instead be given as NAME_FILE
+// pointing to a file holding the value, as with Docker secrets.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file: %v", err)
+		}
+		if err := yaml.Unmarshal(data, cfg); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %v", path, err)
+		}
+	}
+
+	if err := cfg.applyEnv(); err != nil {
+		return nil, err
+	}
+	cfg.normalizeTenants()
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// envBinding maps an environment variable to a configuration field
+type envBinding struct {
+	name string
+	set  func(value string) error
+}
+
+func (c *Config) envBindings() []envBinding {
+	bindings := []envBinding{
+		{"DEFAULT_TENANT", setString(&c.DefaultTenant)},
+		{"ODOO_URL", setString(&c.Odoo.URL)},
+		{"ODOO_DATABASE", setString(&c.Odoo.Database)},
+		{"ODOO_ADMIN_USERNAME", setString(&c.Odoo.AdminUsername)},
+		{"ODOO_ADMIN_PASSWORD", setString(&c.Odoo.AdminPassword)},
+		{"ODOO_SESSION_IDLE_TTL", setDuration(&c.Odoo.SessionIdleTTL)},
+		{"LISTEN_ADDR", setString(&c.Server.ListenAddr)},
+		{"CORS_ORIGINS", setList(&c.Server.CORSOrigins)},
+		{"SHUTDOWN_TIMEOUT", setDuration(&c.Server.ShutdownTimeout)},
+		{"READINESS_CACHE_TTL", setDuration(&c.Server.ReadinessCacheTTL)},
+		{"DRAIN_PERIOD", setDuration(&c.Server.DrainPeriod)},
+		{"PROXY_HEADER", setString(&c.Server.ProxyHeader)},
+		{"TRUSTED_PROXIES", setList(&c.Server.TrustedProxies)},
+		{"DEFAULT_PAGE_SIZE", setInt(&c.Pagination.DefaultPageSize)},
+		{"MAX_PAGE_SIZE", setInt(&c.Pagination.MaxPageSize)},
+		{"JWT_SECRET", setString(&c.Auth.JWTSecret)},
+		{"ACCESS_TOKEN_TTL", setDuration(&c.Auth.AccessTokenTTL)},
+		{"REFRESH_TOKEN_TTL", setDuration(&c.Auth.RefreshTokenTTL)},
+		{"ACCESS_POLICY_FILE", setString(&c.Auth.AccessPolicyFile)},
+		{"LOGIN_MAX_ATTEMPTS", setInt(&c.Auth.LoginMaxAttempts)},
+		{"LOGIN_MAX_ATTEMPTS_PER_IP", setInt(&c.Auth.LoginMaxAttemptsPerIP)},
+		{"STORE", setString(&c.Store.Backend)},
+		{"STORE_PATH", setString(&c.Store.Path)},
+		{"AUDIT_LOG_PATH", setString(&c.Store.AuditLogPath)},
+		{"DATA_SOURCE", setString(&c.Data.Source)},
+		{"FIXTURES_DIR", setString(&c.Data.FixturesDir)},
+		{"CASSETTE_MODE", setString(&c.Data.CassetteMode)},
+		{"CASSETTE_PATH", setString(&c.Data.CassettePath)},
+		{"ODOO_AUTH_TIMEOUT", setDuration(&c.Timeouts.Auth)},
+		{"ODOO_READ_TIMEOUT", setDuration(&c.Timeouts.Read)},
+		{"ODOO_SUMMARY_TIMEOUT", setDuration(&c.Timeouts.Summary)},
+		{"ODOO_RETRY_MAX_ATTEMPTS", setInt(&c.Resilience.MaxAttempts)},
+		{"ODOO_RETRY_BASE_DELAY", setDuration(&c.Resilience.BaseDelay)},
+		{"ODOO_RETRY_MAX_DELAY", setDuration(&c.Resilience.MaxDelay)},
+		{"ODOO_BREAKER_THRESHOLD", setInt(&c.Resilience.BreakerThreshold)},
+		{"ODOO_BREAKER_COOLDOWN", setDuration(&c.Resilience.BreakerCooldown)},
+		{"CACHE_BACKEND", setString(&c.Cache.Backend)},
+		{"CACHE_CAPACITY", setInt(&c.Cache.Capacity)},
+		{"CACHE_REDIS_ADDR", setString(&c.Cache.RedisAddr)},
+		{"CACHE_REDIS_PASSWORD", setString(&c.Cache.RedisPassword)},
+		{"CACHE_REDIS_DB", setInt(&c.Cache.RedisDB)},
+		{"CACHE_REDIS_POOL_SIZE", setInt(&c.Cache.RedisPoolSize)},
+		{"CACHE_REDIS_TIMEOUT", setDuration(&c.Cache.RedisTimeout)},
+		{"MASTER_DATA_TTL", setDuration(&c.Cache.MasterDataTTL)},
+		{"MASTER_DATA_REVALIDATE_INTERVAL", setDuration(&c.Cache.MasterDataRevalidate)},
+		{"CACHE_RESPONSE_TTLS", setDurationMap(&c.Cache.ResponseTTLs)},
+	}
+
+	// Tenant settings are overridden with TENANT_<ID>_ODOO_..., e.g.
+	// TENANT_ACME_ODOO_ADMIN_PASSWORD_FILE for a tenant with ID "acme"
+	for i := range c.Tenants {
+		tenant := &c.Tenants[i]
+		prefix := "TENANT_" + strings.ToUpper(strings.ReplaceAll(tenant.ID, "-", "_")) + "_ODOO_"
+		bindings = append(bindings,
+			envBinding{prefix + "URL", setString(&tenant.Odoo.URL)},
+			envBinding{prefix + "DATABASE", setString(&tenant.Odoo.Database)},
+			envBinding{prefix + "ADMIN_USERNAME", setString(&tenant.Odoo.AdminUsername)},
+			envBinding{prefix + "ADMIN_PASSWORD", setString(&tenant.Odoo.AdminPassword)},
+		)
+	}
+	return bindings
+}
+
+// defaultTenantID is the ID of the tenant built from the top-level odoo
+// section when no tenants are listed
+const defaultTenantID = "default"
+
+// normalizeTenants turns a single-database configuration into one tenant and
+// fills in defaults of the listed tenants
+func (c *Config) normalizeTenants() {
+	if len(c.Tenants) == 0 {
+		c.Tenants = []TenantConfig{{ID: defaultTenantID, Odoo: c.Odoo}}
+	}
+	for i := range c.Tenants {
+		tenant := &c.Tenants[i]
+		if tenant.Name == "" {
+			tenant.Name = tenant.ID
+		}
+		if tenant.Subdomain == "" {
+			tenant.Subdomain = tenant.ID
+		}
+		if tenant.Odoo.SessionIdleTTL == 0 {
+			tenant.Odoo.SessionIdleTTL = c.Odoo.SessionIdleTTL
+		}
+	}
+	if c.DefaultTenant == "" {
+		c.DefaultTenant = c.Tenants[0].ID
+	}
+}
+
+func (c *Config) applyEnv() error {
+	for _, binding := range c.envBindings() {
+		value, ok, err := lookupEnv(binding.name)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			continue
+		}
+		if err := binding.set(value); err != nil {
+			return fmt.Errorf("invalid %s: %v", binding.name, err)
+		}
+	}
+	return nil
+}
+
+// lookupEnv reads NAME, or the file named by NAME_FILE. Setting both is an error.
+func lookupEnv(name string) (string, bool, error) {
+	value, ok := os.LookupEnv(name)
+	file, fileOK := os.LookupEnv(name + "_FILE")
+	if !fileOK {
+		return value, ok, nil
+	}
+	if ok {
+		return "", false, fmt.Errorf("both %s and %s_FILE are set", name, name)
+	}
+
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return "", false, fmt.Errorf("failed to read %s_FILE: %v", name, err)
+	}
+	return strings.TrimRight(string(data), "\r\n"), true, nil
+}
+
+// Validate checks that required settings are present and values are consistent
+func (c *Config) Validate() error {
+	var errs []error
+	require := func(value, key, env string) {
+		if strings.TrimSpace(value) == "" {
+			errs = append(errs, fmt.Errorf("%s is required (set it in the config file or %s)", key, env))
+		}
+	}
+	require(c.Server.ListenAddr, "server.listen_addr", "LISTEN_ADDR")
+
+	if c.Server.ShutdownTimeout <= 0 {
+		errs = append(errs, fmt.Errorf("server.shutdown_timeout must be positive"))
+	}
+	if c.Server.ReadinessCacheTTL < 0 {
+		errs = append(errs, fmt.Errorf("server.readiness_cache_ttl must not be negative"))
+	}
+	if c.Server.DrainPeriod < 0 {
+		errs = append(errs, fmt.Errorf("server.drain_period must not be negative"))
+	}
+	if c.Server.ProxyHeader != "" && len(c.Server.TrustedProxies) == 0 {
+		errs = append(errs, fmt.Errorf("server.proxy_header needs server.trusted_proxies (set TRUSTED_PROXIES)"))
+	}
+	for _, proxy := range c.Server.TrustedProxies {
+		if _, _, err := net.ParseCIDR(proxy); err != nil && net.ParseIP(proxy) == nil {
+			errs = append(errs, fmt.Errorf("server.trusted_proxies: %q is neither an IP nor a CIDR range", proxy))
+		}
+	}
+
+	seen := make(map[string]bool)
+	for i, tenant := range c.Tenants {
+		// A single tenant built from the top-level odoo section reports its keys
+		key, env := fmt.Sprintf("tenants[%d].odoo", i), "TENANT_"+strings.ToUpper(strings.ReplaceAll(tenant.ID, "-", "_"))+"_ODOO"
+		if tenant.ID == defaultTenantID && len(c.Tenants) == 1 {
+			key, env = "odoo", "ODOO"
+		}
+		// Tenants served from fixtures never connect to Odoo
+		if !c.UsesFixtures() {
+			require(tenant.Odoo.URL, key+".url", env+"_URL")
+			require(tenant.Odoo.Database, key+".database", env+"_DATABASE")
+			require(tenant.Odoo.AdminUsername, key+".admin_username", env+"_ADMIN_USERNAME")
+			require(tenant.Odoo.AdminPassword, key+".admin_password", env+"_ADMIN_PASSWORD")
+		}
+
+		if !validTenantID(tenant.ID) {
+			errs = append(errs, fmt.Errorf("tenants[%d].id %q must be lowercase letters, digits and dashes", i, tenant.ID))
+		}
+		if seen[tenant.ID] {
+			errs = append(errs, fmt.Errorf("tenant %q is listed twice", tenant.ID))
+		}
+		seen[tenant.ID] = true
+		if tenant.Odoo.URL != "" && !strings.HasPrefix(tenant.Odoo.URL, "http://") && !strings.HasPrefix(tenant.Odoo.URL, "https://") {
+			errs = append(errs, fmt.Errorf("%s.url must start with http:// or https://", key))
+		}
+		if tenant.Odoo.SessionIdleTTL <= 0 {
+			errs = append(errs, fmt.Errorf("%s.session_idle_ttl must be positive", key))
+		}
+	}
+	if !seen[c.DefaultTenant] {
+		errs = append(errs, fmt.Errorf("default_tenant %q is not a configured tenant", c.DefaultTenant))
+	}
+	if c.Pagination.DefaultPageSize <= 0 || c.Pagination.MaxPageSize <= 0 {
+		errs = append(errs, fmt.Errorf("pagination page sizes must be positive"))
+	} else if c.Pagination.DefaultPageSize > c.Pagination.MaxPageSize {
+		errs = append(errs, fmt.Errorf("pagination.default_page_size (%d) exceeds pagination.max_page_size (%d)",
+			c.Pagination.DefaultPageSize, c.Pagination.MaxPageSize))
+	}
+	if c.Auth.AccessTokenTTL <= 0 || c.Auth.RefreshTokenTTL <= 0 {
+		errs = append(errs, fmt.Errorf("auth token TTLs must be positive"))
+	}
+	if c.Timeouts.Auth <= 0 || c.Timeouts.Read <= 0 || c.Timeouts.Summary <= 0 {
+		errs = append(errs, fmt.Errorf("timeouts must be positive"))
+	}
+	if c.Resilience.MaxAttempts <= 0 || c.Resilience.BreakerThreshold <= 0 {
+		errs = append(errs, fmt.Errorf("resilience.max_attempts and resilience.breaker_threshold must be positive"))
+	}
+	if c.Resilience.BaseDelay <= 0 || c.Resilience.MaxDelay <= 0 || c.Resilience.BreakerCooldown <= 0 {
+		errs = append(errs, fmt.Errorf("resilience delays and cooldown must be positive"))
+	} else if c.Resilience.BaseDelay > c.Resilience.MaxDelay {
+		errs = append(errs, fmt.Errorf("resilience.base_delay (%s) exceeds resilience.max_delay (%s)",
+			c.Resilience.BaseDelay, c.Resilience.MaxDelay))
+	}
+	if c.Auth.LoginMaxAttempts <= 0 || c.Auth.LoginMaxAttemptsPerIP <= 0 {
+		errs = append(errs, fmt.Errorf("auth.login_max_attempts and auth.login_max_attempts_per_ip must be positive"))
+	}
+	switch c.Data.Source {
+	case "odoo":
+	case "fixtures":
+		require(c.Data.FixturesDir, "data.fixtures_dir", "FIXTURES_DIR")
+	default:
+		errs = append(errs, fmt.Errorf("data.source must be odoo or fixtures, got %q", c.Data.Source))
+	}
+	switch c.Data.CassetteMode {
+	case "off":
+	case "record", "replay":
+		require(c.Data.CassettePath, "data.cassette_path", "CASSETTE_PATH")
+		if c.UsesFixtures() {
+			errs = append(errs, fmt.Errorf("data.cassette_mode %s needs data.source odoo", c.Data.CassetteMode))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("data.cassette_mode must be off, record or replay, got %q", c.Data.CassetteMode))
+	}
+	switch c.Cache.Backend {
+	case "memory", "off":
+	case "redis":
+		require(c.Cache.RedisAddr, "cache.redis_addr", "CACHE_REDIS_ADDR")
+		if c.Cache.RedisDB < 0 || c.Cache.RedisPoolSize <= 0 || c.Cache.RedisTimeout <= 0 {
+			errs = append(errs, fmt.Errorf("cache.redis_db must not be negative, cache.redis_pool_size and cache.redis_timeout must be positive"))
+		}
+	default:
+		errs = append(errs, fmt.Errorf("cache.backend must be memory, redis or off, got %q", c.Cache.Backend))
+	}
+	if c.Cache.Capacity <= 0 {
+		errs = append(errs, fmt.Errorf("cache.capacity must be positive"))
+	}
+	if c.Cache.MasterDataTTL <= 0 || c.Cache.MasterDataRevalidate <= 0 {
+		errs = append(errs, fmt.Errorf("cache.master_data_ttl and cache.master_data_revalidate must be positive"))
+	} else if c.Cache.MasterDataRevalidate > c.Cache.MasterDataTTL {
+		errs = append(errs, fmt.Errorf("cache.master_data_revalidate (%s) exceeds cache.master_data_ttl (%s)",
+			c.Cache.MasterDataRevalidate, c.Cache.MasterDataTTL))
+	}
+	for route, ttl := range c.Cache.ResponseTTLs {
+		if !slices.Contains(ResponseCacheRoutes, route) {
+			errs = append(errs, fmt.Errorf("cache.response_ttls: responses of %s cannot be cached, use one of %s",
+				route, strings.Join(ResponseCacheRoutes, ", ")))
+		} else if ttl < 0 {
+			errs = append(errs, fmt.Errorf("cache.response_ttls: TTL of %s must not be negative", route))
+		}
+	}
+	switch c.Store.Backend {
+	case "memory", "sqlite":
+	default:
+		errs = append(errs, fmt.Errorf("store.backend must be memory or sqlite, got %q", c.Store.Backend))
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+func validTenantID(id string) bool {
+	if id == "" {
+		return false
+	}
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-') {
+			return false
+		}
+	}
+	return true
+}
+
+func setString(field *string) func(string) error {
+	return func(value string) error {
+		*field = value
+		return nil
+	}
+}
+
+func setInt(field *int) func(string) error {
+	return func(value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		*field = n
+		return nil
+	}
+}
+
+func setDuration(field *time.Duration) func(string) error {
+	return func(value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		*field = d
+		return nil
+	}
+}
+
+// setList parses a comma separated list
+func setList(field *[]string) func(string) error {
+	return func(value string) error {
+		items := []string{}
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		*field = items
+		return nil
+	}
+}
+
+// setDurationMap parses a comma separated list of key=duration pairs,
+// overriding the listed keys only
+func setDurationMap(field *map[string]time.Duration) func(string) error {
+	return func(value string) error {
+		if *field == nil {
+			*field = make(map[string]time.Duration)
+		}
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item == "" {
+				continue
+			}
+			key, duration, ok := strings.Cut(item, "=")
+			if !ok {
+				return fmt.Errorf("%q is not key=duration", item)
+			}
+			d, err := time.ParseDuration(strings.TrimSpace(duration))
+			if err != nil {
+				return err
+			}
+			(*field)[strings.TrimSpace(key)] = d
+		}
+		return nil
+	}
+}
@@ -0,0 +1,91 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// setOdooEnv sets the settings required to start against Odoo
+func setOdooEnv(t *testing.T) {
+	t.Setenv("ODOO_URL", "https://erp.example.com")
+	t.Setenv("ODOO_DATABASE", "odoo")
+	t.Setenv("ODOO_ADMIN_USERNAME", "admin")
+	t.Setenv("ODOO_ADMIN_PASSWORD", "admin")
+}
+
+func TestLoadAppliesFileThenEnvironment(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	file := "server:\n  listen_addr: \":4000\"\nauth:\n  login_max_attempts: 7\n  login_max_attempts_per_ip: 50\npagination:\n  max_page_size: 2000\n"
+	if err := os.WriteFile(path, []byte(file), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	setOdooEnv(t)
+	t.Setenv("LOGIN_MAX_ATTEMPTS_PER_IP", "40")
+	t.Setenv("PROXY_HEADER", "X-Real-IP")
+	t.Setenv("TRUSTED_PROXIES", "10.0.0.0/8, 192.0.2.1")
+
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Server.ListenAddr != ":4000" || cfg.Auth.LoginMaxAttempts != 7 || cfg.Pagination.MaxPageSize != 2000 {
+		t.Errorf("file settings were not applied: %+v %+v %+v", cfg.Server, cfg.Auth, cfg.Pagination)
+	}
+	if cfg.Auth.LoginMaxAttemptsPerIP != 40 {
+		t.Errorf("LOGIN_MAX_ATTEMPTS_PER_IP gave %d, want the environment to win with 40", cfg.Auth.LoginMaxAttemptsPerIP)
+	}
+	if cfg.Server.ProxyHeader != "X-Real-IP" || !slices.Equal(cfg.Server.TrustedProxies, []string{"10.0.0.0/8", "192.0.2.1"}) {
+		t.Errorf("proxy settings are %q %q", cfg.Server.ProxyHeader, cfg.Server.TrustedProxies)
+	}
+	if len(cfg.Tenants) != 1 || cfg.Tenants[0].ID != "default" || cfg.Tenants[0].Odoo.URL != "https://erp.example.com" {
+		t.Errorf("single database was not turned into the default tenant: %+v", cfg.Tenants)
+	}
+}
+
+func TestLoadReadsFileVariables(t *testing.T) {
+	setOdooEnv(t)
+	os.Unsetenv("ODOO_ADMIN_PASSWORD")
+	secret := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(secret, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("ODOO_ADMIN_PASSWORD_FILE", secret)
+
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatalf("Load failed: %v", err)
+	}
+	if cfg.Tenants[0].Odoo.AdminPassword != "from-file" {
+		t.Errorf("admin password is %q, want the file content", cfg.Tenants[0].Odoo.AdminPassword)
+	}
+}
+
+func TestValidateRejectsInvalidSettings(t *testing.T) {
+	tests := []struct {
+		name   string
+		env    map[string]string
+		reason string
+	}{
+		{"missing odoo url", map[string]string{"ODOO_URL": ""}, "odoo.url is required"},
+		{"default page size above maximum", map[string]string{"DEFAULT_PAGE_SIZE": "600", "MAX_PAGE_SIZE": "500"}, "exceeds pagination.max_page_size"},
+		{"per-IP attempts", map[string]string{"LOGIN_MAX_ATTEMPTS_PER_IP": "0"}, "login_max_attempts_per_ip must be positive"},
+		{"proxy header without trusted proxies", map[string]string{"PROXY_HEADER": "X-Real-IP"}, "needs server.trusted_proxies"},
+		{"invalid trusted proxy", map[string]string{"PROXY_HEADER": "X-Real-IP", "TRUSTED_PROXIES": "proxy.local"}, "neither an IP nor a CIDR range"},
+		{"unknown store", map[string]string{"STORE": "postgres"}, "store"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setOdooEnv(t)
+			for name, value := range tt.env {
+				t.Setenv(name, value)
+			}
+			_, err := Load("")
+			if err == nil || !strings.Contains(err.Error(), tt.reason) {
+				t.Errorf("Load returned %v, want an error mentioning %q", err, tt.reason)
+			}
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package jsonl
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"nerp_wrapper/domain/entity"
+	"os"
+	"sync"
+)
+
+// JSONLAuditRepository implements AuditRepository interface as an append-only
+// file with one JSON encoded event per line
+type JSONLAuditRepository struct {
+	mu   sync.Mutex
+	path string
+	file *os.File
+}
+
+// NewJSONLAuditRepository creates a new instance of JSONLAuditRepository,
+// creating the file at path if needed
+func NewJSONLAuditRepository(path string) (*JSONLAuditRepository, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %v", err)
+	}
+	return &JSONLAuditRepository{path: path, file: file}, nil
+}
+
+// AppendAuditEvent writes an event as a new line at the end of the file
+func (r *JSONLAuditRepository) AppendAuditEvent(event *entity.AuditEvent) error {
+	line, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit event: %v", err)
+	}
+	line = append(line, '\n')
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, err := r.file.Write(line); err != nil {
+		return fmt.Errorf("failed to write audit event: %v", err)
+	}
+	return nil
+}
+
+// ListAuditEvents reads the file backwards for the events matching the
+// filter, newest first, and stops once filter.Limit events are found. It
+// reads through a handle of its own, so appends go on while it runs.
+func (r *JSONLAuditRepository) ListAuditEvents(filter entity.AuditFilter) ([]*entity.AuditEvent, error) {
+	// Lines are written whole under the mutex, so the current size ends
+	// with a complete event
+	r.mu.Lock()
+	info, err := r.file.Stat()
+	r.mu.Unlock()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %v", err)
+	}
+
+	file, err := os.Open(r.path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %v", err)
+	}
+	defer file.Close()
+
+	// Events are appended in time order, so the last lines are the latest
+	events := []*entity.AuditEvent{}
+	err = readLinesBackward(file, info.Size(), func(line []byte) (bool, error) {
+		var event entity.AuditEvent
+		if err := json.Unmarshal(line, &event); err != nil {
+			return false, fmt.Errorf("failed to decode audit event: %v", err)
+		}
+		if filter.Matches(&event) {
+			events = append(events, &event)
+		}
+		return filter.Limit <= 0 || len(events) < filter.Limit, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return events, nil
+}
+
+// Bounds of the backward reads: the block read at a time and the longest line
+const (
+	readBlockSize = 64 * 1024
+	maxLineSize   = 1024 * 1024
+)
+
+// readLinesBackward calls yield with the non-empty lines of the first size
+// bytes of file, last line first, until yield returns false or an error
+func readLinesBackward(file *os.File, size int64, yield func(line []byte) (bool, error)) error {
+	var partial []byte
+	for end := size; end > 0; {
+		start := max(end-readBlockSize, 0)
+		block := make([]byte, end-start, end-start+int64(len(partial)))
+		if _, err := file.ReadAt(block, start); err != nil {
+			return fmt.Errorf("failed to read audit log: %v", err)
+		}
+		end = start
+
+		// The line cut by the previous block's start continues this block
+		data := append(block, partial...)
+		for {
+			i := bytes.LastIndexByte(data, '\n')
+			if i < 0 {
+				break
+			}
+			line := data[i+1:]
+			data = data[:i]
+			if len(line) == 0 {
+				continue
+			}
+			if more, err := yield(line); err != nil || !more {
+				return err
+			}
+		}
+		if len(data) > maxLineSize {
+			return fmt.Errorf("failed to read audit log: line longer than %d bytes", maxLineSize)
+		}
+		partial = data
+	}
+	if len(partial) > 0 {
+		_, err := yield(partial)
+		return err
+	}
+	return nil
+}
+
+// Close closes the underlying file
+func (r *JSONLAuditRepository) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.file.Close()
+}
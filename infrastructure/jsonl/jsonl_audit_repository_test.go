@@ -0,0 +1,136 @@
+package jsonl
+
+import (
+	"fmt"
+	"nerp_wrapper/domain/entity"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func openAuditLog(t *testing.T, path string) *JSONLAuditRepository {
+	t.Helper()
+	repo, err := NewJSONLAuditRepository(path)
+	if err != nil {
+		t.Fatalf("NewJSONLAuditRepository failed: %v", err)
+	}
+	t.Cleanup(func() { repo.Close() })
+	return repo
+}
+
+// auditEvent is the i-th of a series of data reads alternating between users
+// 2 and 6, padded so that a few hundred span several read blocks
+func auditEvent(i int) *entity.AuditEvent {
+	return &entity.AuditEvent{
+		ID:       fmt.Sprint(i),
+		Time:     time.Date(2026, 10, 1, 0, 0, i, 0, time.UTC),
+		Action:   entity.AuditActionDataRead,
+		TenantID: "default",
+		UserID:   int64(2 + 4*(i%2)),
+		Route:    "/sales",
+		Detail:   strings.Repeat("x", 100),
+	}
+}
+
+func appendEvents(t *testing.T, repo *JSONLAuditRepository, first, count int) {
+	t.Helper()
+	for i := first; i < first+count; i++ {
+		if err := repo.AppendAuditEvent(auditEvent(i)); err != nil {
+			t.Fatalf("AppendAuditEvent failed: %v", err)
+		}
+	}
+}
+
+func ids(events []*entity.AuditEvent) string {
+	parts := make([]string, len(events))
+	for i, event := range events {
+		parts[i] = event.ID
+	}
+	return strings.Join(parts, ",")
+}
+
+func TestListAuditEventsNewestFirst(t *testing.T) {
+	repo := openAuditLog(t, filepath.Join(t.TempDir(), "audit.jsonl"))
+	appendEvents(t, repo, 0, 2000)
+
+	events, err := repo.ListAuditEvents(entity.AuditFilter{Limit: 3})
+	if err != nil {
+		t.Fatalf("ListAuditEvents failed: %v", err)
+	}
+	if got := ids(events); got != "1999,1998,1997" {
+		t.Errorf("latest events are %s, want 1999,1998,1997", got)
+	}
+
+	events, err = repo.ListAuditEvents(entity.AuditFilter{UserID: 6})
+	if err != nil {
+		t.Fatalf("ListAuditEvents failed: %v", err)
+	}
+	if len(events) != 1000 || events[0].ID != "1999" || events[999].ID != "1" {
+		t.Errorf("events of user 6 are %d from %s to %s, want 1000 from 1999 to 1", len(events), events[0].ID, events[len(events)-1].ID)
+	}
+	for i := 1; i < len(events); i++ {
+		if events[i].Time.After(events[i-1].Time) {
+			t.Fatalf("event %s is listed after the older event %s", events[i].ID, events[i-1].ID)
+		}
+	}
+
+	until := time.Date(2026, 10, 1, 0, 0, 10, 0, time.UTC)
+	events, err = repo.ListAuditEvents(entity.AuditFilter{UserID: 2, To: &until})
+	if err != nil {
+		t.Fatalf("ListAuditEvents failed: %v", err)
+	}
+	if got := ids(events); got != "10,8,6,4,2,0" {
+		t.Errorf("early events of user 2 are %s, want 10,8,6,4,2,0", got)
+	}
+}
+
+func TestListAuditEventsStopsAtTheLimit(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "audit.jsonl")
+	// An undecodable line at the start is only reached by reading the whole file
+	if err := os.WriteFile(path, []byte("not json\n"), 0o600); err != nil {
+		t.Fatalf("failed to write audit log: %v", err)
+	}
+	repo := openAuditLog(t, path)
+	appendEvents(t, repo, 0, 1000)
+
+	events, err := repo.ListAuditEvents(entity.AuditFilter{UserID: 2, Limit: 10})
+	if err != nil {
+		t.Fatalf("ListAuditEvents with a limit read too far: %v", err)
+	}
+	if len(events) != 10 || events[0].ID != "998" {
+		t.Errorf("ListAuditEvents returned %s", ids(events))
+	}
+	if _, err := repo.ListAuditEvents(entity.AuditFilter{}); err == nil {
+		t.Error("ListAuditEvents without a limit did not reach the undecodable line")
+	}
+}
+
+func TestListAuditEventsWhileAppending(t *testing.T) {
+	repo := openAuditLog(t, filepath.Join(t.TempDir(), "audit.jsonl"))
+	appendEvents(t, repo, 0, 100)
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 100; i < 1100; i++ {
+			if err := repo.AppendAuditEvent(auditEvent(i)); err != nil {
+				t.Errorf("AppendAuditEvent failed: %v", err)
+				return
+			}
+		}
+	}()
+	for range 50 {
+		events, err := repo.ListAuditEvents(entity.AuditFilter{})
+		if err != nil {
+			t.Fatalf("ListAuditEvents failed: %v", err)
+		}
+		if len(events) < 100 || events[len(events)-1].ID != "0" {
+			t.Fatalf("ListAuditEvents returned %d events ending with %s", len(events), events[len(events)-1].ID)
+		}
+	}
+	wg.Wait()
+}
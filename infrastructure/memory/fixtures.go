@@ -0,0 +1,117 @@
+package memory
+
+import (
+	"encoding/json"
+	"fmt"
+	"nerp_wrapper/domain/entity"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Fixtures holds the records served by the in-memory repositories when the
+// API runs without Odoo
+type Fixtures struct {
+	Users      []FixtureUser
+	SaleOrders []*entity.SaleOrder
+	Invoices   []*entity.Invoice
+}
+
+// FixtureUser is a user who can log in against the in-memory auth repository
+type FixtureUser struct {
+	ID               int64    `json:"id"`
+	Login            string   `json:"login"`
+	Password         string   `json:"password"`
+	Email            string   `json:"email"`
+	Groups           []string `json:"groups"`
+	TwoFactorEnabled bool     `json:"two_factor_enabled"`
+}
+
+// LoadFixtures reads users.json, sale_orders.json and invoices.json from dir.
+// Sale orders and invoices use the same field names as the API responses.
+func LoadFixtures(dir string) (*Fixtures, error) {
+	fixtures := &Fixtures{}
+	files := []struct {
+		name   string
+		target interface{}
+	}{
+		{"users.json", &fixtures.Users},
+		{"sale_orders.json", &fixtures.SaleOrders},
+		{"invoices.json", &fixtures.Invoices},
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(filepath.Join(dir, file.name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read fixture: %v", err)
+		}
+		if err := json.Unmarshal(data, file.target); err != nil {
+			return nil, fmt.Errorf("failed to parse fixture %s: %v", file.name, err)
+		}
+	}
+	return fixtures, nil
+}
+
+// paginate clamps page and pageSize like the Odoo repositories do and returns
+// the slice bounds of the page within total records and the page count
+func paginate(total, page, pageSize int) (int, int, int, int, int) {
+	if page < 1 {
+		page = 1
+	}
+	// The caller caps the page size at the configured maximum
+	if pageSize < 1 {
+		pageSize = 1
+	}
+	totalPages := (total + pageSize - 1) / pageSize
+
+	start := (page - 1) * pageSize
+	if start > total {
+		start = total
+	}
+	end := start + pageSize
+	if end > total {
+		end = total
+	}
+	return start, end, page, pageSize, totalPages
+}
+
+// containsID reports whether id is in ids, treating an empty list as matching everything
+func containsID(ids []int64, id int64) bool {
+	if len(ids) == 0 {
+		return true
+	}
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
+
+// inDateRange reports whether t falls on a calendar day between start and end inclusive
+func inDateRange(t, start, end time.Time) bool {
+	day := t.Format("2006-01-02")
+	return day >= start.Format("2006-01-02") && day <= end.Format("2006-01-02")
+}
+
+// inDayRange reports whether t falls on the days from and to, both included
+// and either optional, comparing UTC days as Odoo does
+func inDayRange(t time.Time, from, to *time.Time) bool {
+	day := t.UTC().Format("2006-01-02")
+	if from != nil && day < from.Format("2006-01-02") {
+		return false
+	}
+	return to == nil || day <= to.Format("2006-01-02")
+}
+
+// containsFold reports whether any of values contains term, ignoring case,
+// as Odoo's ilike operator does
+func containsFold(term string, values ...string) bool {
+	term = strings.ToLower(term)
+	for _, value := range values {
+		if strings.Contains(strings.ToLower(value), term) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,26 @@
+package memory
+
+import "testing"
+
+func TestPaginate(t *testing.T) {
+	tests := []struct {
+		name                 string
+		total, page, size    int
+		start, end, lastPage int
+	}{
+		{"first page", 25, 1, 10, 0, 10, 3},
+		{"last partial page", 25, 3, 10, 20, 25, 3},
+		{"past the end", 25, 5, 10, 25, 25, 3},
+		{"page below one", 25, 0, 10, 0, 10, 3},
+		{"page size above 500", 1200, 2, 1000, 1000, 1200, 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			start, end, _, pageSize, totalPages := paginate(tt.total, tt.page, tt.size)
+			if start != tt.start || end != tt.end || totalPages != tt.lastPage || pageSize != tt.size {
+				t.Errorf("paginate(%d, %d, %d) = [%d:%d] of %d pages sized %d, want [%d:%d] of %d sized %d",
+					tt.total, tt.page, tt.size, start, end, totalPages, pageSize, tt.start, tt.end, tt.lastPage, tt.size)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package memory
+
+import (
+	"nerp_wrapper/domain/entity"
+	"sort"
+	"sync"
+	"time"
+)
+
+// MemoryAPIKeyRepository implements APIKeyRepository interface in process memory
+type MemoryAPIKeyRepository struct {
+	mu   sync.Mutex
+	keys map[string]*entity.APIKey
+}
+
+// NewMemoryAPIKeyRepository creates a new instance of MemoryAPIKeyRepository
+func NewMemoryAPIKeyRepository() *MemoryAPIKeyRepository {
+	return &MemoryAPIKeyRepository{keys: make(map[string]*entity.APIKey)}
+}
+
+// CreateAPIKey stores a new API key
+func (r *MemoryAPIKeyRepository) CreateAPIKey(key *entity.APIKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := *key
+	r.keys[key.ID] = &stored
+	return nil
+}
+
+// ListAPIKeys retrieves every API key of a tenant, newest first
+func (r *MemoryAPIKeyRepository) ListAPIKeys(tenantID string) ([]*entity.APIKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	keys := make([]*entity.APIKey, 0, len(r.keys))
+	for _, stored := range r.keys {
+		if stored.TenantID != tenantID {
+			continue
+		}
+		key := *stored
+		keys = append(keys, &key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].CreatedAt.After(keys[j].CreatedAt)
+	})
+	return keys, nil
+}
+
+// GetAPIKeyByHash retrieves an API key by the hash of its value
+func (r *MemoryAPIKeyRepository) GetAPIKeyByHash(keyHash string) (*entity.APIKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, stored := range r.keys {
+		if stored.KeyHash == keyHash {
+			key := *stored
+			return &key, nil
+		}
+	}
+	return nil, nil
+}
+
+// RevokeAPIKey marks an API key of a tenant as revoked
+func (r *MemoryAPIKeyRepository) RevokeAPIKey(tenantID, id string, revokedAt time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, exists := r.keys[id]
+	if !exists || stored.TenantID != tenantID {
+		return false, nil
+	}
+	if stored.RevokedAt == nil {
+		stored.RevokedAt = &revokedAt
+	}
+	return true, nil
+}
+
+// TouchAPIKey records the time an API key was last used
+func (r *MemoryAPIKeyRepository) TouchAPIKey(id string, usedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if stored, exists := r.keys[id]; exists {
+		stored.LastUsedAt = &usedAt
+	}
+	return nil
+}
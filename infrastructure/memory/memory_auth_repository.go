@@ -0,0 +1,92 @@
+package memory
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"nerp_wrapper/domain/entity"
+	"nerp_wrapper/domain/repository"
+	"sync"
+	"time"
+)
+
+// MemoryAuthRepository implements AuthRepository interface with fixture users
+type MemoryAuthRepository struct {
+	mu        sync.Mutex
+	users     map[int64]FixtureUser
+	byLogin   map[string]int64
+	lastLogin map[int64]time.Time
+}
+
+// NewMemoryAuthRepository creates a new instance of MemoryAuthRepository
+func NewMemoryAuthRepository(users []FixtureUser) *MemoryAuthRepository {
+	r := &MemoryAuthRepository{
+		users:     make(map[int64]FixtureUser, len(users)),
+		byLogin:   make(map[string]int64, len(users)),
+		lastLogin: make(map[int64]time.Time),
+	}
+	for _, user := range users {
+		r.users[user.ID] = user
+		r.byLogin[user.Login] = user.ID
+	}
+	return r
+}
+
+// Login authenticates a fixture user with its password
+func (r *MemoryAuthRepository) Login(ctx context.Context, username, secret string) (*entity.User, error) {
+	r.mu.Lock()
+	id, exists := r.byLogin[username]
+	user := r.users[id]
+	r.mu.Unlock()
+
+	if !exists || secret == "" || subtle.ConstantTimeCompare([]byte(user.Password), []byte(secret)) != 1 {
+		return nil, repository.ErrInvalidCredentials
+	}
+
+	r.mu.Lock()
+	r.lastLogin[id] = time.Now()
+	r.mu.Unlock()
+	return r.GetUserInfo(ctx, id)
+}
+
+// Logout handles user logout
+func (r *MemoryAuthRepository) Logout(ctx context.Context, userID int64) error {
+	return nil
+}
+
+// HasSession reports that fixture users always have a session, since fixture
+// data is read without one
+func (r *MemoryAuthRepository) HasSession(userID int64) bool {
+	return true
+}
+
+// GetUserInfo retrieves user information
+func (r *MemoryAuthRepository) GetUserInfo(ctx context.Context, userID int64) (*entity.User, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, exists := r.users[userID]
+	if !exists {
+		return nil, fmt.Errorf("failed to get user info: user %d not found", userID)
+	}
+	return entity.NewUser(user.ID, user.Login, user.Email, true, r.lastLogin[userID]), nil
+}
+
+// GetUserGroups retrieves the external IDs of the user's groups
+func (r *MemoryAuthRepository) GetUserGroups(ctx context.Context, userID int64) ([]string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	user, exists := r.users[userID]
+	if !exists {
+		return nil, fmt.Errorf("failed to get user groups: user %d not found", userID)
+	}
+	return append([]string(nil), user.Groups...), nil
+}
+
+// IsTwoFactorEnabled reports whether the fixture marks the user as using two-factor authentication
+func (r *MemoryAuthRepository) IsTwoFactorEnabled(ctx context.Context, userID int64) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.users[userID].TwoFactorEnabled, nil
+}
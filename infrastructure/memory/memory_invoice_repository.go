@@ -0,0 +1,178 @@
+package memory
+
+import (
+	"context"
+	"nerp_wrapper/domain/entity"
+	"slices"
+	"sort"
+	"time"
+)
+
+// MemoryInvoiceRepository implements InvoiceRepository interface over fixture invoices
+type MemoryInvoiceRepository struct {
+	invoices []*entity.Invoice
+}
+
+// NewMemoryInvoiceRepository creates a new instance of MemoryInvoiceRepository.
+// Invoices are served newest first.
+func NewMemoryInvoiceRepository(invoices []*entity.Invoice) *MemoryInvoiceRepository {
+	sorted := append([]*entity.Invoice(nil), invoices...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if !sorted[i].DateInvoice.Equal(sorted[j].DateInvoice) {
+			return sorted[i].DateInvoice.After(sorted[j].DateInvoice)
+		}
+		return sorted[i].ID > sorted[j].ID
+	})
+	return &MemoryInvoiceRepository{invoices: sorted}
+}
+
+// find returns copies of the invoices matching the filter and predicate
+func (r *MemoryInvoiceRepository) find(filter entity.InvoiceFilter, match func(*entity.Invoice) bool) []*entity.Invoice {
+	var result []*entity.Invoice
+	for _, invoice := range r.invoices {
+		if !containsID(filter.CompanyIDs, invoice.CompanyID) || !match(invoice) {
+			continue
+		}
+		found := *invoice
+		result = append(result, &found)
+	}
+	return result
+}
+
+// GetAllInvoices retrieves invoices with pagination
+func (r *MemoryInvoiceRepository) GetAllInvoices(ctx context.Context, principal *entity.Principal, filter entity.InvoiceFilter, page, pageSize int) (*entity.InvoicePagination, error) {
+	invoices := r.find(filter, func(invoice *entity.Invoice) bool {
+		return matchesInvoiceListFilter(invoice, filter)
+	})
+
+	start, end, page, pageSize, totalPages := paginate(len(invoices), page, pageSize)
+	return &entity.InvoicePagination{
+		Items:      append([]*entity.Invoice{}, invoices[start:end]...),
+		Page:       page,
+		PageSize:   pageSize,
+		TotalItems: len(invoices),
+		TotalPages: totalPages,
+	}, nil
+}
+
+// matchesInvoiceListFilter reports whether an invoice passes the list
+// filters. Fixtures carry no payment state, so it is derived.
+func matchesInvoiceListFilter(invoice *entity.Invoice, filter entity.InvoiceFilter) bool {
+	if len(filter.States) > 0 {
+		if !slices.Contains(filter.States, invoice.State) {
+			return false
+		}
+	} else if invoice.State == "cancel" {
+		return false
+	}
+	if len(filter.MoveTypes) > 0 && !slices.Contains(filter.MoveTypes, invoice.Type) {
+		return false
+	}
+	if len(filter.PaymentStates) > 0 && !slices.Contains(filter.PaymentStates, invoice.DerivePaymentState()) {
+		return false
+	}
+	if (filter.JournalID != 0 && invoice.JournalID != filter.JournalID) ||
+		(filter.PartnerID != 0 && invoice.Partner != filter.PartnerID) ||
+		(filter.CurrencyID != 0 && invoice.CurrencyID != filter.CurrencyID) {
+		return false
+	}
+	return inDayRange(invoice.DateInvoice, filter.InvoiceDateFrom, filter.InvoiceDateTo) &&
+		inDayRange(invoice.DateDue, filter.DueDateFrom, filter.DueDateTo)
+}
+
+// GetDailyInvoiceSummary retrieves daily invoice summary with pagination
+func (r *MemoryInvoiceRepository) GetDailyInvoiceSummary(ctx context.Context, principal *entity.Principal, filter entity.InvoiceFilter, page, pageSize int) (*entity.InvoiceSummaryResponse, error) {
+	invoices := r.find(filter, func(invoice *entity.Invoice) bool {
+		return invoice.State == "posted"
+	})
+
+	start, end, page, pageSize, totalPages := paginate(len(invoices), page, pageSize)
+	return &entity.InvoiceSummaryResponse{
+		Items:      groupInvoicesByDay(invoices[start:end]),
+		Page:       page,
+		PageSize:   pageSize,
+		TotalItems: len(invoices),
+		TotalPages: totalPages,
+	}, nil
+}
+
+// GetPeriodInvoiceSummary retrieves invoice summary for a specific period
+func (r *MemoryInvoiceRepository) GetPeriodInvoiceSummary(ctx context.Context, principal *entity.Principal, filter entity.InvoiceFilter, periodType entity.PeriodType, customStartDate, customEndDate *time.Time) (*entity.PeriodInvoiceSummaryResponse, error) {
+	startDate, endDate := periodType.DateRange(time.Now(), customStartDate, customEndDate)
+	invoices := r.find(filter, func(invoice *entity.Invoice) bool {
+		return invoice.State == "posted" && inDateRange(invoice.DateInvoice, startDate, endDate)
+	})
+
+	var totalAmount float64
+	companyMap := make(map[int64]*entity.CompanyInvoiceSummary)
+	for _, invoice := range invoices {
+		totalAmount += invoice.AmountTotal
+		if filter.Consolidated {
+			if _, exists := companyMap[invoice.CompanyID]; !exists {
+				companyMap[invoice.CompanyID] = &entity.CompanyInvoiceSummary{CompanyID: invoice.CompanyID, CompanyName: invoice.CompanyName}
+			}
+			companyMap[invoice.CompanyID].TotalAmount += invoice.AmountTotal
+			companyMap[invoice.CompanyID].InvoiceCount++
+		}
+	}
+
+	items := groupInvoicesByDay(invoices)
+	if !filter.IncludeItems {
+		for i := range items {
+			items[i].Invoices = nil
+		}
+	}
+
+	var companies []entity.CompanyInvoiceSummary
+	for _, company := range companyMap {
+		companies = append(companies, *company)
+	}
+	sort.Slice(companies, func(i, j int) bool {
+		return companies[i].CompanyID < companies[j].CompanyID
+	})
+
+	return &entity.PeriodInvoiceSummaryResponse{
+		Period:       periodType.Describe(startDate, endDate),
+		PeriodType:   periodType,
+		DateRange:    entity.DateRange{StartDate: startDate, EndDate: endDate},
+		Items:        items,
+		TotalAmount:  totalAmount,
+		InvoiceCount: len(invoices),
+		AverageDaily: totalAmount / entity.PeriodDays(startDate, endDate),
+		Companies:    companies,
+	}, nil
+}
+
+// groupInvoicesByDay groups invoices by invoice date, newest day first
+func groupInvoicesByDay(invoices []*entity.Invoice) []entity.DailyInvoiceSummary {
+	dateMap := make(map[string]*entity.DailyInvoiceSummary)
+	for _, invoice := range invoices {
+		dateStr := invoice.DateInvoice.Format("2006-01-02")
+		if _, exists := dateMap[dateStr]; !exists {
+			dateMap[dateStr] = &entity.DailyInvoiceSummary{
+				Date:     invoice.DateInvoice.Truncate(24 * time.Hour),
+				Invoices: []entity.InvoiceSummary{},
+			}
+		}
+
+		summary := dateMap[dateStr]
+		summary.TotalAmount += invoice.AmountTotal
+		summary.InvoiceCount++
+		summary.Invoices = append(summary.Invoices, entity.InvoiceSummary{
+			InvoiceNumber: summary.InvoiceCount,
+			InvoiceID:     invoice.ID,
+			InvoiceName:   invoice.Name,
+			AmountTotal:   invoice.AmountTotal,
+			DateInvoice:   invoice.DateInvoice,
+		})
+	}
+
+	summaries := []entity.DailyInvoiceSummary{}
+	for _, summary := range dateMap {
+		summaries = append(summaries, *summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Date.After(summaries[j].Date)
+	})
+	return summaries
+}
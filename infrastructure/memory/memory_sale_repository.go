@@ -0,0 +1,190 @@
+package memory
+
+import (
+	"context"
+	"nerp_wrapper/domain/entity"
+	"slices"
+	"sort"
+	"time"
+)
+
+// MemorySaleRepository implements SaleRepository interface over fixture sale orders
+type MemorySaleRepository struct {
+	orders []*entity.SaleOrder
+}
+
+// NewMemorySaleRepository creates a new instance of MemorySaleRepository.
+// Orders are served newest first, as Odoo orders sale.order by default.
+func NewMemorySaleRepository(orders []*entity.SaleOrder) *MemorySaleRepository {
+	sorted := append([]*entity.SaleOrder(nil), orders...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if !sorted[i].DateOrder.Equal(sorted[j].DateOrder) {
+			return sorted[i].DateOrder.After(sorted[j].DateOrder)
+		}
+		return sorted[i].ID > sorted[j].ID
+	})
+	return &MemorySaleRepository{orders: sorted}
+}
+
+// visible reports whether the principal may see the order. Fixtures carry no
+// sales teams, so the team scope only grants the principal's own orders.
+func (r *MemorySaleRepository) visible(principal *entity.Principal, order *entity.SaleOrder) bool {
+	if principal.SalesScope() == entity.RecordScopeAll {
+		return true
+	}
+	return order.SalespersonID == principal.UserID
+}
+
+// find returns copies of the visible orders matching the filter and predicate
+func (r *MemorySaleRepository) find(principal *entity.Principal, filter entity.SaleOrderFilter, match func(*entity.SaleOrder) bool) []*entity.SaleOrder {
+	var result []*entity.SaleOrder
+	for _, order := range r.orders {
+		if !r.visible(principal, order) || !containsID(filter.CompanyIDs, order.CompanyID) || !match(order) {
+			continue
+		}
+		found := *order
+		result = append(result, &found)
+	}
+	return result
+}
+
+// GetAllSaleOrders retrieves sale orders with pagination
+func (r *MemorySaleRepository) GetAllSaleOrders(ctx context.Context, principal *entity.Principal, filter entity.SaleOrderFilter, page, pageSize int) (*entity.SaleOrderPagination, error) {
+	orders := r.find(principal, filter, func(order *entity.SaleOrder) bool {
+		return matchesSaleOrderListFilter(order, filter)
+	})
+
+	start, end, page, pageSize, totalPages := paginate(len(orders), page, pageSize)
+	return &entity.SaleOrderPagination{
+		Items:      append([]*entity.SaleOrder{}, orders[start:end]...),
+		Page:       page,
+		PageSize:   pageSize,
+		TotalItems: len(orders),
+		TotalPages: totalPages,
+	}, nil
+}
+
+// matchesSaleOrderListFilter reports whether an order passes the list
+// filters. Fixtures carry no sales teams, so a team filter matches nothing.
+func matchesSaleOrderListFilter(order *entity.SaleOrder, filter entity.SaleOrderFilter) bool {
+	if len(filter.States) > 0 {
+		if !slices.Contains(filter.States, order.State) {
+			return false
+		}
+	} else if order.State == "cancel" {
+		return false
+	}
+	if filter.PartnerID != 0 && order.Partner != filter.PartnerID {
+		return false
+	}
+	if filter.SalespersonID != 0 && order.SalespersonID != filter.SalespersonID {
+		return false
+	}
+	if filter.TeamID != 0 {
+		return false
+	}
+	if !inDayRange(order.DateOrder, filter.DateFrom, filter.DateTo) {
+		return false
+	}
+	if (filter.AmountMin != nil && order.AmountTotal < *filter.AmountMin) || (filter.AmountMax != nil && order.AmountTotal > *filter.AmountMax) {
+		return false
+	}
+	return filter.Search == "" || containsFold(filter.Search, order.Name, order.ClientOrderRef, order.PartnerName)
+}
+
+// GetDailySalesSummary retrieves daily sales summary with pagination
+func (r *MemorySaleRepository) GetDailySalesSummary(ctx context.Context, principal *entity.Principal, filter entity.SaleOrderFilter, page, pageSize int) (*entity.SalesSummaryResponse, error) {
+	orders := r.find(principal, filter, func(order *entity.SaleOrder) bool {
+		return order.State == "sale"
+	})
+
+	start, end, page, pageSize, totalPages := paginate(len(orders), page, pageSize)
+	return &entity.SalesSummaryResponse{
+		Items:      groupSalesByDay(orders[start:end]),
+		Page:       page,
+		PageSize:   pageSize,
+		TotalItems: len(orders),
+		TotalPages: totalPages,
+	}, nil
+}
+
+// GetPeriodSalesSummary retrieves sales summary for a specific period
+func (r *MemorySaleRepository) GetPeriodSalesSummary(ctx context.Context, principal *entity.Principal, filter entity.SaleOrderFilter, periodType entity.PeriodType, customStartDate, customEndDate *time.Time) (*entity.PeriodSalesSummaryResponse, error) {
+	startDate, endDate := periodType.DateRange(time.Now(), customStartDate, customEndDate)
+	orders := r.find(principal, filter, func(order *entity.SaleOrder) bool {
+		return order.State == "sale" && inDateRange(order.DateOrder, startDate, endDate)
+	})
+
+	var totalAmount float64
+	companyMap := make(map[int64]*entity.CompanySalesSummary)
+	for _, order := range orders {
+		totalAmount += order.AmountTotal
+		if filter.Consolidated {
+			if _, exists := companyMap[order.CompanyID]; !exists {
+				companyMap[order.CompanyID] = &entity.CompanySalesSummary{CompanyID: order.CompanyID, CompanyName: order.CompanyName}
+			}
+			companyMap[order.CompanyID].TotalAmount += order.AmountTotal
+			companyMap[order.CompanyID].OrderCount++
+		}
+	}
+
+	items := groupSalesByDay(orders)
+	if !filter.IncludeItems {
+		for i := range items {
+			items[i].Orders = nil
+		}
+	}
+
+	var companies []entity.CompanySalesSummary
+	for _, company := range companyMap {
+		companies = append(companies, *company)
+	}
+	sort.Slice(companies, func(i, j int) bool {
+		return companies[i].CompanyID < companies[j].CompanyID
+	})
+
+	return &entity.PeriodSalesSummaryResponse{
+		Period:       periodType.Describe(startDate, endDate),
+		PeriodType:   periodType,
+		DateRange:    entity.DateRange{StartDate: startDate, EndDate: endDate},
+		Items:        items,
+		TotalAmount:  totalAmount,
+		OrderCount:   len(orders),
+		AverageDaily: totalAmount / entity.PeriodDays(startDate, endDate),
+		Companies:    companies,
+	}, nil
+}
+
+// groupSalesByDay groups orders by order date, newest day first
+func groupSalesByDay(orders []*entity.SaleOrder) []entity.DailySalesSummary {
+	dateMap := make(map[string]*entity.DailySalesSummary)
+	for _, order := range orders {
+		dateStr := order.DateOrder.Format("2006-01-02")
+		if _, exists := dateMap[dateStr]; !exists {
+			dateMap[dateStr] = &entity.DailySalesSummary{
+				Date:   order.DateOrder.Truncate(24 * time.Hour),
+				Orders: []entity.SaleOrderSummary{},
+			}
+		}
+
+		summary := dateMap[dateStr]
+		summary.TotalAmount += order.AmountTotal
+		summary.OrderCount++
+		summary.Orders = append(summary.Orders, entity.SaleOrderSummary{
+			OrderNumber: summary.OrderCount,
+			OrderID:     order.ID,
+			OrderName:   order.Name,
+			AmountTotal: order.AmountTotal,
+			DateOrder:   order.DateOrder,
+		})
+	}
+
+	summaries := []entity.DailySalesSummary{}
+	for _, summary := range dateMap {
+		summaries = append(summaries, *summary)
+	}
+	sort.Slice(summaries, func(i, j int) bool {
+		return summaries[i].Date.After(summaries[j].Date)
+	})
+	return summaries
+}
@@ -0,0 +1,113 @@
+package memory
+
+import (
+	"nerp_wrapper/domain/entity"
+	"sync"
+	"time"
+)
+
+// MemoryTokenRepository implements TokenRepository interface in process memory
+type MemoryTokenRepository struct {
+	mu            sync.Mutex
+	refreshTokens map[string]*entity.RefreshToken
+	revokedAccess map[string]time.Time
+}
+
+// NewMemoryTokenRepository creates a new instance of MemoryTokenRepository
+func NewMemoryTokenRepository() *MemoryTokenRepository {
+	return &MemoryTokenRepository{
+		refreshTokens: make(map[string]*entity.RefreshToken),
+		revokedAccess: make(map[string]time.Time),
+	}
+}
+
+// SaveRefreshToken stores a newly issued refresh token
+func (r *MemoryTokenRepository) SaveRefreshToken(token *entity.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := *token
+	r.refreshTokens[token.TokenHash] = &stored
+	return nil
+}
+
+// ConsumeRefreshToken marks a refresh token as used and returns its previous state
+func (r *MemoryTokenRepository) ConsumeRefreshToken(tokenHash string, usedAt time.Time) (*entity.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, exists := r.refreshTokens[tokenHash]
+	if !exists {
+		return nil, nil
+	}
+
+	previous := *stored
+	if stored.UsedAt == nil {
+		stored.UsedAt = &usedAt
+	}
+	return &previous, nil
+}
+
+// GetRefreshToken retrieves a refresh token by hash
+func (r *MemoryTokenRepository) GetRefreshToken(tokenHash string) (*entity.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, exists := r.refreshTokens[tokenHash]
+	if !exists {
+		return nil, nil
+	}
+
+	token := *stored
+	return &token, nil
+}
+
+// RevokeRefreshTokenFamily revokes every refresh token of a family
+func (r *MemoryTokenRepository) RevokeRefreshTokenFamily(familyID string, revokedAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, token := range r.refreshTokens {
+		if token.FamilyID == familyID && token.RevokedAt == nil {
+			revoked := revokedAt
+			token.RevokedAt = &revoked
+		}
+	}
+	return nil
+}
+
+// RevokeAccessToken blacklists an access token until it expires
+func (r *MemoryTokenRepository) RevokeAccessToken(tokenID string, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.revokedAccess[tokenID] = expiresAt
+	return nil
+}
+
+// IsAccessTokenRevoked reports whether an access token has been blacklisted
+func (r *MemoryTokenRepository) IsAccessTokenRevoked(tokenID string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, revoked := r.revokedAccess[tokenID]
+	return revoked, nil
+}
+
+// DeleteExpired removes refresh tokens and blacklist entries expired before the given time
+func (r *MemoryTokenRepository) DeleteExpired(before time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for hash, token := range r.refreshTokens {
+		if token.ExpiresAt.Before(before) {
+			delete(r.refreshTokens, hash)
+		}
+	}
+	for tokenID, expiresAt := range r.revokedAccess {
+		if expiresAt.Before(before) {
+			delete(r.revokedAccess, tokenID)
+		}
+	}
+	return nil
+}
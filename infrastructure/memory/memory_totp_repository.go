@@ -0,0 +1,54 @@
+package memory
+
+import (
+	"nerp_wrapper/domain/entity"
+	"sync"
+)
+
+// MemoryTOTPRepository implements TOTPRepository interface in process memory
+type MemoryTOTPRepository struct {
+	mu          sync.Mutex
+	enrollments map[totpKey]*entity.TOTPEnrollment
+}
+
+type totpKey struct {
+	tenantID string
+	userID   int64
+}
+
+// NewMemoryTOTPRepository creates a new instance of MemoryTOTPRepository
+func NewMemoryTOTPRepository() *MemoryTOTPRepository {
+	return &MemoryTOTPRepository{enrollments: make(map[totpKey]*entity.TOTPEnrollment)}
+}
+
+// GetTOTPEnrollment retrieves the enrollment of a user
+func (r *MemoryTOTPRepository) GetTOTPEnrollment(tenantID string, userID int64) (*entity.TOTPEnrollment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, exists := r.enrollments[totpKey{tenantID, userID}]
+	if !exists {
+		return nil, nil
+	}
+	enrollment := *stored
+	return &enrollment, nil
+}
+
+// SaveTOTPEnrollment creates or replaces the enrollment of a user
+func (r *MemoryTOTPRepository) SaveTOTPEnrollment(enrollment *entity.TOTPEnrollment) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored := *enrollment
+	r.enrollments[totpKey{enrollment.TenantID, enrollment.UserID}] = &stored
+	return nil
+}
+
+// DeleteTOTPEnrollment removes the enrollment of a user
+func (r *MemoryTOTPRepository) DeleteTOTPEnrollment(tenantID string, userID int64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.enrollments, totpKey{tenantID, userID})
+	return nil
+}
@@ -0,0 +1,147 @@
+package cassette
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Redacted replaces credentials in recorded requests
+const Redacted = "REDACTED"
+
+// secretParams gives the position of the password or API key among the
+// parameters of the XML-RPC methods the wrapper calls
+var secretParams = map[string]int{
+	"authenticate": 2, // db, login, password, user agent env
+	"login":        2, // db, login, password
+	"execute_kw":   2, // db, uid, password, model, method, args, kwargs
+	"execute":      2, // db, uid, password, model, method, args...
+}
+
+// structural lists the XML-RPC elements holding only other elements
+var structural = map[string]bool{
+	"methodCall": true, "params": true, "param": true,
+	"array": true, "data": true, "struct": true, "member": true,
+}
+
+// element is a parsed XML element holding either text or child elements
+type element struct {
+	name     string
+	text     string
+	children []*element
+}
+
+// redactBody rewrites an XML-RPC method call with its credential parameter
+// replaced. The output is canonical, with struct members sorted by name, so
+// requests differing only in map order, formatting or credentials compare
+// equal.
+func redactBody(body []byte) (string, error) {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return "", nil
+	}
+	root, err := parseElement(xml.NewDecoder(bytes.NewReader(body)))
+	if err != nil {
+		return "", fmt.Errorf("failed to parse XML-RPC request: %v", err)
+	}
+
+	var method string
+	for _, child := range root.children {
+		switch child.name {
+		case "methodName":
+			method = strings.TrimSpace(child.text)
+		case "params":
+			if position, ok := secretParams[method]; ok && position < len(child.children) {
+				redact(child.children[position])
+			}
+		}
+	}
+	sortMembers(root)
+
+	var out bytes.Buffer
+	out.WriteString(xml.Header[:len(xml.Header)-1])
+	writeElement(&out, root)
+	return out.String(), nil
+}
+
+// parseElement reads the next element and its content from decoder
+func parseElement(decoder *xml.Decoder) (*element, error) {
+	var stack []*element
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			return nil, fmt.Errorf("unexpected end of document")
+		}
+		if err != nil {
+			return nil, err
+		}
+		switch t := token.(type) {
+		case xml.StartElement:
+			e := &element{name: t.Name.Local}
+			if len(stack) > 0 {
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, e)
+			}
+			stack = append(stack, e)
+		case xml.EndElement:
+			e := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				return e, nil
+			}
+		case xml.CharData:
+			if len(stack) > 0 && !structural[stack[len(stack)-1].name] {
+				stack[len(stack)-1].text += string(t)
+			}
+		}
+	}
+}
+
+// redact replaces every text value below e
+func redact(e *element) {
+	if strings.TrimSpace(e.text) != "" {
+		e.text = Redacted
+	}
+	for _, child := range e.children {
+		redact(child)
+	}
+}
+
+func sortMembers(e *element) {
+	if e.name == "struct" {
+		sort.SliceStable(e.children, func(i, j int) bool {
+			return memberName(e.children[i]) < memberName(e.children[j])
+		})
+	}
+	for _, child := range e.children {
+		sortMembers(child)
+	}
+}
+
+func memberName(member *element) string {
+	for _, child := range member.children {
+		if child.name == "name" {
+			return child.text
+		}
+	}
+	return ""
+}
+
+func writeElement(out *bytes.Buffer, e *element) {
+	fmt.Fprintf(out, "<%s>", e.name)
+	xml.EscapeText(out, []byte(e.text))
+	for _, child := range e.children {
+		writeElement(out, child)
+	}
+	fmt.Fprintf(out, "</%s>", e.name)
+}
+
+// redactURL drops user info, which may carry basic auth credentials
+func redactURL(u *url.URL) string {
+	clean := *u
+	clean.User = nil
+	return clean.String()
+}
@@ -0,0 +1,154 @@
+package cassette
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// Mode selects what the transport does with Odoo traffic
+type Mode string
+
+const (
+	ModeOff    Mode = "off"    // Pass requests through untouched
+	ModeRecord Mode = "record" // Pass requests through and record them
+	ModeReplay Mode = "replay" // Answer from the cassette without network access
+)
+
+// Transport is an http.RoundTripper recording XML-RPC calls to a cassette or
+// replaying them from it. Replayed requests are matched on method, URL and
+// redacted body; identical requests get the recorded responses in order,
+// the last one repeating once they run out.
+type Transport struct {
+	mu       sync.Mutex
+	mode     Mode
+	path     string
+	next     http.RoundTripper
+	cassette *Cassette
+	replays  map[string][]Response
+}
+
+// NewTransport creates a transport in the given mode. Recording starts a new
+// cassette at path and sends requests on with next; replaying loads path.
+func NewTransport(mode Mode, path string, next http.RoundTripper) (*Transport, error) {
+	t := &Transport{mode: mode, path: path, next: next, cassette: &Cassette{}}
+	switch mode {
+	case ModeOff, ModeRecord:
+		if next == nil {
+			t.next = http.DefaultTransport
+		}
+	case ModeReplay:
+		cassette, err := Load(path)
+		if err != nil {
+			return nil, err
+		}
+		t.cassette = cassette
+		t.replays = make(map[string][]Response)
+		for _, interaction := range cassette.Interactions {
+			key := matchKey(interaction.Request)
+			t.replays[key] = append(t.replays[key], interaction.Response)
+		}
+	default:
+		return nil, fmt.Errorf("invalid cassette mode %q", mode)
+	}
+	return t, nil
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *Transport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.mode == ModeOff {
+		return t.next.RoundTrip(req)
+	}
+
+	var body []byte
+	if req.Body != nil {
+		var err error
+		if body, err = io.ReadAll(req.Body); err != nil {
+			return nil, fmt.Errorf("failed to read request body: %v", err)
+		}
+		req.Body.Close()
+	}
+	redacted, err := redactBody(body)
+	if err != nil {
+		return nil, err
+	}
+	recorded := Request{Method: req.Method, URL: redactURL(req.URL), Body: redacted}
+
+	if t.mode == ModeReplay {
+		return t.replay(req, recorded)
+	}
+	return t.record(req, body, recorded)
+}
+
+func (t *Transport) replay(req *http.Request, recorded Request) (*http.Response, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	key := matchKey(recorded)
+	responses := t.replays[key]
+	if len(responses) == 0 {
+		return nil, fmt.Errorf("cassette %s has no recorded response for %s %s", t.path, recorded.Method, recorded.URL)
+	}
+	response := responses[0]
+	if len(responses) > 1 {
+		t.replays[key] = responses[1:]
+	}
+	return newResponse(req, response), nil
+}
+
+func (t *Transport) record(req *http.Request, body []byte, recorded Request) (*http.Response, error) {
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.ContentLength = int64(len(body))
+	resp, err := t.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response body: %v", err)
+	}
+	response := Response{
+		StatusCode:  resp.StatusCode,
+		ContentType: resp.Header.Get("Content-Type"),
+		Body:        string(data),
+	}
+
+	t.mu.Lock()
+	t.cassette.Interactions = append(t.cassette.Interactions, Interaction{Request: recorded, Response: response})
+	err = t.cassette.Save(t.path)
+	t.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+	return resp, nil
+}
+
+func matchKey(req Request) string {
+	return req.Method + " " + req.URL + "\n" + req.Body
+}
+
+func newResponse(req *http.Request, recorded Response) *http.Response {
+	header := make(http.Header)
+	if recorded.ContentType != "" {
+		header.Set("Content-Type", recorded.ContentType)
+	}
+	header.Set("Content-Length", strconv.Itoa(len(recorded.Body)))
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", recorded.StatusCode, http.StatusText(recorded.StatusCode)),
+		StatusCode:    recorded.StatusCode,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        header,
+		Body:          io.NopCloser(bytes.NewReader([]byte(recorded.Body))),
+		ContentLength: int64(len(recorded.Body)),
+		Request:       req,
+	}
+}
@@ -0,0 +1,96 @@
+package cassette_test
+
+import (
+	"nerp_wrapper/infrastructure/memory"
+	"nerp_wrapper/infrastructure/odoo/cassette"
+	"nerp_wrapper/infrastructure/odoo/odootest"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/kolo/xmlrpc"
+)
+
+const secret = "s3cret-password"
+
+// session authenticates against the Odoo at url through transport and reads
+// the sale orders of a partner, as a query worth capturing would
+func session(t *testing.T, url string, transport *cassette.Transport) (int64, []interface{}) {
+	t.Helper()
+	common, err := xmlrpc.NewClient(url+"/xmlrpc/2/common", transport)
+	if err != nil {
+		t.Fatalf("failed to create common client: %v", err)
+	}
+	defer common.Close()
+	var uid int64
+	if err := common.Call("authenticate", []interface{}{"nerp", "robot", secret, map[string]interface{}{}}, &uid); err != nil {
+		t.Fatalf("authenticate failed: %v", err)
+	}
+
+	object, err := xmlrpc.NewClient(url+"/xmlrpc/2/object", transport)
+	if err != nil {
+		t.Fatalf("failed to create object client: %v", err)
+	}
+	defer object.Close()
+	var orders []interface{}
+	args := []interface{}{"nerp", uid, secret, "sale.order", "search_read",
+		[]interface{}{[]interface{}{[]interface{}{"partner_id", "=", 9}}},
+		map[string]interface{}{"fields": []string{"name", "amount_total"}, "order": "id"}}
+	if err := object.Call("execute_kw", args, &orders); err != nil {
+		t.Fatalf("search_read failed: %v", err)
+	}
+	return uid, orders
+}
+
+func TestRecordedTrafficReplaysWithoutOdoo(t *testing.T) {
+	fixtures, err := memory.LoadFixtures(filepath.Join("..", "..", "..", "fixtures"))
+	if err != nil {
+		t.Fatalf("failed to load fixtures: %v", err)
+	}
+	fake := odootest.NewServer("nerp")
+	fake.Seed(fixtures)
+	fake.AddUser(0, "robot", secret, "Robot", "base.group_user")
+	odooServer := httptest.NewServer(fake)
+	path := filepath.Join(t.TempDir(), "odoo.json")
+
+	recorder, err := cassette.NewTransport(cassette.ModeRecord, path, nil)
+	if err != nil {
+		t.Fatalf("failed to start recording: %v", err)
+	}
+	recordedUID, recordedOrders := session(t, odooServer.URL, recorder)
+	if len(recordedOrders) == 0 {
+		t.Fatal("recorded no sale orders of partner 9")
+	}
+	url := odooServer.URL
+	odooServer.Close()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read cassette: %v", err)
+	}
+	if strings.Contains(string(data), secret) {
+		t.Error("cassette holds the password")
+	}
+
+	player, err := cassette.NewTransport(cassette.ModeReplay, path, nil)
+	if err != nil {
+		t.Fatalf("failed to load cassette: %v", err)
+	}
+	replayedUID, replayedOrders := session(t, url, player)
+	if replayedUID != recordedUID || !reflect.DeepEqual(replayedOrders, recordedOrders) {
+		t.Errorf("replay returned uid %d and %v, recorded uid %d and %v", replayedUID, replayedOrders, recordedUID, recordedOrders)
+	}
+
+	common, err := xmlrpc.NewClient(url+"/xmlrpc/2/common", player)
+	if err != nil {
+		t.Fatalf("failed to create common client: %v", err)
+	}
+	defer common.Close()
+	var version map[string]interface{}
+	if err := common.Call("version", nil, &version); err == nil {
+		t.Error("replay answered a call missing from the cassette")
+	}
+}
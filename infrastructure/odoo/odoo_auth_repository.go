@@ -1,8 +1,11 @@
 package odoo
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"nerp_wrapper/domain/entity"
+	"sync"
 	"time"
 
 	odoo "github.com/skilld-labs/go-odoo"
@@ -10,7 +13,15 @@ import (
 
 // OdooAuthRepository implements AuthRepository interface using Odoo
 type OdooAuthRepository struct {
-	client *odoo.Client
+	client   *odoo.Client
+	clients  *ClientSet
+	pool     *ClientPool
+	url      string
+	database string
+
+	totpFieldMu      sync.Mutex
+	totpFieldChecked bool
+	totpFieldExists  bool
 }
 
 // GetClient returns the underlying Odoo client
@@ -18,67 +29,85 @@ func (r *OdooAuthRepository) GetClient() *odoo.Client {
 	return r.client
 }
 
+// ServiceClients returns the admin clients, which serve API key callers
+func (r *OdooAuthRepository) ServiceClients() *ClientSet {
+	return r.clients
+}
+
 // NewOdooAuthRepository creates a new instance of OdooAuthRepository
-func NewOdooAuthRepository(adminUsername, adminPassword, database, url string) (*OdooAuthRepository, error) {
+func NewOdooAuthRepository(adminUsername, adminPassword, database, url string, pool *ClientPool) (*OdooAuthRepository, error) {
 	// Create Odoo client with admin credentials for system connection
-	client, err := odoo.NewClient(&odoo.ClientConfig{
-		Admin:    adminUsername,
-		Password: adminPassword,
-		Database: database,
-		URL:      url,
-	})
+	open := func() (*odoo.Client, error) {
+		return odoo.NewClient(&odoo.ClientConfig{
+			Admin:    adminUsername,
+			Password: adminPassword,
+			Database: database,
+			URL:      url,
+		})
+	}
+	client, err := open()
 	if err != nil {
-		return nil, fmt.Errorf("failed to create Odoo client: %v", err)
+		return nil, fmt.Errorf("failed to create Odoo client: %w", err)
 	}
-	return &OdooAuthRepository{client: client}, nil
+	return &OdooAuthRepository{
+		client:   client,
+		clients:  NewClientSet(client, sessionClients, open),
+		pool:     pool,
+		url:      url,
+		database: database,
+	}, nil
 }
 
-// Login authenticates a user with Odoo
-func (r *OdooAuthRepository) Login(username, password string) (*entity.User, error) {
-	// Use admin client to verify user credentials
-	criteria := odoo.NewCriteria().
-		Add("login", "=", username).
-		Add("password", "=", password)
+// admin returns the admin client bound to ctx
+func (r *OdooAuthRepository) admin(ctx context.Context) *contextClient {
+	return &contextClient{ctx: ctx, client: r.client, clients: r.clients, resilience: r.pool.Resilience()}
+}
 
-	users, err := r.client.FindResUsers(criteria)
+// Login authenticates a user against Odoo with a password or API key, opens
+// the user's own Odoo session in the client pool and loads the user's profile
+// with the admin client
+func (r *OdooAuthRepository) Login(ctx context.Context, username, secret string) (*entity.User, error) {
+	uid, err := resilientCall(ctx, r.pool.Resilience(), true, func() (int64, error) {
+		return Authenticate(ctx, r.url, r.database, username, secret)
+	})
 	if err != nil {
-		return nil, fmt.Errorf("failed to search for user: %v", err)
+		return nil, err
 	}
 
-	if users == nil {
-		return nil, fmt.Errorf("invalid credentials")
+	if err := r.pool.Open(ctx, uid, username, secret); err != nil {
+		return nil, err
 	}
 
-	// Update last login in Odoo
-	now := time.Now()
-	users.LoginDate = odoo.NewTime(now)
-	err = r.client.UpdateResUsers(users)
+	// Odoo records the login date itself during authenticate
+	user, err := r.GetUserInfo(ctx, uid)
 	if err != nil {
-		return nil, fmt.Errorf("failed to update last login: %v", err)
+		return nil, err
 	}
-
-	// Convert Odoo user to domain entity
-	return entity.NewUser(
-		users.Id.Get(),
-		users.Login.Get(),
-		users.Email.Get(),
-		users.Active.Get(),
-		now,
-	), nil
+	if user.LastLogin.IsZero() {
+		user.LastLogin = time.Now()
+	}
+	return user, nil
 }
 
-// Logout handles user logout
-func (r *OdooAuthRepository) Logout() error {
-	// In Odoo, logout is typically handled on the client side
+// Logout ends one login of the user, closing the user's Odoo clients once no
+// login remains
+func (r *OdooAuthRepository) Logout(ctx context.Context, userID int64) error {
+	r.pool.Remove(userID)
 	return nil
 }
 
+// HasSession reports whether the user's Odoo clients are still open, which
+// they are not once the user's logins ended or went idle
+func (r *OdooAuthRepository) HasSession(userID int64) bool {
+	return r.pool.HasSession(userID)
+}
+
 // GetUserInfo retrieves user information
-func (r *OdooAuthRepository) GetUserInfo(userID int64) (*entity.User, error) {
+func (r *OdooAuthRepository) GetUserInfo(ctx context.Context, userID int64) (*entity.User, error) {
 	// Use admin client to get user information
-	odooUser, err := r.client.GetResUsers(userID)
+	odooUser, err := r.admin(ctx).GetResUsers(userID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user info: %v", err)
+		return nil, fmt.Errorf("failed to get user info: %w", err)
 	}
 
 	// Convert last login time
@@ -95,3 +124,75 @@ func (r *OdooAuthRepository) GetUserInfo(userID int64) (*entity.User, error) {
 		lastLogin,
 	), nil
 }
+
+// GetUserGroups retrieves the external IDs of the groups the user belongs to
+func (r *OdooAuthRepository) GetUserGroups(ctx context.Context, userID int64) ([]string, error) {
+	client := r.admin(ctx)
+	var users []odoo.ResUsers
+	userOptions := odoo.NewOptions().FetchFields("id", "groups_id")
+	if err := client.Read("res.users", []int64{userID}, userOptions, &users); err != nil {
+		return nil, fmt.Errorf("failed to read user groups: %w", err)
+	}
+	if len(users) == 0 || users[0].GroupsId == nil || len(users[0].GroupsId.Get()) == 0 {
+		return []string{}, nil
+	}
+
+	criteria := odoo.NewCriteria().
+		Add("model", "=", "res.groups").
+		Add("res_id", "in", users[0].GroupsId.Get())
+	dataOptions := odoo.NewOptions().FetchFields("module", "name")
+
+	var records []odoo.IrModelData
+	if err := client.SearchRead("ir.model.data", criteria, dataOptions, &records); err != nil {
+		if errors.Is(err, odoo.ErrNotFound) {
+			return []string{}, nil
+		}
+		return nil, fmt.Errorf("failed to resolve group external IDs: %w", err)
+	}
+
+	groups := make([]string, 0, len(records))
+	for _, record := range records {
+		if record.Module != nil && record.Name != nil {
+			groups = append(groups, record.Module.Get()+"."+record.Name.Get())
+		}
+	}
+	return groups, nil
+}
+
+// IsTwoFactorEnabled reports whether the user enabled TOTP in Odoo (auth_totp).
+// Databases without the auth_totp module never require it.
+func (r *OdooAuthRepository) IsTwoFactorEnabled(ctx context.Context, userID int64) (bool, error) {
+	exists, err := r.hasTOTPField(ctx)
+	if err != nil || !exists {
+		return false, err
+	}
+
+	resp, err := r.admin(ctx).ExecuteKw("read", "res.users", []interface{}{[]int64{userID}}, odoo.NewOptions().FetchFields("totp_enabled"))
+	if err != nil {
+		return false, fmt.Errorf("failed to read two-factor status: %w", err)
+	}
+	records, ok := resp.([]interface{})
+	if !ok || len(records) == 0 {
+		return false, fmt.Errorf("failed to read two-factor status: user %d not found", userID)
+	}
+	record, _ := records[0].(map[string]interface{})
+	enabled, _ := record["totp_enabled"].(bool)
+	return enabled, nil
+}
+
+// hasTOTPField checks once whether res.users has the auth_totp field
+func (r *OdooAuthRepository) hasTOTPField(ctx context.Context) (bool, error) {
+	r.totpFieldMu.Lock()
+	defer r.totpFieldMu.Unlock()
+
+	if !r.totpFieldChecked {
+		resp, err := r.admin(ctx).ExecuteKw("fields_get", "res.users", []interface{}{[]string{"totp_enabled"}}, odoo.NewOptions().Attributes("type"))
+		if err != nil {
+			return false, fmt.Errorf("failed to inspect res.users fields: %w", err)
+		}
+		fields, _ := resp.(map[string]interface{})
+		_, r.totpFieldExists = fields["totp_enabled"]
+		r.totpFieldChecked = true
+	}
+	return r.totpFieldExists, nil
+}
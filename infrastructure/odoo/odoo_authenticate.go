@@ -0,0 +1,40 @@
+package odoo
+
+import (
+	"context"
+	"fmt"
+	"nerp_wrapper/domain/repository"
+
+	"github.com/kolo/xmlrpc"
+)
+
+// Authenticate verifies a login against Odoo's common.authenticate endpoint
+// and returns the user id. The secret may be the user's password or an Odoo
+// API key; Odoo checks both through the same call.
+func Authenticate(ctx context.Context, url, database, login, secret string) (int64, error) {
+	if login == "" || secret == "" {
+		return 0, repository.ErrInvalidCredentials
+	}
+
+	common, err := xmlrpc.NewClient(url+"/xmlrpc/2/common", nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create Odoo common client: %v", err)
+	}
+	defer common.Close()
+
+	reply, err := withContext(ctx, func() (interface{}, error) {
+		var reply interface{}
+		err := common.Call("authenticate", []interface{}{database, login, secret, map[string]interface{}{}}, &reply)
+		return reply, err
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to authenticate with Odoo: %w", err)
+	}
+
+	// Odoo answers false on rejected credentials and the uid otherwise
+	uid, ok := reply.(int64)
+	if !ok || uid == 0 {
+		return 0, repository.ErrInvalidCredentials
+	}
+	return uid, nil
+}
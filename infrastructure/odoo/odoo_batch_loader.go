@@ -0,0 +1,117 @@
+package odoo
+
+import (
+	"context"
+	"nerp_wrapper/domain/entity"
+	"sync"
+)
+
+const (
+	sessionClients   = 4   // go-odoo clients of a user, bounding its concurrent calls
+	relatedBatchSize = 200 // IDs read per call when loading related records
+)
+
+// batchLoader runs the Odoo calls of one list operation concurrently in the
+// manner of errgroup, each with a client borrowed from the user's client
+// set, so at most the set's size run at once. A failing required call
+// cancels the others and fails the operation; a failing related read leaves
+// the fields it fills empty and is reported as an enrichment warning instead.
+type batchLoader struct {
+	parent context.Context
+	ctx    context.Context
+	cancel context.CancelFunc
+	client *contextClient
+	wg     sync.WaitGroup
+
+	mu       sync.Mutex
+	err      error
+	warnings []entity.EnrichmentWarning
+}
+
+// newBatchLoader creates a loader running calls with client's set under a
+// context derived from the client's
+func newBatchLoader(client *contextClient) *batchLoader {
+	ctx, cancel := context.WithCancel(client.ctx)
+	return &batchLoader{parent: client.ctx, ctx: ctx, cancel: cancel, client: client}
+}
+
+// Go runs a required call
+func (l *batchLoader) Go(call func(client *contextClient) error) {
+	l.run(call, func(err error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if l.err == nil {
+			l.err = err
+			l.cancel()
+		}
+	})
+}
+
+// goRelated runs a best effort read of related records of model
+func (l *batchLoader) goRelated(model string, ids []int64, call func(client *contextClient) error) {
+	l.run(call, func(err error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		l.warnings = append(l.warnings, entity.EnrichmentWarning{Model: model, IDs: ids, Error: err.Error()})
+	})
+}
+
+func (l *batchLoader) run(call func(client *contextClient) error, fail func(error)) {
+	l.wg.Add(1)
+	go func() {
+		defer l.wg.Done()
+		client, err := l.client.clients.acquire(l.ctx, l.client.resilience)
+		if err != nil {
+			fail(err)
+			return
+		}
+		defer l.client.clients.release(client)
+
+		if err := call(&contextClient{ctx: l.ctx, client: client, clients: l.client.clients, resilience: l.client.resilience}); err != nil {
+			fail(err)
+		}
+	}()
+}
+
+// Wait waits for every call, including those started by calls, and returns
+// the first error of a required call. An operation past its deadline fails
+// even when only related reads were cut short.
+func (l *batchLoader) Wait() error {
+	l.wg.Wait()
+	l.cancel()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.err != nil {
+		return l.err
+	}
+	if err := l.parent.Err(); err != nil {
+		return contextError(err)
+	}
+	return nil
+}
+
+// Warnings returns the related reads that failed, once Wait has returned
+func (l *batchLoader) Warnings() []entity.EnrichmentWarning {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.warnings
+}
+
+// relatedIDs collects the distinct IDs of related records in first seen order
+type relatedIDs struct {
+	seen map[int64]bool
+	ids  []int64
+}
+
+// Add records id, ignoring zero and repeated IDs
+func (r *relatedIDs) Add(id int64) {
+	if id == 0 || r.seen[id] {
+		return
+	}
+	if r.seen == nil {
+		r.seen = make(map[int64]bool)
+	}
+	r.seen[id] = true
+	r.ids = append(r.ids, id)
+}
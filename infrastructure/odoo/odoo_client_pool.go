@@ -0,0 +1,175 @@
+package odoo
+
+import (
+	"context"
+	"fmt"
+	"nerp_wrapper/domain/entity"
+	"nerp_wrapper/domain/repository"
+	"sync"
+	"time"
+
+	odoo "github.com/skilld-labs/go-odoo"
+)
+
+// ClientPool keeps the Odoo clients of each authenticated user so that data
+// calls run with the caller's own access rights and record rules
+type ClientPool struct {
+	mu             sync.Mutex
+	url            string
+	database       string
+	idleTTL        time.Duration
+	sessions       map[int64]*clientSession
+	serviceClients *ClientSet
+	resilience     *Resilience
+}
+
+type clientSession struct {
+	clients  *ClientSet
+	logins   int
+	lastUsed time.Time
+}
+
+// NewClientPool creates a new instance of ClientPool. Calls to the Odoo
+// instance go through resilience, which may be nil.
+func NewClientPool(url, database string, idleTTL time.Duration, resilience *Resilience) *ClientPool {
+	return &ClientPool{
+		url:        url,
+		database:   database,
+		idleTTL:    idleTTL,
+		sessions:   make(map[int64]*clientSession),
+		resilience: resilience,
+	}
+}
+
+// Resilience returns the retry and circuit breaker layer of the Odoo instance
+func (p *ClientPool) Resilience() *Resilience {
+	return p.resilience
+}
+
+// Open creates a client authenticated with the user's password or API key
+// and caches it as one more login of that user. The previous clients of the
+// user are retired, closing once the operations running on them end.
+func (p *ClientPool) Open(ctx context.Context, userID int64, login, secret string) error {
+	open := func() (*odoo.Client, error) {
+		return odoo.NewClient(&odoo.ClientConfig{
+			Admin:    login,
+			Password: secret,
+			Database: p.database,
+			URL:      p.url,
+		})
+	}
+	client, err := resilientCall(ctx, p.resilience, true, open)
+	if err != nil {
+		return fmt.Errorf("failed to create Odoo client for user %d: %w", userID, err)
+	}
+
+	p.mu.Lock()
+	session := &clientSession{clients: NewClientSet(client, sessionClients, open), logins: 1, lastUsed: time.Now()}
+	previous := p.sessions[userID]
+	if previous != nil {
+		session.logins += previous.logins
+	}
+	p.sessions[userID] = session
+	p.mu.Unlock()
+
+	if previous != nil {
+		previous.clients.retire()
+	}
+	return nil
+}
+
+// UseServiceClients sets the clients used for principals authenticated with
+// an API key, which have no Odoo user of their own
+func (p *ClientPool) UseServiceClients(clients *ClientSet) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.serviceClients = clients
+}
+
+// Clients returns the cached clients of the principal, which stay open
+// until ctx is done
+func (p *ClientPool) Clients(ctx context.Context, principal *entity.Principal) (*ClientSet, error) {
+	if principal == nil {
+		return nil, repository.ErrSessionExpired
+	}
+	if principal.IsAPIKey() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if p.serviceClients == nil {
+			return nil, fmt.Errorf("no Odoo service client configured for API keys")
+		}
+		return p.serviceClients, nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	session, exists := p.sessions[principal.UserID]
+	if !exists {
+		return nil, repository.ErrSessionExpired
+	}
+	session.lastUsed = time.Now()
+	session.clients.hold(ctx)
+	return session.clients, nil
+}
+
+// HasSession reports whether the user's clients are still cached, marking
+// them as used
+func (p *ClientPool) HasSession(userID int64) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	session, exists := p.sessions[userID]
+	if exists {
+		session.lastUsed = time.Now()
+	}
+	return exists
+}
+
+// Remove ends one login of a user. The user's clients are dropped with the
+// last login and close once the operations running on them end.
+func (p *ClientPool) Remove(userID int64) {
+	p.mu.Lock()
+	session := p.sessions[userID]
+	if session == nil {
+		p.mu.Unlock()
+		return
+	}
+	session.logins--
+	if session.logins > 0 {
+		p.mu.Unlock()
+		return
+	}
+	delete(p.sessions, userID)
+	p.mu.Unlock()
+
+	session.clients.retire()
+}
+
+// EvictIdle closes and drops clients unused for longer than the idle TTL
+func (p *ClientPool) EvictIdle() {
+	cutoff := time.Now().Add(-p.idleTTL)
+
+	p.mu.Lock()
+	var idle []*clientSession
+	for userID, session := range p.sessions {
+		if session.lastUsed.Before(cutoff) {
+			idle = append(idle, session)
+			delete(p.sessions, userID)
+		}
+	}
+	p.mu.Unlock()
+
+	for _, session := range idle {
+		session.clients.retire()
+	}
+}
+
+// RunEviction evicts idle clients at the given interval until the process exits
+func (p *ClientPool) RunEviction(interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for range ticker.C {
+		p.EvictIdle()
+	}
+}
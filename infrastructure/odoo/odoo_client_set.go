@@ -0,0 +1,133 @@
+package odoo
+
+import (
+	"context"
+	"nerp_wrapper/domain/repository"
+	"sync"
+
+	odoo "github.com/skilld-labs/go-odoo"
+)
+
+// ClientSet holds the go-odoo clients of one Odoo user. A go-odoo client
+// sends one call at a time, as kolo/xmlrpc makes the HTTP request while
+// holding the connection, so calls meant to run concurrently each borrow a
+// client of the set. Clients beyond the first are opened on demand, up to
+// the set's size, and kept for later operations.
+type ClientSet struct {
+	primary *odoo.Client
+	open    func() (*odoo.Client, error)
+	idle    chan *odoo.Client
+	slots   chan struct{}
+
+	mu      sync.Mutex
+	opened  []*odoo.Client
+	closed  bool
+	retired bool
+	holders int
+}
+
+// NewClientSet creates a set around an authenticated client. open
+// authenticates another client of the same user; size bounds the clients
+// in use at once.
+func NewClientSet(primary *odoo.Client, size int, open func() (*odoo.Client, error)) *ClientSet {
+	size = max(size, 1)
+	set := &ClientSet{
+		primary: primary,
+		open:    open,
+		idle:    make(chan *odoo.Client, size),
+		slots:   make(chan struct{}, size),
+	}
+	set.idle <- primary
+	return set
+}
+
+// Primary returns the client used for calls made one after another
+func (s *ClientSet) Primary() *odoo.Client {
+	return s.primary
+}
+
+// acquire borrows a client once fewer than size are in use, opening a new
+// one when none is idle. Opening goes through resilience, which may be nil.
+func (s *ClientSet) acquire(ctx context.Context, resilience *Resilience) (*odoo.Client, error) {
+	select {
+	case s.slots <- struct{}{}:
+	case <-ctx.Done():
+		return nil, contextError(ctx.Err())
+	}
+
+	select {
+	case client := <-s.idle:
+		return client, nil
+	default:
+	}
+
+	client, err := resilientCall(ctx, resilience, true, s.open)
+	if err != nil {
+		<-s.slots
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		client.Close()
+		<-s.slots
+		// The user logged out or the session was evicted meanwhile
+		return nil, repository.ErrSessionExpired
+	}
+	s.opened = append(s.opened, client)
+	return client, nil
+}
+
+// release returns a borrowed client to the set
+func (s *ClientSet) release(client *odoo.Client) {
+	s.idle <- client
+	<-s.slots
+}
+
+// hold keeps the set open until ctx is done, so that retiring the set does
+// not close the clients of an operation still running
+func (s *ClientSet) hold(ctx context.Context) {
+	s.mu.Lock()
+	s.holders++
+	s.mu.Unlock()
+
+	context.AfterFunc(ctx, func() {
+		s.mu.Lock()
+		s.holders--
+		closing := s.retired && s.holders == 0
+		s.mu.Unlock()
+		if closing {
+			s.Close()
+		}
+	})
+}
+
+// retire closes the set once no operation holds it anymore
+func (s *ClientSet) retire() {
+	s.mu.Lock()
+	s.retired = true
+	closing := s.holders == 0
+	s.mu.Unlock()
+	if closing {
+		s.Close()
+	}
+}
+
+// Close closes every client of the set
+func (s *ClientSet) Close() {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	opened := s.opened
+	s.opened = nil
+	s.mu.Unlock()
+
+	s.primary.Close()
+	for _, client := range opened {
+		client.Close()
+	}
+}
@@ -0,0 +1,21 @@
+package odoo
+
+import (
+	odoo "github.com/skilld-labs/go-odoo"
+)
+
+// applyCompanyFilter restricts criteria to records of the given companies.
+// An empty list leaves the criteria untouched so every allowed company is included.
+func applyCompanyFilter(criteria *odoo.Criteria, companyIDs []int64) {
+	if len(companyIDs) > 0 {
+		criteria.Add("company_id", "in", companyIDs)
+	}
+}
+
+// companyOf returns the ID and display name of a company_id relation
+func companyOf(company *odoo.Many2One) (int64, string) {
+	if company == nil {
+		return 0, ""
+	}
+	return company.ID, company.Name
+}
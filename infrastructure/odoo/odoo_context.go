@@ -0,0 +1,178 @@
+package odoo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"nerp_wrapper/domain/entity"
+	"nerp_wrapper/domain/repository"
+	"net/http"
+	"reflect"
+	"time"
+
+	odoo "github.com/skilld-labs/go-odoo"
+)
+
+// withContext runs an Odoo request until it returns or ctx is done. go-odoo
+// has no context support, so an abandoned request keeps running in the
+// background until Odoo answers or the transport deadline passes; the caller
+// is released right away and makes no further calls. Requests must keep
+// their results to themselves and return them, since an abandoned request
+// writing into the caller's variables would race with the caller.
+func withContext[T any](ctx context.Context, request func() (T, error)) (T, error) {
+	var zero T
+	if err := ctx.Err(); err != nil {
+		return zero, contextError(err)
+	}
+	type outcome struct {
+		value T
+		err   error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		value, err := request()
+		done <- outcome{value: value, err: err}
+	}()
+	select {
+	case result := <-done:
+		return result.value, result.err
+	case <-ctx.Done():
+		return zero, contextError(ctx.Err())
+	}
+}
+
+// contextError reports a passed deadline as ErrBackendTimeout
+func contextError(err error) error {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return repository.ErrBackendTimeout
+	}
+	return err
+}
+
+// contextClient runs the requests of one operation with a go-odoo client
+// under the operation's context, retrying and failing fast through the
+// resilience layer of the client's Odoo instance
+type contextClient struct {
+	ctx        context.Context
+	client     *odoo.Client
+	clients    *ClientSet
+	resilience *Resilience
+}
+
+// clientFor returns the principal's primary client bound to ctx
+func clientFor(ctx context.Context, provider ClientProvider, principal *entity.Principal) (*contextClient, error) {
+	clients, err := provider.Clients(ctx, principal)
+	if err != nil {
+		return nil, err
+	}
+	return &contextClient{ctx: ctx, client: clients.Primary(), clients: clients, resilience: provider.Resilience(principal)}, nil
+}
+
+// readMethods lists the model methods that are safe to retry
+var readMethods = map[string]bool{
+	"read":         true,
+	"search":       true,
+	"search_read":  true,
+	"search_count": true,
+	"read_group":   true,
+	"fields_get":   true,
+	"name_get":     true,
+}
+
+// Count counts the records matching criteria
+func (c *contextClient) Count(model string, criteria *odoo.Criteria, options *odoo.Options) (int64, error) {
+	return resilientCall(c.ctx, c.resilience, true, func() (int64, error) {
+		return c.client.Count(model, criteria, options)
+	})
+}
+
+// Search returns the IDs of the records matching criteria
+func (c *contextClient) Search(model string, criteria *odoo.Criteria, options *odoo.Options) ([]int64, error) {
+	return resilientCall(c.ctx, c.resilience, true, func() ([]int64, error) {
+		return c.client.Search(model, criteria, options)
+	})
+}
+
+// SearchRead reads the records matching criteria into elem
+func (c *contextClient) SearchRead(model string, criteria *odoo.Criteria, options *odoo.Options, elem interface{}) error {
+	return c.readInto(elem, func(fresh interface{}) error {
+		return c.client.SearchRead(model, criteria, options, fresh)
+	})
+}
+
+// Read reads the records with the given IDs into elem
+func (c *contextClient) Read(model string, ids []int64, options *odoo.Options, elem interface{}) error {
+	return c.readInto(elem, func(fresh interface{}) error {
+		return c.client.Read(model, ids, options, fresh)
+	})
+}
+
+// readInto runs a read decoding into a fresh value of elem's type, and
+// copies it into elem, a pointer, once the read completed in time
+func (c *contextClient) readInto(elem interface{}, read func(fresh interface{}) error) error {
+	target := reflect.ValueOf(elem)
+	if target.Kind() != reflect.Pointer || target.IsNil() {
+		return fmt.Errorf("read target must be a non-nil pointer, got %T", elem)
+	}
+	fresh, err := resilientCall(c.ctx, c.resilience, true, func() (reflect.Value, error) {
+		fresh := reflect.New(target.Type().Elem())
+		return fresh, read(fresh.Interface())
+	})
+	if err != nil {
+		return err
+	}
+	target.Elem().Set(fresh.Elem())
+	return nil
+}
+
+// ExecuteKw calls a model method with positional and keyword arguments
+func (c *contextClient) ExecuteKw(method, model string, args []interface{}, options *odoo.Options) (interface{}, error) {
+	return resilientCall(c.ctx, c.resilience, readMethods[method], func() (interface{}, error) {
+		return c.client.ExecuteKw(method, model, args, options)
+	})
+}
+
+// GetResUsers reads a user
+func (c *contextClient) GetResUsers(id int64) (*odoo.ResUsers, error) {
+	return resilientCall(c.ctx, c.resilience, true, func() (*odoo.ResUsers, error) {
+		return c.client.GetResUsers(id)
+	})
+}
+
+// DeadlineTransport bounds every HTTP request to Odoo, including requests
+// abandoned by their caller's context, which go-odoo cannot cancel itself
+type DeadlineTransport struct {
+	next    http.RoundTripper
+	timeout time.Duration
+}
+
+// NewDeadlineTransport creates a transport sending requests with next and
+// cancelling those that take longer than timeout
+func NewDeadlineTransport(next http.RoundTripper, timeout time.Duration) *DeadlineTransport {
+	return &DeadlineTransport{next: next, timeout: timeout}
+}
+
+// RoundTrip implements http.RoundTripper
+func (t *DeadlineTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, cancel := context.WithTimeout(req.Context(), t.timeout)
+	resp, err := t.next.RoundTrip(req.WithContext(ctx))
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	// The deadline also covers reading the body
+	resp.Body = &cancelOnClose{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+type cancelOnClose struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelOnClose) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
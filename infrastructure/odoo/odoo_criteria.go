@@ -0,0 +1,41 @@
+package odoo
+
+import (
+	"time"
+
+	odoo "github.com/skilld-labs/go-odoo"
+)
+
+// anyOf adds to criteria a condition matching records that satisfy any of
+// the criterions, in Odoo's prefix notation
+func anyOf(criteria *odoo.Criteria, criterions ...*odoo.Criterion) {
+	for range len(criterions) - 1 {
+		*criteria = append(*criteria, "|")
+	}
+	for _, criterion := range criterions {
+		criteria.AddCriterion(criterion)
+	}
+}
+
+// applyDatetimeRange restricts a datetime field to the days from and to,
+// both included and either optional. Days are in UTC, as Odoo stores
+// datetimes.
+func applyDatetimeRange(criteria *odoo.Criteria, field string, from, to *time.Time) {
+	if from != nil {
+		criteria.Add(field, ">=", from.Format(time.DateOnly)+" 00:00:00")
+	}
+	if to != nil {
+		criteria.Add(field, "<", to.AddDate(0, 0, 1).Format(time.DateOnly)+" 00:00:00")
+	}
+}
+
+// applyDateRange restricts a date field to the days from and to, both
+// included and either optional
+func applyDateRange(criteria *odoo.Criteria, field string, from, to *time.Time) {
+	if from != nil {
+		criteria.Add(field, ">=", from.Format(time.DateOnly))
+	}
+	if to != nil {
+		criteria.Add(field, "<=", to.Format(time.DateOnly))
+	}
+}
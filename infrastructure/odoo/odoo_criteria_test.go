@@ -0,0 +1,104 @@
+package odoo
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	odoo "github.com/skilld-labs/go-odoo"
+)
+
+// domain returns criteria as the plain list sent to Odoo
+func domain(criteria *odoo.Criteria) []interface{} {
+	return []interface{}(*criteria)
+}
+
+func condition(field, operator string, value interface{}) []interface{} {
+	return []interface{}{field, operator, value}
+}
+
+func date(value string) *time.Time {
+	parsed, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		panic(err)
+	}
+	return &parsed
+}
+
+func TestAnyOf(t *testing.T) {
+	tests := []struct {
+		name       string
+		criterions []*odoo.Criterion
+		want       []interface{}
+	}{
+		{
+			"one condition",
+			[]*odoo.Criterion{odoo.NewCriterion("name", "ilike", "S0")},
+			[]interface{}{condition("name", "ilike", "S0")},
+		},
+		{
+			"three conditions",
+			[]*odoo.Criterion{
+				odoo.NewCriterion("name", "ilike", "S0"),
+				odoo.NewCriterion("client_order_ref", "ilike", "S0"),
+				odoo.NewCriterion("partner_id.name", "ilike", "S0"),
+			},
+			[]interface{}{"|", "|",
+				condition("name", "ilike", "S0"),
+				condition("client_order_ref", "ilike", "S0"),
+				condition("partner_id.name", "ilike", "S0"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			criteria := odoo.NewCriteria().Add("state", "!=", "cancel")
+			anyOf(criteria, tt.criterions...)
+			want := append([]interface{}{condition("state", "!=", "cancel")}, tt.want...)
+			if got := domain(criteria); !reflect.DeepEqual(got, want) {
+				t.Errorf("domain is %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+func TestDateRanges(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to *time.Time
+		datetime []interface{}
+		date     []interface{}
+	}{
+		{"open", nil, nil, []interface{}{}, []interface{}{}},
+		{
+			"from only", date("2024-03-01"), nil,
+			[]interface{}{condition("field", ">=", "2024-03-01 00:00:00")},
+			[]interface{}{condition("field", ">=", "2024-03-01")},
+		},
+		{
+			// The last day is included up to its last second
+			"to only", nil, date("2024-03-31"),
+			[]interface{}{condition("field", "<", "2024-04-01 00:00:00")},
+			[]interface{}{condition("field", "<=", "2024-03-31")},
+		},
+		{
+			"single day across a year end", date("2024-12-31"), date("2024-12-31"),
+			[]interface{}{condition("field", ">=", "2024-12-31 00:00:00"), condition("field", "<", "2025-01-01 00:00:00")},
+			[]interface{}{condition("field", ">=", "2024-12-31"), condition("field", "<=", "2024-12-31")},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			criteria := odoo.NewCriteria()
+			applyDatetimeRange(criteria, "field", tt.from, tt.to)
+			if got := domain(criteria); !reflect.DeepEqual(got, tt.datetime) {
+				t.Errorf("datetime range is %v, want %v", got, tt.datetime)
+			}
+			criteria = odoo.NewCriteria()
+			applyDateRange(criteria, "field", tt.from, tt.to)
+			if got := domain(criteria); !reflect.DeepEqual(got, tt.date) {
+				t.Errorf("date range is %v, want %v", got, tt.date)
+			}
+		})
+	}
+}
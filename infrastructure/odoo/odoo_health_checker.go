@@ -0,0 +1,85 @@
+package odoo
+
+import (
+	"context"
+	"fmt"
+	"nerp_wrapper/domain/entity"
+	"time"
+
+	"github.com/kolo/xmlrpc"
+)
+
+// OdooHealthChecker probes an Odoo database the way a data request would:
+// the server must answer common.version and accept the admin credentials.
+// Probes bypass the circuit breaker, whose state is reported alongside.
+type OdooHealthChecker struct {
+	name       string
+	url        string
+	database   string
+	login      string
+	secret     string
+	resilience *Resilience
+}
+
+// NewOdooHealthChecker creates a new instance of OdooHealthChecker
+func NewOdooHealthChecker(tenantID, url, database, login, secret string, resilience *Resilience) *OdooHealthChecker {
+	return &OdooHealthChecker{
+		name:       "odoo:" + tenantID,
+		url:        url,
+		database:   database,
+		login:      login,
+		secret:     secret,
+		resilience: resilience,
+	}
+}
+
+// Name identifies the tenant's Odoo database in readiness reports
+func (h *OdooHealthChecker) Name() string {
+	return h.name
+}
+
+// CheckHealth calls common.version and authenticates the admin user. The
+// database is down while its breaker is open, as data requests fail fast.
+func (h *OdooHealthChecker) CheckHealth(ctx context.Context) entity.DependencyStatus {
+	start := time.Now()
+	breaker := h.resilience.State()
+	status := entity.DependencyStatus{Name: h.name, Status: entity.DependencyDown, Breaker: string(breaker)}
+
+	version, err := h.serverVersion(ctx)
+	if err == nil {
+		status.Version = version
+		status.UID, err = Authenticate(ctx, h.url, h.database, h.login, h.secret)
+	}
+	status.LatencyMs = time.Since(start).Milliseconds()
+	if err != nil {
+		status.Error = err.Error()
+		return status
+	}
+	if breaker == BreakerOpen {
+		status.Error = "circuit breaker is open"
+		return status
+	}
+
+	status.Status = entity.DependencyUp
+	return status
+}
+
+// serverVersion returns the server_version reported by common.version
+func (h *OdooHealthChecker) serverVersion(ctx context.Context) (string, error) {
+	common, err := xmlrpc.NewClient(h.url+"/xmlrpc/2/common", nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to create Odoo common client: %v", err)
+	}
+	defer common.Close()
+
+	reply, err := withContext(ctx, func() (map[string]interface{}, error) {
+		var reply map[string]interface{}
+		err := common.Call("version", nil, &reply)
+		return reply, err
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get Odoo version: %v", err)
+	}
+	version, _ := reply["server_version"].(string)
+	return version, nil
+}
@@ -1,6 +1,8 @@
 package odoo
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"nerp_wrapper/domain/entity"
 	"sort"
@@ -11,154 +13,131 @@ import (
 
 // OdooInvoiceRepository handles invoice operations with Odoo
 type OdooInvoiceRepository struct {
-	client *odoo.Client
+	clients    ClientProvider
+	masterData *MasterDataCache
 }
 
-// NewOdooInvoiceRepository creates a new instance of OdooInvoiceRepository
-func NewOdooInvoiceRepository(client *odoo.Client) *OdooInvoiceRepository {
-	return &OdooInvoiceRepository{client: client}
+// NewOdooInvoiceRepository creates a new instance of OdooInvoiceRepository.
+// masterData may be nil to read partners, journals and currencies uncached.
+func NewOdooInvoiceRepository(clients ClientProvider, masterData *MasterDataCache) *OdooInvoiceRepository {
+	return &OdooInvoiceRepository{clients: clients, masterData: masterData}
 }
 
-// GetAllInvoices retrieves invoices from Odoo with pagination
-func (r *OdooInvoiceRepository) GetAllInvoices(page, pageSize int) (*entity.InvoicePagination, error) {
-	if page < 1 {
-		page = 1
+// applyInvoiceListFilter translates the list filters into criteria.
+// Cancelled invoices are left out unless asked for by state.
+func applyInvoiceListFilter(criteria *odoo.Criteria, filter entity.InvoiceFilter) {
+	if len(filter.States) > 0 {
+		criteria.Add("state", "in", filter.States)
+	} else {
+		criteria.Add("state", "!=", "cancel")
 	}
-	if pageSize < 1 || pageSize > 500 {
-		pageSize = 500
+	if len(filter.MoveTypes) > 0 {
+		criteria.Add("move_type", "in", filter.MoveTypes)
 	}
+	if len(filter.PaymentStates) > 0 {
+		criteria.Add("payment_state", "in", filter.PaymentStates)
+	}
+	if filter.JournalID != 0 {
+		criteria.Add("journal_id", "=", filter.JournalID)
+	}
+	if filter.PartnerID != 0 {
+		criteria.Add("partner_id", "=", filter.PartnerID)
+	}
+	if filter.CurrencyID != 0 {
+		criteria.Add("currency_id", "=", filter.CurrencyID)
+	}
+	applyDateRange(criteria, "invoice_date", filter.InvoiceDateFrom, filter.InvoiceDateTo)
+	applyDateRange(criteria, "invoice_date_due", filter.DueDateFrom, filter.DueDateTo)
+}
 
-	offset := (page - 1) * pageSize
-
-	criteria := odoo.NewCriteria().Add("state", "!=", "cancel")
-	totalCount, err := r.client.Count("account.invoice.report", criteria, odoo.NewOptions())
+// GetAllInvoices retrieves invoices from Odoo with pagination
+func (r *OdooInvoiceRepository) GetAllInvoices(ctx context.Context, principal *entity.Principal, filter entity.InvoiceFilter, page, pageSize int) (*entity.InvoicePagination, error) {
+	client, err := clientFor(ctx, r.clients, principal)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get total count: %v", err)
+		return nil, err
 	}
-	totalPages := int((totalCount + int64(pageSize) - 1) / int64(pageSize))
 
-	searchOptions := odoo.NewOptions().
-		Limit(pageSize).
-		Offset(offset)
-
-	var invoices []odoo.AccountInvoiceReport
-	if err := r.client.SearchRead("account.invoice.report", criteria, searchOptions, &invoices); err != nil {
-		return nil, fmt.Errorf("failed to search invoices: %v", err)
+	if page < 1 {
+		page = 1
 	}
-
-	if len(invoices) == 0 {
-		return &entity.InvoicePagination{
-			Items:      []*entity.Invoice{},
-			Page:       page,
-			PageSize:   pageSize,
-			TotalItems: int(totalCount),
-			TotalPages: totalPages,
-		}, nil
+	// The caller caps the page size at the configured maximum
+	if pageSize < 1 {
+		pageSize = 1
 	}
 
-	// Collect related record IDs
-	partnerIDs := make(map[int64]bool)
-	journalIDs := make(map[int64]bool)
-	currencyIDs := make(map[int64]bool)
+	offset := (page - 1) * pageSize
 
-	for _, invoice := range invoices {
-		if invoice.PartnerId != nil {
-			partnerIDs[invoice.PartnerId.Get()] = true
+	criteria := odoo.NewCriteria()
+	applyInvoiceListFilter(criteria, filter)
+	applyCompanyFilter(criteria, filter.CompanyIDs)
+
+	// The count, the page of invoices and, once read, their partners,
+	// journals and currencies are loaded concurrently
+	loader := newBatchLoader(client)
+	var totalCount int64
+	loader.Go(func(client *contextClient) (err error) {
+		if totalCount, err = client.Count("account.invoice.report", criteria, odoo.NewOptions()); err != nil {
+			return fmt.Errorf("failed to get total count: %w", err)
 		}
-		if invoice.JournalId != nil {
-			journalIDs[invoice.JournalId.Get()] = true
-		}
-		if invoice.CurrencyId != nil {
-			currencyIDs[invoice.CurrencyId.Get()] = true
-		}
-	}
+		return nil
+	})
 
-	// Fetch partners
-	partners := make(map[int64]*odoo.ResPartner)
-	if len(partnerIDs) > 0 {
-		partnerIDsList := make([]int64, 0, len(partnerIDs))
-		for id := range partnerIDs {
-			partnerIDsList = append(partnerIDsList, id)
-		}
-		var partnerRecords []odoo.ResPartner
-		partnerOptions := odoo.NewOptions().FetchFields("id", "name", "vat", "phone", "mobile")
-		if err := r.client.Read("res.partner", partnerIDsList, partnerOptions, &partnerRecords); err == nil {
-			for i := range partnerRecords {
-				partners[partnerRecords[i].Id.Get()] = &partnerRecords[i]
-			}
+	var invoices []odoo.AccountInvoiceReport
+	var partners, journals, currencies map[int64]masterRecord
+	loader.Go(func(client *contextClient) error {
+		searchOptions := odoo.NewOptions().
+			Limit(pageSize).
+			Offset(offset)
+
+		if err := client.SearchRead("account.invoice.report", criteria, searchOptions, &invoices); err != nil && !errors.Is(err, odoo.ErrNotFound) {
+			return fmt.Errorf("failed to search invoices: %w", err)
 		}
-	}
 
-	// Fetch journals
-	journals := make(map[int64]*odoo.AccountJournal)
-	if len(journalIDs) > 0 {
-		journalIDsList := make([]int64, 0, len(journalIDs))
-		for id := range journalIDs {
-			journalIDsList = append(journalIDsList, id)
-		}
-		var journalRecords []odoo.AccountJournal
-		journalOptions := odoo.NewOptions().FetchFields("id", "name")
-		if err := r.client.Read("account.journal", journalIDsList, journalOptions, &journalRecords); err == nil {
-			for i := range journalRecords {
-				journals[journalRecords[i].Id.Get()] = &journalRecords[i]
+		var partnerIDs, journalIDs, currencyIDs relatedIDs
+		for _, invoice := range invoices {
+			if invoice.PartnerId != nil {
+				partnerIDs.Add(invoice.PartnerId.Get())
 			}
-		}
-	}
-
-	// Fetch currencies
-	currencies := make(map[int64]*odoo.ResCurrency)
-	if len(currencyIDs) > 0 {
-		currencyIDsList := make([]int64, 0, len(currencyIDs))
-		for id := range currencyIDs {
-			currencyIDsList = append(currencyIDsList, id)
-		}
-		var currencyRecords []odoo.ResCurrency
-		currencyOptions := odoo.NewOptions().FetchFields("id", "name")
-		if err := r.client.Read("res.currency", currencyIDsList, currencyOptions, &currencyRecords); err == nil {
-			for i := range currencyRecords {
-				currencies[currencyRecords[i].Id.Get()] = &currencyRecords[i]
+			if invoice.JournalId != nil {
+				journalIDs.Add(invoice.JournalId.Get())
+			}
+			if invoice.CurrencyId != nil {
+				currencyIDs.Add(invoice.CurrencyId.Get())
 			}
 		}
+		partners = loadMasterData(loader, r.masterData, principal.TenantID, "res.partner", partnerIDs)
+		journals = loadMasterData(loader, r.masterData, principal.TenantID, "account.journal", journalIDs)
+		currencies = loadMasterData(loader, r.masterData, principal.TenantID, "res.currency", currencyIDs)
+		return nil
+	})
+	if err := loader.Wait(); err != nil {
+		return nil, err
 	}
+	totalPages := int((totalCount + int64(pageSize) - 1) / int64(pageSize))
 
-	var result []*entity.Invoice
+	result := []*entity.Invoice{}
 	for _, invoice := range invoices {
 		var partnerName, partnerVat, partnerPhone, partnerMobile string
 		var partnerID int64
 		if invoice.PartnerId != nil {
 			partnerID = invoice.PartnerId.Get()
-			if partner, exists := partners[partnerID]; exists {
-				if partner.Name != nil {
-					partnerName = partner.Name.Get()
-				}
-				if partner.Vat != nil {
-					partnerVat = partner.Vat.Get()
-				}
-				if partner.Phone != nil {
-					partnerPhone = partner.Phone.Get()
-				}
-				if partner.Mobile != nil {
-					partnerMobile = partner.Mobile.Get()
-				}
-			}
+			partner := partners[partnerID]
+			partnerName, partnerVat = partner["name"], partner["vat"]
+			partnerPhone, partnerMobile = partner["phone"], partner["mobile"]
 		}
 
 		var journalID int64
 		var journalName string
 		if invoice.JournalId != nil {
 			journalID = invoice.JournalId.Get()
-			if journal, exists := journals[journalID]; exists && journal.Name != nil {
-				journalName = journal.Name.Get()
-			}
+			journalName = journals[journalID]["name"]
 		}
 
 		var currencyID int64
 		var currencyName string
 		if invoice.CurrencyId != nil {
 			currencyID = invoice.CurrencyId.Get()
-			if currency, exists := currencies[currencyID]; exists && currency.Name != nil {
-				currencyName = currency.Name.Get()
-			}
+			currencyName = currencies[currencyID]["name"]
 		}
 
 		inv := &entity.Invoice{
@@ -179,6 +158,8 @@ func (r *OdooInvoiceRepository) GetAllInvoices(page, pageSize int) (*entity.Invo
 			CurrencyName:   currencyName,
 		}
 
+		inv.CompanyID, inv.CompanyName = companyOf(invoice.CompanyId)
+
 		if invoice.State != nil {
 			if s, ok := invoice.State.Get().(string); ok {
 				inv.State = s
@@ -208,26 +189,34 @@ func (r *OdooInvoiceRepository) GetAllInvoices(page, pageSize int) (*entity.Invo
 		PageSize:   pageSize,
 		TotalItems: int(totalCount),
 		TotalPages: totalPages,
+		Warnings:   loader.Warnings(),
 	}, nil
 }
 
 // GetDailyInvoiceSummary retrieves daily invoice summary with pagination
-func (r *OdooInvoiceRepository) GetDailyInvoiceSummary(page, pageSize int) (*entity.InvoiceSummaryResponse, error) {
+func (r *OdooInvoiceRepository) GetDailyInvoiceSummary(ctx context.Context, principal *entity.Principal, filter entity.InvoiceFilter, page, pageSize int) (*entity.InvoiceSummaryResponse, error) {
+	client, err := clientFor(ctx, r.clients, principal)
+	if err != nil {
+		return nil, err
+	}
+
 	if page < 1 {
 		page = 1
 	}
-	if pageSize < 1 || pageSize > 500 {
-		pageSize = 500
+	// The caller caps the page size at the configured maximum
+	if pageSize < 1 {
+		pageSize = 1
 	}
 
 	offset := (page - 1) * pageSize
 
 	criteria := odoo.NewCriteria().
 		Add("state", "=", "posted")
+	applyCompanyFilter(criteria, filter.CompanyIDs)
 
-	totalCount, err := r.client.Count("account.invoice.report", criteria, odoo.NewOptions())
+	totalCount, err := client.Count("account.invoice.report", criteria, odoo.NewOptions())
 	if err != nil {
-		return nil, fmt.Errorf("failed to get total count: %v", err)
+		return nil, fmt.Errorf("failed to get total count: %w", err)
 	}
 
 	totalPages := int((totalCount + int64(pageSize) - 1) / int64(pageSize))
@@ -237,8 +226,8 @@ func (r *OdooInvoiceRepository) GetDailyInvoiceSummary(page, pageSize int) (*ent
 		Offset(offset)
 
 	var records []odoo.AccountInvoiceReport
-	if err := r.client.SearchRead("account.invoice.report", criteria, searchOptions, &records); err != nil {
-		return nil, fmt.Errorf("failed to search invoices: %v", err)
+	if err := client.SearchRead("account.invoice.report", criteria, searchOptions, &records); err != nil && !errors.Is(err, odoo.ErrNotFound) {
+		return nil, fmt.Errorf("failed to search invoices: %w", err)
 	}
 
 	if len(records) == 0 {
@@ -297,122 +286,89 @@ func (r *OdooInvoiceRepository) GetDailyInvoiceSummary(page, pageSize int) (*ent
 	}, nil
 }
 
-// GetPeriodInvoiceSummary retrieves invoice summary for a specific period
-func (r *OdooInvoiceRepository) GetPeriodInvoiceSummary(periodType entity.PeriodType, customStartDate, customEndDate *time.Time) (*entity.PeriodInvoiceSummaryResponse, error) {
-	now := time.Now()
-	var startDate, endDate time.Time
-
-	if customStartDate != nil && customEndDate != nil {
-		startDate = *customStartDate
-		endDate = *customEndDate
-	} else {
-		endDate = now
-		switch periodType {
-		case entity.PeriodTypeDay:
-			startDate = now.AddDate(0, 0, -1)
-		case entity.PeriodTypeWeek:
-			startDate = now.AddDate(0, 0, -7)
-		case entity.PeriodTypeMonth:
-			startDate = now.AddDate(0, 0, -30)
-		case entity.PeriodTypeQuarter:
-			startDate = now.AddDate(0, 0, -90)
-		case entity.PeriodTypeMonthly:
-			startDate = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
-			endDate = startDate.AddDate(0, 1, 0).Add(-time.Second)
-		case entity.PeriodTypeYearly:
-			startDate = time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location())
-			endDate = startDate.AddDate(1, 0, 0).Add(-time.Second)
-		default:
-			startDate = now.AddDate(0, 0, -30)
-		}
+// GetPeriodInvoiceSummary retrieves invoice summary for a specific period.
+// Odoo sums the invoices per day with read_group; the invoices themselves
+// are only read when filter.IncludeItems is set.
+func (r *OdooInvoiceRepository) GetPeriodInvoiceSummary(ctx context.Context, principal *entity.Principal, filter entity.InvoiceFilter, periodType entity.PeriodType, customStartDate, customEndDate *time.Time) (*entity.PeriodInvoiceSummaryResponse, error) {
+	client, err := clientFor(ctx, r.clients, principal)
+	if err != nil {
+		return nil, err
 	}
 
+	startDate, endDate := periodType.DateRange(time.Now(), customStartDate, customEndDate)
+
 	criteria := odoo.NewCriteria().
 		Add("state", "=", "posted").
 		Add("invoice_date", ">=", startDate.Format("2006-01-02")).
 		Add("invoice_date", "<=", endDate.Format("2006-01-02"))
+	applyCompanyFilter(criteria, filter.CompanyIDs)
 
-	searchOptions := odoo.NewOptions().
-		FetchFields("id", "display_name", "invoice_date", "price_total")
-
-	var records []odoo.AccountInvoiceReport
-	if err := r.client.SearchRead("account.invoice.report", criteria, searchOptions, &records); err != nil {
-		return nil, fmt.Errorf("failed to search invoices: %v", err)
+	totals, err := readDailyTotals(client, "account.invoice.report", criteria, "invoice_date", "price_total", filter.Consolidated)
+	if err != nil {
+		return nil, err
 	}
+	days, totalAmount, totalInvoices := sumDailyTotals(totals)
 
+	summaries := make([]entity.DailyInvoiceSummary, len(days))
 	dateMap := make(map[string]*entity.DailyInvoiceSummary)
-	var totalAmount float64
-	var totalInvoices int
+	for i, day := range days {
+		summaries[i] = entity.DailyInvoiceSummary{Date: day.Day, TotalAmount: day.Amount, InvoiceCount: day.Count}
+		dateMap[day.Day.Format("2006-01-02")] = &summaries[i]
+	}
 
-	for _, invoice := range records {
-		invoiceDate := invoice.Date.Get()
-		dateStr := invoiceDate.Format("2006-01-02")
+	if filter.IncludeItems && totalInvoices > 0 {
+		searchOptions := odoo.NewOptions().
+			FetchFields("id", "display_name", "invoice_date", "price_total")
 
-		if _, exists := dateMap[dateStr]; !exists {
-			dateMap[dateStr] = &entity.DailyInvoiceSummary{
-				Date:         invoiceDate.Truncate(24 * time.Hour),
-				TotalAmount:  0,
-				InvoiceCount: 0,
-				Invoices:     []entity.InvoiceSummary{},
-			}
+		var records []invoiceReportLine
+		if err := client.SearchRead("account.invoice.report", criteria, searchOptions, &records); err != nil && !errors.Is(err, odoo.ErrNotFound) {
+			return nil, fmt.Errorf("failed to search invoices: %w", err)
 		}
-
-		summary := dateMap[dateStr]
-		amount := invoice.PriceTotal.Get()
-		summary.TotalAmount += amount
-		summary.InvoiceCount++
-		totalAmount += amount
-		totalInvoices++
-
-		invoiceSummary := entity.InvoiceSummary{
-			InvoiceNumber: summary.InvoiceCount,
-			InvoiceID:     invoice.Id.Get(),
-			InvoiceName:   invoice.DisplayName.Get(),
-			AmountTotal:   amount,
-			DateInvoice:   invoiceDate,
+		for _, invoice := range records {
+			invoiceDate := invoice.InvoiceDate.Get()
+			summary, exists := dateMap[invoiceDate.Format("2006-01-02")]
+			if !exists {
+				continue
+			}
+			summary.Invoices = append(summary.Invoices, entity.InvoiceSummary{
+				InvoiceNumber: len(summary.Invoices) + 1,
+				InvoiceID:     invoice.Id.Get(),
+				InvoiceName:   invoice.DisplayName.Get(),
+				AmountTotal:   invoice.PriceTotal.Get(),
+				DateInvoice:   invoiceDate,
+			})
 		}
-		summary.Invoices = append(summary.Invoices, invoiceSummary)
-	}
-
-	var summaries []entity.DailyInvoiceSummary
-	for _, summary := range dateMap {
-		summaries = append(summaries, *summary)
-	}
-
-	sort.Slice(summaries, func(i, j int) bool {
-		return summaries[i].Date.After(summaries[j].Date)
-	})
-
-	days := endDate.Sub(startDate).Hours() / 24
-	if days < 1 {
-		days = 1
 	}
 
-	var periodDesc string
-	switch periodType {
-	case entity.PeriodTypeDay:
-		periodDesc = "Last 24 Hours"
-	case entity.PeriodTypeWeek:
-		periodDesc = "Last 7 Days"
-	case entity.PeriodTypeMonth:
-		periodDesc = "Last 30 Days"
-	case entity.PeriodTypeQuarter:
-		periodDesc = "Last 90 Days"
-	case entity.PeriodTypeMonthly:
-		periodDesc = endDate.Format("January 2006")
-	case entity.PeriodTypeYearly:
-		periodDesc = endDate.Format("2006")
-	default:
-		periodDesc = fmt.Sprintf("%s to %s", startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
+	var companies []entity.CompanyInvoiceSummary
+	if filter.Consolidated {
+		for _, company := range sumCompanyTotals(totals) {
+			companies = append(companies, entity.CompanyInvoiceSummary{
+				CompanyID:    company.CompanyID,
+				CompanyName:  company.CompanyName,
+				TotalAmount:  company.Amount,
+				InvoiceCount: company.Count,
+			})
+		}
 	}
 
 	return &entity.PeriodInvoiceSummaryResponse{
-		Period:       periodDesc,
+		Period:       periodType.Describe(startDate, endDate),
 		PeriodType:   periodType,
 		DateRange:    entity.DateRange{StartDate: startDate, EndDate: endDate},
 		Items:        summaries,
 		TotalAmount:  totalAmount,
 		InvoiceCount: totalInvoices,
-		AverageDaily: totalAmount / days,
+		AverageDaily: totalAmount / entity.PeriodDays(startDate, endDate),
+		Companies:    companies,
 	}, nil
 }
+
+// invoiceReportLine is an account.invoice.report line with the invoice date
+// the period summary groups by, which go-odoo's model lacks
+type invoiceReportLine struct {
+	Id          *odoo.Int    `xmlrpc:"id,omitempty"`
+	DisplayName *odoo.String `xmlrpc:"display_name,omitempty"`
+	InvoiceDate *odoo.Time   `xmlrpc:"invoice_date,omitempty"`
+	PriceTotal  *odoo.Float  `xmlrpc:"price_total,omitempty"`
+}
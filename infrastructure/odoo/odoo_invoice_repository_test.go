@@ -0,0 +1,60 @@
+package odoo
+
+import (
+	"nerp_wrapper/domain/entity"
+	"reflect"
+	"testing"
+
+	odoo "github.com/skilld-labs/go-odoo"
+)
+
+func TestApplyInvoiceListFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter entity.InvoiceFilter
+		want   []interface{}
+	}{
+		{"no filters", entity.InvoiceFilter{}, []interface{}{condition("state", "!=", "cancel")}},
+		{
+			"states replace the cancel exclusion",
+			entity.InvoiceFilter{States: []string{"draft", "cancel"}},
+			[]interface{}{condition("state", "in", []string{"draft", "cancel"})},
+		},
+		{
+			"every filter",
+			entity.InvoiceFilter{
+				MoveTypes:       []string{"out_invoice", "out_refund"},
+				PaymentStates:   []string{"not_paid"},
+				JournalID:       1,
+				PartnerID:       9,
+				CurrencyID:      2,
+				InvoiceDateFrom: date("2024-03-01"),
+				InvoiceDateTo:   date("2024-03-31"),
+				DueDateFrom:     date("2024-04-01"),
+				DueDateTo:       date("2024-04-30"),
+			},
+			[]interface{}{
+				condition("state", "!=", "cancel"),
+				condition("move_type", "in", []string{"out_invoice", "out_refund"}),
+				condition("payment_state", "in", []string{"not_paid"}),
+				condition("journal_id", "=", int64(1)),
+				condition("partner_id", "=", int64(9)),
+				condition("currency_id", "=", int64(2)),
+				// Invoice and due dates are date fields, so the last day is compared as is
+				condition("invoice_date", ">=", "2024-03-01"),
+				condition("invoice_date", "<=", "2024-03-31"),
+				condition("invoice_date_due", ">=", "2024-04-01"),
+				condition("invoice_date_due", "<=", "2024-04-30"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			criteria := odoo.NewCriteria()
+			applyInvoiceListFilter(criteria, tt.filter)
+			if got := domain(criteria); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("domain is %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
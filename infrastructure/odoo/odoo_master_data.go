@@ -0,0 +1,314 @@
+package odoo
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"nerp_wrapper/domain/entity"
+	"nerp_wrapper/domain/repository"
+	"slices"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	odoo "github.com/skilld-labs/go-odoo"
+)
+
+const (
+	odooDatetimeLayout = "2006-01-02 15:04:05"
+
+	// writeDateSlack widens every write_date check, covering clock skew
+	// between the wrapper and Odoo and writes committed late by long
+	// transactions
+	writeDateSlack = time.Minute
+)
+
+// masterDataFields lists the fields cached per master data model
+var masterDataFields = map[string][]string{
+	"res.partner":     {"name", "vat", "phone", "mobile"},
+	"res.users":       {"name"},
+	"account.journal": {"name"},
+	"res.currency":    {"name"},
+}
+
+// masterRecord holds the cached fields of a master data record, with false
+// values read as empty strings
+type masterRecord map[string]string
+
+// MasterDataCache caches the partners, users, journals and currencies lists
+// refer to, per tenant, in a CacheStore that may be shared by several
+// wrapper instances. Entries expire after ttl. At most once per revalidate
+// interval and model, the records written in Odoo since the last check are
+// looked up by write_date and their entries dropped, so changes show within
+// that interval rather than the ttl.
+type MasterDataCache struct {
+	store      repository.CacheStore
+	ttl        time.Duration
+	revalidate time.Duration
+
+	mu     sync.Mutex
+	models map[string]*masterDataModel
+}
+
+// masterDataModel tracks the entries and statistics of one model of a tenant
+type masterDataModel struct {
+	tenant string
+	model  string
+
+	// sweepMu serializes revalidations; watermark and checkedAt are
+	// guarded by it
+	sweepMu   sync.Mutex
+	watermark time.Time
+	checkedAt time.Time
+
+	hits          atomic.Int64
+	misses        atomic.Int64
+	invalidations atomic.Int64
+	errors        atomic.Int64
+}
+
+// NewMasterDataCache creates a new instance of MasterDataCache
+func NewMasterDataCache(store repository.CacheStore, ttl, revalidate time.Duration) *MasterDataCache {
+	return &MasterDataCache{
+		store:      store,
+		ttl:        ttl,
+		revalidate: revalidate,
+		models:     make(map[string]*masterDataModel),
+	}
+}
+
+// CacheStats reports the statistics of every model of every tenant, ordered
+// by tenant and model
+func (c *MasterDataCache) CacheStats() []entity.CacheStats {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	models := make([]*masterDataModel, 0, len(c.models))
+	for _, m := range c.models {
+		models = append(models, m)
+	}
+	c.mu.Unlock()
+
+	sort.Slice(models, func(i, j int) bool {
+		if models[i].tenant != models[j].tenant {
+			return models[i].tenant < models[j].tenant
+		}
+		return models[i].model < models[j].model
+	})
+
+	stats := make([]entity.CacheStats, 0, len(models))
+	for _, m := range models {
+		stat := entity.CacheStats{
+			Cache:         "master_data",
+			Tenant:        m.tenant,
+			Kind:          m.model,
+			Hits:          m.hits.Load(),
+			Misses:        m.misses.Load(),
+			Invalidations: m.invalidations.Load(),
+			Errors:        m.errors.Load(),
+		}
+		if lookups := stat.Hits + stat.Misses; lookups > 0 {
+			stat.HitRatio = float64(stat.Hits) / float64(lookups)
+		}
+		stats = append(stats, stat)
+	}
+	return stats
+}
+
+func (c *MasterDataCache) model(tenant, model string) *masterDataModel {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	key := tenant + "/" + model
+	m, exists := c.models[key]
+	if !exists {
+		// Entries of a shared store may have been cached by another instance
+		// up to ttl ago, so the first check looks that far back
+		m = &masterDataModel{tenant: tenant, model: model, watermark: time.Now().Add(-c.ttl)}
+		c.models[key] = m
+	}
+	return m
+}
+
+func masterDataKey(tenant, model string, id int64) string {
+	return fmt.Sprintf("nerp:md:%s:%s:%d", tenant, model, id)
+}
+
+// revalidateModel drops the entries of the records of m written since the
+// last check, unless one ran within the revalidate interval
+func (c *MasterDataCache) revalidateModel(client *contextClient, m *masterDataModel) error {
+	m.sweepMu.Lock()
+	defer m.sweepMu.Unlock()
+
+	startedAt := time.Now()
+	if startedAt.Sub(m.checkedAt) < c.revalidate {
+		return nil
+	}
+
+	since := m.watermark.Add(-writeDateSlack).UTC().Format(odooDatetimeLayout)
+	criteria := odoo.NewCriteria().Add("write_date", ">=", since)
+	// Archived records are revalidated too, as lists still refer to them
+	options := odoo.NewOptions().Add("context", map[string]interface{}{"active_test": false})
+	ids, err := client.Search(m.model, criteria, options)
+	if err != nil && !errors.Is(err, odoo.ErrNotFound) {
+		return fmt.Errorf("failed to search changed %s: %w", m.model, err)
+	}
+
+	for batch := range slices.Chunk(ids, relatedBatchSize) {
+		keys := make([]string, len(batch))
+		for i, id := range batch {
+			keys[i] = masterDataKey(m.tenant, m.model, id)
+		}
+		if err := c.store.Delete(client.ctx, keys...); err != nil {
+			m.errors.Add(1)
+			return fmt.Errorf("failed to drop changed %s: %w", m.model, err)
+		}
+	}
+	m.invalidations.Add(int64(len(ids)))
+	m.watermark = startedAt
+	m.checkedAt = startedAt
+	return nil
+}
+
+// lookup returns the cached records of m among ids. It returns false when
+// the cache must be bypassed, because the entries could not be revalidated.
+func (c *MasterDataCache) lookup(client *contextClient, m *masterDataModel, ids []int64) (map[int64]masterRecord, bool) {
+	if err := c.revalidateModel(client, m); err != nil {
+		log.Printf("Bypassing master data cache for %s of tenant %s: %v", m.model, m.tenant, err)
+		return nil, false
+	}
+
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		keys[i] = masterDataKey(m.tenant, m.model, id)
+	}
+	values, err := c.store.GetMany(client.ctx, keys)
+	if err != nil {
+		m.errors.Add(1)
+		log.Printf("Failed to read master data cache for %s of tenant %s: %v", m.model, m.tenant, err)
+		values = nil
+	}
+
+	records := make(map[int64]masterRecord, len(values))
+	for i, id := range ids {
+		value, exists := values[keys[i]]
+		if !exists {
+			continue
+		}
+		var record masterRecord
+		if err := json.Unmarshal(value, &record); err != nil {
+			m.errors.Add(1)
+			continue
+		}
+		records[id] = record
+	}
+	m.hits.Add(int64(len(records)))
+	m.misses.Add(int64(len(ids) - len(records)))
+	return records, true
+}
+
+// put caches records read from Odoo
+func (c *MasterDataCache) put(ctx context.Context, m *masterDataModel, records map[int64]masterRecord) {
+	values := make(map[string][]byte, len(records))
+	for id, record := range records {
+		value, err := json.Marshal(record)
+		if err != nil {
+			continue
+		}
+		values[masterDataKey(m.tenant, m.model, id)] = value
+	}
+	if err := c.store.SetMany(ctx, values, c.ttl); err != nil {
+		m.errors.Add(1)
+		log.Printf("Failed to write master data cache for %s of tenant %s: %v", m.model, m.tenant, err)
+	}
+}
+
+// loadMasterData reads the master data records of model with the given IDs
+// on the loader, taking those it can from cache, which may be nil, and
+// reading the others relatedBatchSize IDs per call, joined with the reads of
+// concurrent operations. The map is filled once the loader's Wait has returned.
+func loadMasterData(l *batchLoader, cache *MasterDataCache, tenant, model string, ids relatedIDs) map[int64]masterRecord {
+	records := make(map[int64]masterRecord, len(ids.ids))
+	if len(ids.ids) == 0 {
+		return records
+	}
+	var mu sync.Mutex
+	read := func(missing []int64, m *masterDataModel) {
+		for batch := range slices.Chunk(missing, relatedBatchSize) {
+			l.goRelated(model, batch, func(client *contextClient) error {
+				page, err := masterDataReads.read(client, tenant, model, batch)
+				if err != nil {
+					return err
+				}
+				if m != nil {
+					cache.put(client.ctx, m, page)
+				}
+				mu.Lock()
+				defer mu.Unlock()
+				for id, record := range page {
+					records[id] = record
+				}
+				return nil
+			})
+		}
+	}
+
+	if cache == nil {
+		read(ids.ids, nil)
+		return records
+	}
+
+	m := cache.model(tenant, model)
+	l.goRelated(model, ids.ids, func(client *contextClient) error {
+		cached, ok := cache.lookup(client, m, ids.ids)
+		if !ok {
+			read(ids.ids, nil)
+			return nil
+		}
+
+		missing := make([]int64, 0, len(ids.ids)-len(cached))
+		for _, id := range ids.ids {
+			if _, exists := cached[id]; !exists {
+				missing = append(missing, id)
+			}
+		}
+		mu.Lock()
+		for id, record := range cached {
+			records[id] = record
+		}
+		mu.Unlock()
+		read(missing, m)
+		return nil
+	})
+	return records
+}
+
+// readMasterData reads the cached fields of the records of model with the
+// given IDs
+func readMasterData(client *contextClient, model string, ids []int64) (map[int64]masterRecord, error) {
+	fields := masterDataFields[model]
+	resp, err := client.ExecuteKw("read", model, []interface{}{ids}, odoo.NewOptions().FetchFields(append([]string{"id"}, fields...)...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", model, err)
+	}
+	rows, _ := resp.([]interface{})
+
+	records := make(map[int64]masterRecord, len(rows))
+	for _, item := range rows {
+		row, _ := item.(map[string]interface{})
+		id, _ := row["id"].(int64)
+		if id == 0 {
+			continue
+		}
+		record := make(masterRecord, len(fields))
+		for _, field := range fields {
+			// Empty fields are read as false
+			record[field], _ = row[field].(string)
+		}
+		records[id] = record
+	}
+	return records, nil
+}
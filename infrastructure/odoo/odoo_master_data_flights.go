@@ -0,0 +1,97 @@
+package odoo
+
+import (
+	"maps"
+	"sync"
+)
+
+// readFlights coalesces concurrent reads of the same master data records
+// across operations. A record already being read for one operation is waited
+// for by the others rather than read again. Like cached entries, records
+// read with one user's client are shared with the tenant's other users; a
+// failed read is not, as the failure may come from the first caller's access
+// rights, so the operations waiting on it read the record themselves.
+type readFlights struct {
+	mu      sync.Mutex
+	flights map[string]*readFlight
+}
+
+// readFlight is the read of one record in progress
+type readFlight struct {
+	done   chan struct{}
+	record masterRecord // nil when Odoo returned no such record
+	failed bool
+}
+
+// masterDataReads coalesces the master data reads of every tenant
+var masterDataReads = &readFlights{flights: make(map[string]*readFlight)}
+
+// read reads the master data records of model with the given IDs, joining
+// the reads of those already in flight
+func (f *readFlights) read(client *contextClient, tenant, model string, ids []int64) (map[int64]masterRecord, error) {
+	var own []int64
+	led := make(map[int64]*readFlight)
+	joined := make(map[int64]*readFlight)
+	f.mu.Lock()
+	for _, id := range ids {
+		key := masterDataKey(tenant, model, id)
+		if flight, exists := f.flights[key]; exists {
+			joined[id] = flight
+			continue
+		}
+		flight := &readFlight{done: make(chan struct{})}
+		f.flights[key] = flight
+		led[id] = flight
+		own = append(own, id)
+	}
+	f.mu.Unlock()
+
+	// Own reads complete before joined ones are waited for, so operations
+	// waiting on each other's records cannot deadlock
+	records, err := f.lead(client, tenant, model, own, led)
+	if err != nil {
+		return nil, err
+	}
+
+	var retry []int64
+	for id, flight := range joined {
+		select {
+		case <-flight.done:
+		case <-client.ctx.Done():
+			return nil, contextError(client.ctx.Err())
+		}
+		switch {
+		case flight.failed:
+			retry = append(retry, id)
+		case flight.record != nil:
+			records[id] = flight.record
+		}
+	}
+	if len(retry) > 0 {
+		page, err := readMasterData(client, model, retry)
+		if err != nil {
+			return nil, err
+		}
+		maps.Copy(records, page)
+	}
+	return records, nil
+}
+
+// lead reads the records whose flights the caller started and completes
+// those flights
+func (f *readFlights) lead(client *contextClient, tenant, model string, ids []int64, flights map[int64]*readFlight) (map[int64]masterRecord, error) {
+	records := make(map[int64]masterRecord, len(ids))
+	var err error
+	if len(ids) > 0 {
+		records, err = readMasterData(client, model, ids)
+	}
+
+	f.mu.Lock()
+	for id, flight := range flights {
+		delete(f.flights, masterDataKey(tenant, model, id))
+		flight.record, flight.failed = records[id], err != nil
+		close(flight.done)
+	}
+	f.mu.Unlock()
+	return records, err
+}
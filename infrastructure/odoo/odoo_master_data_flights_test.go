@@ -0,0 +1,67 @@
+package odoo
+
+import (
+	"context"
+	"nerp_wrapper/infrastructure/memory"
+	"nerp_wrapper/infrastructure/odoo/odootest"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	odoo "github.com/skilld-labs/go-odoo"
+)
+
+func TestConcurrentMasterDataReadsAreCoalesced(t *testing.T) {
+	fixtures, err := memory.LoadFixtures(filepath.Join("..", "..", "fixtures"))
+	if err != nil {
+		t.Fatalf("failed to load fixtures: %v", err)
+	}
+	fake := odootest.NewServer("nerp")
+	fake.Seed(fixtures)
+	odooServer := httptest.NewServer(fake)
+	defer odooServer.Close()
+
+	// Each operation reads with a client of its own, as two users would
+	newClient := func() *contextClient {
+		client, err := odoo.NewClient(&odoo.ClientConfig{Admin: "admin", Password: "admin", Database: "nerp", URL: odooServer.URL})
+		if err != nil {
+			t.Fatalf("failed to create Odoo client: %v", err)
+		}
+		t.Cleanup(client.Close)
+		return &contextClient{ctx: context.Background(), client: client}
+	}
+	first, second := newClient(), newClient()
+	fake.SetDelay(200 * time.Millisecond)
+
+	var wg sync.WaitGroup
+	results := make([]map[int64]masterRecord, 2)
+	errs := make([]error, 2)
+	for i, client := range []*contextClient{first, second} {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = masterDataReads.read(client, "default", "res.partner", []int64{9, 10})
+		}()
+		// The second read starts while the first is in flight
+		time.Sleep(50 * time.Millisecond)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("read %d failed: %v", i+1, err)
+		}
+	}
+	if len(results[0]) != 2 || results[0][9]["name"] == "" {
+		t.Errorf("first read returned %v, want partners 9 and 10", results[0])
+	}
+	if !reflect.DeepEqual(results[0], results[1]) {
+		t.Errorf("reads returned %v and %v", results[0], results[1])
+	}
+	if calls := fake.CallCount("res.partner", "read"); calls != 1 {
+		t.Errorf("Odoo answered %d reads of res.partner, want 1", calls)
+	}
+}
@@ -0,0 +1,135 @@
+package odoo
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	odoo "github.com/skilld-labs/go-odoo"
+)
+
+// dayTotal is the sum and count of the records of one day, and of one
+// company within that day when grouped by company too
+type dayTotal struct {
+	Day         time.Time
+	CompanyID   int64
+	CompanyName string
+	Amount      float64
+	Count       int
+}
+
+// readDailyTotals sums amountField over the records matching criteria per
+// day of dateField with read_group, so Odoo aggregates without sending every
+// record. Days are in UTC, as go-odoo reads dates and datetimes.
+func readDailyTotals(client *contextClient, model string, criteria *odoo.Criteria, dateField, amountField string, byCompany bool) ([]dayTotal, error) {
+	dayGroup := dateField + ":day"
+	groupby := []string{dayGroup}
+	if byCompany {
+		groupby = append(groupby, "company_id")
+	}
+
+	resp, err := client.ExecuteKw("read_group", model,
+		[]interface{}{*criteria, []string{amountField + ":sum"}, groupby},
+		odoo.NewOptions().Add("lazy", false))
+	if err != nil {
+		return nil, fmt.Errorf("failed to group %s: %w", model, err)
+	}
+	rows, _ := resp.([]interface{})
+
+	totals := make([]dayTotal, 0, len(rows))
+	for _, item := range rows {
+		row, _ := item.(map[string]interface{})
+		day, err := groupDay(row, dayGroup)
+		if err != nil {
+			return nil, fmt.Errorf("failed to group %s: %w", model, err)
+		}
+		total := dayTotal{Day: day}
+		total.Amount, _ = row[amountField].(float64)
+		if count, ok := row["__count"].(int64); ok {
+			total.Count = int(count)
+		}
+		if byCompany {
+			total.CompanyID, total.CompanyName = many2OneValue(row["company_id"])
+		}
+		totals = append(totals, total)
+	}
+	return totals, nil
+}
+
+// groupDay returns the first day of a read_group row from the bounds Odoo
+// reports in __range, as the group label depends on the user's language
+func groupDay(row map[string]interface{}, dayGroup string) (time.Time, error) {
+	ranges, _ := row["__range"].(map[string]interface{})
+	bounds, _ := ranges[dayGroup].(map[string]interface{})
+	from, _ := bounds["from"].(string)
+	for _, layout := range []string{time.DateTime, time.DateOnly} {
+		if day, err := time.Parse(layout, from); err == nil {
+			return day.Truncate(24 * time.Hour), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("read_group row has no %s range", dayGroup)
+}
+
+// many2OneValue returns the ID and name of a many2one value as returned by
+// read_group, [id, name] or false
+func many2OneValue(value interface{}) (int64, string) {
+	pair, _ := value.([]interface{})
+	if len(pair) != 2 {
+		return 0, ""
+	}
+	id, _ := pair[0].(int64)
+	name, _ := pair[1].(string)
+	return id, name
+}
+
+// sumDailyTotals folds per day and company totals into one total per day,
+// newest day first, with the grand total and count
+func sumDailyTotals(totals []dayTotal) ([]dayTotal, float64, int) {
+	byDay := make(map[time.Time]*dayTotal)
+	var amount float64
+	var count int
+	for _, total := range totals {
+		day, exists := byDay[total.Day]
+		if !exists {
+			day = &dayTotal{Day: total.Day}
+			byDay[total.Day] = day
+		}
+		day.Amount += total.Amount
+		day.Count += total.Count
+		amount += total.Amount
+		count += total.Count
+	}
+
+	days := make([]dayTotal, 0, len(byDay))
+	for _, day := range byDay {
+		days = append(days, *day)
+	}
+	sort.Slice(days, func(i, j int) bool {
+		return days[i].Day.After(days[j].Day)
+	})
+	return days, amount, count
+}
+
+// sumCompanyTotals folds per day and company totals into one total per
+// company, ordered by company ID
+func sumCompanyTotals(totals []dayTotal) []dayTotal {
+	byCompany := make(map[int64]*dayTotal)
+	for _, total := range totals {
+		company, exists := byCompany[total.CompanyID]
+		if !exists {
+			company = &dayTotal{CompanyID: total.CompanyID, CompanyName: total.CompanyName}
+			byCompany[total.CompanyID] = company
+		}
+		company.Amount += total.Amount
+		company.Count += total.Count
+	}
+
+	companies := make([]dayTotal, 0, len(byCompany))
+	for _, company := range byCompany {
+		companies = append(companies, *company)
+	}
+	sort.Slice(companies, func(i, j int) bool {
+		return companies[i].CompanyID < companies[j].CompanyID
+	})
+	return companies
+}
@@ -0,0 +1,191 @@
+package odoo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"nerp_wrapper/domain/repository"
+	"net"
+	"net/rpc"
+	"strings"
+	"sync"
+	"time"
+)
+
+// BreakerState is the state of a circuit breaker
+type BreakerState string
+
+const (
+	BreakerClosed   BreakerState = "closed"    // Calls go through
+	BreakerOpen     BreakerState = "open"      // Calls fail fast until the cooldown ends
+	BreakerHalfOpen BreakerState = "half_open" // One probe call decides whether to close again
+)
+
+// ResilienceConfig holds the retry and circuit breaker settings of an Odoo instance
+type ResilienceConfig struct {
+	MaxAttempts      int           // Attempts of an idempotent call, including the first
+	BaseDelay        time.Duration // Backoff ceiling before the first retry, doubled per retry
+	MaxDelay         time.Duration // Backoff ceiling of any retry
+	FailureThreshold int           // Consecutive failures opening the breaker
+	Cooldown         time.Duration // Time the breaker stays open before a probe call
+}
+
+// Resilience retries transient failures of idempotent Odoo calls with
+// jittered exponential backoff and trips a circuit breaker after
+// consecutive failures, so a failing Odoo is not hammered by every request
+type Resilience struct {
+	name   string
+	config ResilienceConfig
+
+	mu       sync.Mutex
+	state    BreakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// NewResilience creates the resilience layer of the Odoo instance called name
+func NewResilience(name string, config ResilienceConfig) *Resilience {
+	if config.MaxAttempts < 1 {
+		config.MaxAttempts = 1
+	}
+	return &Resilience{name: name, config: config, state: BreakerClosed}
+}
+
+// State returns the current breaker state
+func (r *Resilience) State() BreakerState {
+	if r == nil {
+		return BreakerClosed
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.state == BreakerOpen && time.Since(r.openedAt) >= r.config.Cooldown {
+		return BreakerHalfOpen
+	}
+	return r.state
+}
+
+// resilientCall runs request under ctx through r. Idempotent requests
+// failing with a transient error are retried; while the breaker is open
+// requests fail fast with ErrBackendUnavailable, as do transient failures once
+// retries run out. A nil Resilience runs the request once. The result is
+// handed back by the attempt that completed in time, never written by an
+// attempt the caller stopped waiting for.
+func resilientCall[T any](ctx context.Context, r *Resilience, idempotent bool, request func() (T, error)) (T, error) {
+	if r == nil {
+		return withContext(ctx, request)
+	}
+
+	var zero T
+	attempts := 1
+	if idempotent {
+		attempts = r.config.MaxAttempts
+	}
+	for attempt := 1; ; attempt++ {
+		if err := r.allow(); err != nil {
+			return zero, err
+		}
+		value, err := withContext(ctx, request)
+		r.record(err)
+		if err == nil || !isTransient(err) {
+			return value, err
+		}
+		if attempt >= attempts {
+			return zero, fmt.Errorf("%w: %v", repository.ErrBackendUnavailable, err)
+		}
+
+		select {
+		case <-time.After(r.backoff(attempt)):
+		case <-ctx.Done():
+			return zero, contextError(ctx.Err())
+		}
+	}
+}
+
+// allow reports whether a call may go through, letting a single probe call
+// through once an open breaker has cooled down
+func (r *Resilience) allow() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	switch r.state {
+	case BreakerOpen:
+		if time.Since(r.openedAt) < r.config.Cooldown {
+			return fmt.Errorf("%w: circuit breaker for %s is open", repository.ErrBackendUnavailable, r.name)
+		}
+		r.state = BreakerHalfOpen
+		r.probing = true
+	case BreakerHalfOpen:
+		if r.probing {
+			return fmt.Errorf("%w: circuit breaker for %s is half open", repository.ErrBackendUnavailable, r.name)
+		}
+		r.probing = true
+	}
+	return nil
+}
+
+// record updates the breaker with the outcome of a call. Only transient
+// errors and timeouts count as failures; Odoo faults and rejected
+// credentials mean Odoo is answering.
+func (r *Resilience) record(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// A caller giving up says nothing about Odoo
+	canceled := errors.Is(err, context.Canceled)
+	failed := err != nil && (isTransient(err) || errors.Is(err, repository.ErrBackendTimeout))
+	if r.state == BreakerHalfOpen {
+		r.probing = false
+		switch {
+		case canceled:
+		case failed:
+			r.state, r.openedAt = BreakerOpen, time.Now()
+		default:
+			r.state, r.failures = BreakerClosed, 0
+		}
+		return
+	}
+	if !failed {
+		if !canceled {
+			r.failures = 0
+		}
+		return
+	}
+	r.failures++
+	if r.state == BreakerClosed && r.failures >= r.config.FailureThreshold {
+		r.state, r.openedAt = BreakerOpen, time.Now()
+	}
+}
+
+// backoff returns a random delay up to the exponential ceiling of the attempt
+func (r *Resilience) backoff(attempt int) time.Duration {
+	ceiling := r.config.BaseDelay << (attempt - 1)
+	if ceiling > r.config.MaxDelay || ceiling <= 0 {
+		ceiling = r.config.MaxDelay
+	}
+	if ceiling <= 0 {
+		return 0
+	}
+	return rand.N(ceiling) + 1
+}
+
+// isTransient reports whether a call failed because Odoo or the network in
+// front of it was unavailable, as opposed to Odoo rejecting the call
+func isTransient(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		return true
+	}
+	// kolo/xmlrpc reports HTTP errors from the reverse proxy as server errors
+	var serverErr rpc.ServerError
+	if errors.As(err, &serverErr) {
+		for _, code := range []string{"502", "503", "504"} {
+			if strings.HasSuffix(string(serverErr), "bad status code - "+code) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -1,6 +1,8 @@
 package odoo
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"nerp_wrapper/domain/entity"
 	"sort"
@@ -11,131 +13,183 @@ import (
 
 // OdooSaleRepository handles sale order operations with Odoo
 type OdooSaleRepository struct {
-	client *odoo.Client
+	clients    ClientProvider
+	masterData *MasterDataCache
 }
 
-// NewOdooSaleRepository creates a new instance of OdooSaleRepository
-func NewOdooSaleRepository(client *odoo.Client) *OdooSaleRepository {
-	return &OdooSaleRepository{client: client}
+// NewOdooSaleRepository creates a new instance of OdooSaleRepository.
+// masterData may be nil to read partners and salespeople uncached.
+func NewOdooSaleRepository(clients ClientProvider, masterData *MasterDataCache) *OdooSaleRepository {
+	return &OdooSaleRepository{clients: clients, masterData: masterData}
 }
 
-// GetAllSaleOrders retrieves sale orders from Odoo with pagination
-func (r *OdooSaleRepository) GetAllSaleOrders(page, pageSize int) (*entity.SaleOrderPagination, error) {
-	if page < 1 {
-		page = 1
-	}
-	if pageSize < 1 || pageSize > 500 {
-		pageSize = 500
+// applyScope restricts criteria to the sale orders the principal may see.
+// Users without the manager permission only see orders they are the
+// salesperson of, or with the team permission also those of their teams.
+func (r *OdooSaleRepository) applyScope(client *contextClient, principal *entity.Principal, criteria *odoo.Criteria) error {
+	switch principal.SalesScope() {
+	case entity.RecordScopeAll:
+		return nil
+	case entity.RecordScopeTeam:
+		teamIDs, err := r.getTeamIDs(client, principal.UserID)
+		if err != nil {
+			return err
+		}
+		if len(teamIDs) > 0 {
+			criteria.Or(
+				odoo.NewCriterion("user_id", "=", principal.UserID),
+				odoo.NewCriterion("team_id", "in", teamIDs),
+			)
+			return nil
+		}
 	}
 
-	offset := (page - 1) * pageSize
+	criteria.Add("user_id", "=", principal.UserID)
+	return nil
+}
 
-	criteria := odoo.NewCriteria().Add("state", "!=", "cancel")
-	totalCount, err := r.client.Count("sale.order", criteria, odoo.NewOptions())
-	if err != nil {
-		return nil, fmt.Errorf("failed to get total count: %v", err)
+// applySaleOrderListFilter translates the list filters into criteria.
+// Cancelled orders are left out unless asked for by state.
+func applySaleOrderListFilter(criteria *odoo.Criteria, filter entity.SaleOrderFilter) {
+	if len(filter.States) > 0 {
+		criteria.Add("state", "in", filter.States)
+	} else {
+		criteria.Add("state", "!=", "cancel")
 	}
-	totalPages := int((totalCount + int64(pageSize) - 1) / int64(pageSize))
+	if filter.PartnerID != 0 {
+		criteria.Add("partner_id", "=", filter.PartnerID)
+	}
+	if filter.SalespersonID != 0 {
+		criteria.Add("user_id", "=", filter.SalespersonID)
+	}
+	if filter.TeamID != 0 {
+		criteria.Add("team_id", "=", filter.TeamID)
+	}
+	applyDatetimeRange(criteria, "date_order", filter.DateFrom, filter.DateTo)
+	if filter.AmountMin != nil {
+		criteria.Add("amount_total", ">=", *filter.AmountMin)
+	}
+	if filter.AmountMax != nil {
+		criteria.Add("amount_total", "<=", *filter.AmountMax)
+	}
+	if filter.Search != "" {
+		anyOf(criteria,
+			odoo.NewCriterion("name", "ilike", filter.Search),
+			odoo.NewCriterion("client_order_ref", "ilike", filter.Search),
+			odoo.NewCriterion("partner_id.name", "ilike", filter.Search),
+		)
+	}
+}
 
-	searchOptions := odoo.NewOptions().
-		Limit(pageSize).
-		Offset(offset)
+// getTeamIDs retrieves the sales teams the user leads or is a member of
+func (r *OdooSaleRepository) getTeamIDs(client *contextClient, userID int64) ([]int64, error) {
+	criteria := odoo.NewCriteria().Or(
+		odoo.NewCriterion("user_id", "=", userID),
+		odoo.NewCriterion("member_ids", "in", []int64{userID}),
+	)
+	teamIDs, err := client.Search("crm.team", criteria, odoo.NewOptions())
+	if err != nil {
+		if errors.Is(err, odoo.ErrNotFound) {
+			return []int64{}, nil
+		}
+		return nil, fmt.Errorf("failed to search sales teams: %w", err)
+	}
+	return teamIDs, nil
+}
 
-	ids, err := r.client.Search("sale.order", criteria, searchOptions)
+// GetAllSaleOrders retrieves sale orders from Odoo with pagination
+func (r *OdooSaleRepository) GetAllSaleOrders(ctx context.Context, principal *entity.Principal, filter entity.SaleOrderFilter, page, pageSize int) (*entity.SaleOrderPagination, error) {
+	client, err := clientFor(ctx, r.clients, principal)
 	if err != nil {
-		return nil, fmt.Errorf("failed to search sale orders: %v", err)
+		return nil, err
 	}
 
-	if len(ids) == 0 {
-		return &entity.SaleOrderPagination{
-			Items:      []*entity.SaleOrder{},
-			Page:       page,
-			PageSize:   pageSize,
-			TotalItems: int(totalCount),
-			TotalPages: totalPages,
-		}, nil
+	if page < 1 {
+		page = 1
+	}
+	// The caller caps the page size at the configured maximum
+	if pageSize < 1 {
+		pageSize = 1
 	}
 
-	orders := []odoo.SaleOrder{}
-	readOptions := odoo.NewOptions().FetchFields(
-		"id", "name", "partner_id", "date_order", "amount_total", "state",
-		"partner_invoice_id", "partner_shipping_id", "validity_date",
-		"client_order_ref", "user_id", "note",
-	)
-	if err := r.client.Read("sale.order", ids, readOptions, &orders); err != nil {
-		return nil, fmt.Errorf("failed to read sale orders: %v", err)
+	offset := (page - 1) * pageSize
+
+	criteria := odoo.NewCriteria()
+	applySaleOrderListFilter(criteria, filter)
+	applyCompanyFilter(criteria, filter.CompanyIDs)
+	if err := r.applyScope(client, principal, criteria); err != nil {
+		return nil, err
 	}
 
-	partnerIDs := make(map[int64]bool)
-	userIDs := make(map[int64]bool)
-	for _, order := range orders {
-		if order.PartnerId != nil {
-			partnerIDs[order.PartnerId.Get()] = true
+	// The count, the page of orders and, once read, their partners and
+	// salespeople are loaded concurrently
+	loader := newBatchLoader(client)
+	var totalCount int64
+	loader.Go(func(client *contextClient) (err error) {
+		if totalCount, err = client.Count("sale.order", criteria, odoo.NewOptions()); err != nil {
+			return fmt.Errorf("failed to get total count: %w", err)
 		}
-		if order.UserId != nil {
-			userIDs[order.UserId.Get()] = true
-		}
-	}
+		return nil
+	})
 
-	partners := make(map[int64]*odoo.ResPartner)
-	if len(partnerIDs) > 0 {
-		partnerIDsList := make([]int64, 0, len(partnerIDs))
-		for id := range partnerIDs {
-			partnerIDsList = append(partnerIDsList, id)
+	orders := []odoo.SaleOrder{}
+	var partners, users map[int64]masterRecord
+	loader.Go(func(client *contextClient) error {
+		searchOptions := odoo.NewOptions().
+			Limit(pageSize).
+			Offset(offset)
+
+		ids, err := client.Search("sale.order", criteria, searchOptions)
+		if err != nil && !errors.Is(err, odoo.ErrNotFound) {
+			return fmt.Errorf("failed to search sale orders: %w", err)
 		}
-		var partnerRecords []odoo.ResPartner
-		partnerOptions := odoo.NewOptions().FetchFields("id", "name", "vat", "phone", "mobile")
-		if err := r.client.Read("res.partner", partnerIDsList, partnerOptions, &partnerRecords); err == nil {
-			for i := range partnerRecords {
-				partners[partnerRecords[i].Id.Get()] = &partnerRecords[i]
-			}
+		if len(ids) == 0 {
+			return nil
 		}
-	}
 
-	users := make(map[int64]*odoo.ResUsers)
-	if len(userIDs) > 0 {
-		userIDsList := make([]int64, 0, len(userIDs))
-		for id := range userIDs {
-			userIDsList = append(userIDsList, id)
+		readOptions := odoo.NewOptions().FetchFields(
+			"id", "name", "partner_id", "date_order", "amount_total", "state",
+			"partner_invoice_id", "partner_shipping_id", "validity_date",
+			"client_order_ref", "user_id", "note", "company_id",
+		)
+		if err := client.Read("sale.order", ids, readOptions, &orders); err != nil {
+			return fmt.Errorf("failed to read sale orders: %w", err)
 		}
-		var userRecords []odoo.ResUsers
-		userOptions := odoo.NewOptions().FetchFields("id", "name")
-		if err := r.client.Read("res.users", userIDsList, userOptions, &userRecords); err == nil {
-			for i := range userRecords {
-				users[userRecords[i].Id.Get()] = &userRecords[i]
+
+		var partnerIDs, userIDs relatedIDs
+		for _, order := range orders {
+			if order.PartnerId != nil {
+				partnerIDs.Add(order.PartnerId.Get())
+			}
+			if order.UserId != nil {
+				userIDs.Add(order.UserId.Get())
 			}
 		}
+		partners = loadMasterData(loader, r.masterData, principal.TenantID, "res.partner", partnerIDs)
+		users = loadMasterData(loader, r.masterData, principal.TenantID, "res.users", userIDs)
+		return nil
+	})
+	if err := loader.Wait(); err != nil {
+		return nil, err
 	}
+	totalPages := int((totalCount + int64(pageSize) - 1) / int64(pageSize))
 
-	var result []*entity.SaleOrder
+	result := []*entity.SaleOrder{}
 	for _, order := range orders {
 		var partnerName, partnerVat, partnerPhone, partnerMobile string
 		var partnerID int64
 		if order.PartnerId != nil {
 			partnerID = order.PartnerId.Get()
-			if partner, exists := partners[partnerID]; exists {
-				if partner.Name != nil {
-					partnerName = partner.Name.Get()
-				}
-				if partner.Vat != nil {
-					partnerVat = partner.Vat.Get()
-				}
-				if partner.Phone != nil {
-					partnerPhone = partner.Phone.Get()
-				}
-				if partner.Mobile != nil {
-					partnerMobile = partner.Mobile.Get()
-				}
-			}
+			partner := partners[partnerID]
+			partnerName, partnerVat = partner["name"], partner["vat"]
+			partnerPhone, partnerMobile = partner["phone"], partner["mobile"]
 		}
 
 		var salespersonID int64
 		var salespersonName string
 		if order.UserId != nil {
 			salespersonID = order.UserId.Get()
-			if user, exists := users[salespersonID]; exists && user.Name != nil {
-				salespersonName = user.Name.Get()
-			}
+			salespersonName = users[salespersonID]["name"]
 		}
 
 		saleOrder := &entity.SaleOrder{
@@ -153,6 +207,8 @@ func (r *OdooSaleRepository) GetAllSaleOrders(page, pageSize int) (*entity.SaleO
 			Note:            order.Note.Get(),
 		}
 
+		saleOrder.CompanyID, saleOrder.CompanyName = companyOf(order.CompanyId)
+
 		if order.State != nil {
 			if s, ok := order.State.Get().(string); ok {
 				saleOrder.State = s
@@ -184,16 +240,23 @@ func (r *OdooSaleRepository) GetAllSaleOrders(page, pageSize int) (*entity.SaleO
 		PageSize:   pageSize,
 		TotalItems: int(totalCount),
 		TotalPages: totalPages,
+		Warnings:   loader.Warnings(),
 	}, nil
 }
 
 // GetDailySalesSummary retrieves daily sales summary with pagination
-func (r *OdooSaleRepository) GetDailySalesSummary(page, pageSize int) (*entity.SalesSummaryResponse, error) {
+func (r *OdooSaleRepository) GetDailySalesSummary(ctx context.Context, principal *entity.Principal, filter entity.SaleOrderFilter, page, pageSize int) (*entity.SalesSummaryResponse, error) {
+	client, err := clientFor(ctx, r.clients, principal)
+	if err != nil {
+		return nil, err
+	}
+
 	if page < 1 {
 		page = 1
 	}
-	if pageSize < 1 || pageSize > 500 {
-		pageSize = 500
+	// The caller caps the page size at the configured maximum
+	if pageSize < 1 {
+		pageSize = 1
 	}
 
 	// Calculate offset
@@ -201,11 +264,15 @@ func (r *OdooSaleRepository) GetDailySalesSummary(page, pageSize int) (*entity.S
 
 	// Search for confirmed sales orders
 	criteria := odoo.NewCriteria().Add("state", "=", "sale")
+	applyCompanyFilter(criteria, filter.CompanyIDs)
+	if err := r.applyScope(client, principal, criteria); err != nil {
+		return nil, err
+	}
 
 	// Get total count
-	totalCount, err := r.client.Count("sale.order", criteria, odoo.NewOptions())
+	totalCount, err := client.Count("sale.order", criteria, odoo.NewOptions())
 	if err != nil {
-		return nil, fmt.Errorf("failed to get total count: %v", err)
+		return nil, fmt.Errorf("failed to get total count: %w", err)
 	}
 
 	// Calculate total pages
@@ -215,12 +282,12 @@ func (r *OdooSaleRepository) GetDailySalesSummary(page, pageSize int) (*entity.S
 	searchOptions := odoo.NewOptions().
 		Limit(pageSize).
 		Offset(offset).
-		FetchFields("id", "name", "date_order", "amount_total")
+		FetchFields("id", "name", "date_order", "amount_total", "company_id")
 
 	// Execute search and read in one call
 	var records []odoo.SaleOrder
-	if err := r.client.SearchRead("sale.order", criteria, searchOptions, &records); err != nil {
-		return nil, fmt.Errorf("failed to search sale orders: %v", err)
+	if err := client.SearchRead("sale.order", criteria, searchOptions, &records); err != nil && !errors.Is(err, odoo.ErrNotFound) {
+		return nil, fmt.Errorf("failed to search sale orders: %w", err)
 	}
 
 	if len(records) == 0 {
@@ -282,130 +349,84 @@ func (r *OdooSaleRepository) GetDailySalesSummary(page, pageSize int) (*entity.S
 	}, nil
 }
 
-// GetPeriodSalesSummary retrieves sales summary for a specific period
-func (r *OdooSaleRepository) GetPeriodSalesSummary(periodType entity.PeriodType, customStartDate, customEndDate *time.Time) (*entity.PeriodSalesSummaryResponse, error) {
-	// Calculate date range based on period type
-	now := time.Now()
-	var startDate, endDate time.Time
-
-	if customStartDate != nil && customEndDate != nil {
-		startDate = *customStartDate
-		endDate = *customEndDate
-	} else {
-		endDate = now
-		switch periodType {
-		case entity.PeriodTypeDay:
-			startDate = now.AddDate(0, 0, -1)
-		case entity.PeriodTypeWeek:
-			startDate = now.AddDate(0, 0, -7)
-		case entity.PeriodTypeMonth:
-			startDate = now.AddDate(0, 0, -30)
-		case entity.PeriodTypeQuarter:
-			startDate = now.AddDate(0, 0, -90)
-		case entity.PeriodTypeMonthly:
-			startDate = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
-			endDate = startDate.AddDate(0, 1, 0).Add(-time.Second)
-		case entity.PeriodTypeYearly:
-			startDate = time.Date(now.Year(), 1, 1, 0, 0, 0, 0, now.Location())
-			endDate = startDate.AddDate(1, 0, 0).Add(-time.Second)
-		default:
-			startDate = now.AddDate(0, 0, -30) // Default to last 30 days
-		}
+// GetPeriodSalesSummary retrieves sales summary for a specific period. Odoo
+// sums the orders per day with read_group; the orders themselves are only
+// read when filter.IncludeItems is set.
+func (r *OdooSaleRepository) GetPeriodSalesSummary(ctx context.Context, principal *entity.Principal, filter entity.SaleOrderFilter, periodType entity.PeriodType, customStartDate, customEndDate *time.Time) (*entity.PeriodSalesSummaryResponse, error) {
+	client, err := clientFor(ctx, r.clients, principal)
+	if err != nil {
+		return nil, err
 	}
 
-	// Create search criteria with date range
-	criteria := odoo.NewCriteria().
-		Add("state", "=", "sale").
-		Add("date_order", ">=", startDate.Format("2006-01-02")).
-		Add("date_order", "<=", endDate.Format("2006-01-02"))
+	startDate, endDate := periodType.DateRange(time.Now(), customStartDate, customEndDate)
 
-	// Search with date ordering
-	searchOptions := odoo.NewOptions().
-		FetchFields("id", "name", "date_order", "amount_total")
+	// Create search criteria with date range. date_order is a datetime, so
+	// the range runs up to the start of the day after endDate.
+	criteria := odoo.NewCriteria().Add("state", "=", "sale")
+	applyDatetimeRange(criteria, "date_order", &startDate, &endDate)
+	applyCompanyFilter(criteria, filter.CompanyIDs)
+	if err := r.applyScope(client, principal, criteria); err != nil {
+		return nil, err
+	}
 
-	// Execute search and read in one call
-	var records []odoo.SaleOrder
-	if err := r.client.SearchRead("sale.order", criteria, searchOptions, &records); err != nil {
-		return nil, fmt.Errorf("failed to search sale orders: %v", err)
+	totals, err := readDailyTotals(client, "sale.order", criteria, "date_order", "amount_total", filter.Consolidated)
+	if err != nil {
+		return nil, err
 	}
+	days, totalAmount, totalOrders := sumDailyTotals(totals)
 
-	// Group orders by date
+	summaries := make([]entity.DailySalesSummary, len(days))
 	dateMap := make(map[string]*entity.DailySalesSummary)
-	var totalAmount float64
-	var totalOrders int
+	for i, day := range days {
+		summaries[i] = entity.DailySalesSummary{Date: day.Day, TotalAmount: day.Amount, OrderCount: day.Count}
+		dateMap[day.Day.Format("2006-01-02")] = &summaries[i]
+	}
 
-	for _, order := range records {
-		orderDate := order.DateOrder.Get()
-		dateStr := orderDate.Format("2006-01-02")
+	if filter.IncludeItems && totalOrders > 0 {
+		searchOptions := odoo.NewOptions().
+			FetchFields("id", "name", "date_order", "amount_total")
 
-		if _, exists := dateMap[dateStr]; !exists {
-			dateMap[dateStr] = &entity.DailySalesSummary{
-				Date:        orderDate.Truncate(24 * time.Hour),
-				TotalAmount: 0,
-				OrderCount:  0,
-				Orders:      []entity.SaleOrderSummary{},
-			}
+		var records []odoo.SaleOrder
+		if err := client.SearchRead("sale.order", criteria, searchOptions, &records); err != nil && !errors.Is(err, odoo.ErrNotFound) {
+			return nil, fmt.Errorf("failed to search sale orders: %w", err)
 		}
-
-		summary := dateMap[dateStr]
-		amount := order.AmountTotal.Get()
-		summary.TotalAmount += amount
-		summary.OrderCount++
-		totalAmount += amount
-		totalOrders++
-
-		orderSummary := entity.SaleOrderSummary{
-			OrderNumber: summary.OrderCount,
-			OrderID:     order.Id.Get(),
-			OrderName:   order.Name.Get(),
-			AmountTotal: amount,
-			DateOrder:   orderDate,
+		for _, order := range records {
+			orderDate := order.DateOrder.Get()
+			summary, exists := dateMap[orderDate.Format("2006-01-02")]
+			if !exists {
+				continue
+			}
+			summary.Orders = append(summary.Orders, entity.SaleOrderSummary{
+				OrderNumber: len(summary.Orders) + 1,
+				OrderID:     order.Id.Get(),
+				OrderName:   order.Name.Get(),
+				AmountTotal: order.AmountTotal.Get(),
+				DateOrder:   orderDate,
+			})
 		}
-		summary.Orders = append(summary.Orders, orderSummary)
-	}
-
-	// Convert map to sorted slice
-	var summaries []entity.DailySalesSummary
-	for _, summary := range dateMap {
-		summaries = append(summaries, *summary)
 	}
 
-	// Sort by date descending
-	sort.Slice(summaries, func(i, j int) bool {
-		return summaries[i].Date.After(summaries[j].Date)
-	})
-
-	// Calculate period description and average
-	days := endDate.Sub(startDate).Hours() / 24
-	if days < 1 {
-		days = 1
-	}
-
-	var periodDesc string
-	switch periodType {
-	case entity.PeriodTypeDay:
-		periodDesc = "Last 24 Hours"
-	case entity.PeriodTypeWeek:
-		periodDesc = "Last 7 Days"
-	case entity.PeriodTypeMonth:
-		periodDesc = "Last 30 Days"
-	case entity.PeriodTypeQuarter:
-		periodDesc = "Last 90 Days"
-	case entity.PeriodTypeMonthly:
-		periodDesc = endDate.Format("January 2006")
-	case entity.PeriodTypeYearly:
-		periodDesc = endDate.Format("2006")
-	default:
-		periodDesc = fmt.Sprintf("%s to %s", startDate.Format("2006-01-02"), endDate.Format("2006-01-02"))
+	// Per-company totals for consolidated reporting, ordered by company ID
+	var companies []entity.CompanySalesSummary
+	if filter.Consolidated {
+		for _, company := range sumCompanyTotals(totals) {
+			companies = append(companies, entity.CompanySalesSummary{
+				CompanyID:   company.CompanyID,
+				CompanyName: company.CompanyName,
+				TotalAmount: company.Amount,
+				OrderCount:  company.Count,
+			})
+		}
 	}
 
 	return &entity.PeriodSalesSummaryResponse{
-		Period:       periodDesc,
+		Period:       periodType.Describe(startDate, endDate),
 		PeriodType:   periodType,
 		DateRange:    entity.DateRange{StartDate: startDate, EndDate: endDate},
 		Items:        summaries,
 		TotalAmount:  totalAmount,
 		OrderCount:   totalOrders,
-		AverageDaily: totalAmount / days,
+		AverageDaily: totalAmount / entity.PeriodDays(startDate, endDate),
+		Companies:    companies,
 	}, nil
 }
@@ -0,0 +1,110 @@
+package odoo
+
+import (
+	"context"
+	"nerp_wrapper/domain/entity"
+	"nerp_wrapper/infrastructure/memory"
+	"nerp_wrapper/infrastructure/odoo/odootest"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	odoo "github.com/skilld-labs/go-odoo"
+)
+
+func TestApplySaleOrderListFilter(t *testing.T) {
+	minimum, maximum := 100.0, 250.5
+	tests := []struct {
+		name   string
+		filter entity.SaleOrderFilter
+		want   []interface{}
+	}{
+		{"no filters", entity.SaleOrderFilter{}, []interface{}{condition("state", "!=", "cancel")}},
+		{
+			"states replace the cancel exclusion",
+			entity.SaleOrderFilter{States: []string{"sale", "cancel"}},
+			[]interface{}{condition("state", "in", []string{"sale", "cancel"})},
+		},
+		{
+			"every filter",
+			entity.SaleOrderFilter{
+				PartnerID:     9,
+				SalespersonID: 6,
+				TeamID:        1,
+				DateFrom:      date("2024-03-01"),
+				DateTo:        date("2024-03-31"),
+				AmountMin:     &minimum,
+				AmountMax:     &maximum,
+				Search:        "Azure",
+			},
+			[]interface{}{
+				condition("state", "!=", "cancel"),
+				condition("partner_id", "=", int64(9)),
+				condition("user_id", "=", int64(6)),
+				condition("team_id", "=", int64(1)),
+				condition("date_order", ">=", "2024-03-01 00:00:00"),
+				condition("date_order", "<", "2024-04-01 00:00:00"),
+				condition("amount_total", ">=", 100.0),
+				condition("amount_total", "<=", 250.5),
+				"|", "|",
+				condition("name", "ilike", "Azure"),
+				condition("client_order_ref", "ilike", "Azure"),
+				condition("partner_id.name", "ilike", "Azure"),
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			criteria := odoo.NewCriteria()
+			applySaleOrderListFilter(criteria, tt.filter)
+			if got := domain(criteria); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("domain is %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPeriodSalesSummaryIncludesOrdersLaterOnTheLastDay(t *testing.T) {
+	fixtures, err := memory.LoadFixtures(filepath.Join("..", "..", "fixtures"))
+	if err != nil {
+		t.Fatalf("failed to load fixtures: %v", err)
+	}
+	fake := odootest.NewServer("nerp")
+	fake.Seed(fixtures)
+	odooServer := httptest.NewServer(fake)
+	defer odooServer.Close()
+
+	pool := NewClientPool(odooServer.URL, "nerp", time.Hour, nil)
+	if err := pool.Open(context.Background(), 2, "admin", "admin"); err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	registry := NewTenantRegistry("default")
+	if err := registry.Register(&entity.Tenant{ID: "default"}, pool, nil); err != nil {
+		t.Fatalf("failed to register tenant: %v", err)
+	}
+	principal := &entity.Principal{TenantID: "default", UserID: 2, Permissions: []entity.Permission{entity.PermissionAdmin}}
+
+	// Every fixture order is placed after midnight, so a range ending on its
+	// day only includes it when the whole day is covered
+	for _, order := range fixtures.SaleOrders {
+		if order.State != "sale" {
+			continue
+		}
+		day := date(order.DateOrder.UTC().Format(time.DateOnly))
+		want := 0
+		for _, other := range fixtures.SaleOrders {
+			if other.State == "sale" && other.DateOrder.UTC().Format(time.DateOnly) == day.Format(time.DateOnly) {
+				want++
+			}
+		}
+		summary, err := NewOdooSaleRepository(registry, nil).GetPeriodSalesSummary(context.Background(), principal, entity.SaleOrderFilter{}, entity.PeriodTypeMonth, day, day)
+		if err != nil {
+			t.Fatalf("GetPeriodSalesSummary failed: %v", err)
+		}
+		if summary.OrderCount != want {
+			t.Errorf("summary of %s counts %d orders, want %d", day.Format(time.DateOnly), summary.OrderCount, want)
+		}
+	}
+}
@@ -0,0 +1,151 @@
+package odoo
+
+import (
+	"context"
+	"fmt"
+	"nerp_wrapper/domain/entity"
+	"nerp_wrapper/domain/repository"
+	"net"
+	"strings"
+	"sync"
+)
+
+// ClientProvider returns the Odoo clients to run a principal's calls with
+type ClientProvider interface {
+	Clients(ctx context.Context, principal *entity.Principal) (*ClientSet, error)
+	Resilience(principal *entity.Principal) *Resilience
+}
+
+// TenantRegistry implements TenantRegistry interface and routes each
+// principal to the client pool of its tenant
+type TenantRegistry struct {
+	mu        sync.RWMutex
+	defaultID string
+	tenants   map[string]*tenantEntry
+	order     []string
+}
+
+type tenantEntry struct {
+	tenant   *entity.Tenant
+	pool     *ClientPool
+	authRepo repository.AuthRepository
+}
+
+// NewTenantRegistry creates a new instance of TenantRegistry. Requests that do
+// not name a tenant are served by defaultID.
+func NewTenantRegistry(defaultID string) *TenantRegistry {
+	return &TenantRegistry{
+		defaultID: defaultID,
+		tenants:   make(map[string]*tenantEntry),
+	}
+}
+
+// Register adds a tenant with its client pool and auth repository. The pool
+// is nil for tenants served from fixtures instead of Odoo.
+func (r *TenantRegistry) Register(tenant *entity.Tenant, pool *ClientPool, authRepo repository.AuthRepository) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.tenants[tenant.ID]; exists {
+		return fmt.Errorf("tenant %q is already registered", tenant.ID)
+	}
+	r.tenants[tenant.ID] = &tenantEntry{tenant: tenant, pool: pool, authRepo: authRepo}
+	r.order = append(r.order, tenant.ID)
+	return nil
+}
+
+// Tenants returns the registered tenants in registration order
+func (r *TenantRegistry) Tenants() []*entity.Tenant {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	tenants := make([]*entity.Tenant, 0, len(r.order))
+	for _, id := range r.order {
+		tenant := *r.tenants[id].tenant
+		tenants = append(tenants, &tenant)
+	}
+	return tenants
+}
+
+// ResolveTenant retrieves a tenant by ID, or the default tenant when id is empty
+func (r *TenantRegistry) ResolveTenant(id string) (*entity.Tenant, error) {
+	entry, err := r.entry(id)
+	if err != nil {
+		return nil, err
+	}
+	tenant := *entry.tenant
+	return &tenant, nil
+}
+
+// TenantForHost retrieves the tenant whose subdomain is the first label of the host
+func (r *TenantRegistry) TenantForHost(host string) *entity.Tenant {
+	if name, _, err := net.SplitHostPort(host); err == nil {
+		host = name
+	}
+	host = strings.ToLower(host)
+	label, _, found := strings.Cut(host, ".")
+	if !found {
+		return nil
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, id := range r.order {
+		if tenant := r.tenants[id].tenant; tenant.Subdomain == label {
+			found := *tenant
+			return &found
+		}
+	}
+	return nil
+}
+
+// AuthRepository retrieves the auth repository of a tenant
+func (r *TenantRegistry) AuthRepository(tenantID string) (repository.AuthRepository, error) {
+	entry, err := r.entry(tenantID)
+	if err != nil {
+		return nil, err
+	}
+	return entry.authRepo, nil
+}
+
+// Clients returns the clients of the principal from its tenant's pool
+func (r *TenantRegistry) Clients(ctx context.Context, principal *entity.Principal) (*ClientSet, error) {
+	if principal == nil {
+		return nil, repository.ErrSessionExpired
+	}
+	entry, err := r.entry(principal.TenantID)
+	if err != nil {
+		return nil, err
+	}
+	if entry.pool == nil {
+		return nil, fmt.Errorf("tenant %s has no Odoo connection", principal.TenantID)
+	}
+	return entry.pool.Clients(ctx, principal)
+}
+
+// Resilience returns the retry and circuit breaker layer of the principal's
+// tenant, nil when the tenant has no Odoo connection
+func (r *TenantRegistry) Resilience(principal *entity.Principal) *Resilience {
+	if principal == nil {
+		return nil
+	}
+	entry, err := r.entry(principal.TenantID)
+	if err != nil || entry.pool == nil {
+		return nil
+	}
+	return entry.pool.Resilience()
+}
+
+func (r *TenantRegistry) entry(id string) (*tenantEntry, error) {
+	if id == "" {
+		id = r.defaultID
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	entry, exists := r.tenants[id]
+	if !exists {
+		return nil, fmt.Errorf("%w: %s", repository.ErrUnknownTenant, id)
+	}
+	return entry, nil
+}
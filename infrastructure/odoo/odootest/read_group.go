@@ -0,0 +1,312 @@
+package odootest
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// readGroup aggregates the records matching domain like Odoo's read_group.
+// fields are "field", "field:agg" or "alias:agg(field)" with agg one of sum,
+// avg, min, max, count and count_distinct; groupby entries may carry a date
+// granularity ("date_order:day"). With lazy set only the first groupby is
+// applied and the count is reported as "<field>_count", otherwise "__count".
+func (s *store) readGroup(modelName string, domain []interface{}, fields, groupby []string, lazy bool, offset, limit int) ([]interface{}, error) {
+	ids, err := s.search(modelName, domain, 0, 0, "")
+	if err != nil {
+		return nil, err
+	}
+	records := s.read(modelName, ids, nil)
+
+	if lazy && len(groupby) > 1 {
+		groupby = groupby[:1]
+	}
+	specs := make([]groupSpec, len(groupby))
+	for i, spec := range groupby {
+		if specs[i], err = parseGroupSpec(spec); err != nil {
+			return nil, err
+		}
+	}
+	aggregates := make([]aggregateSpec, 0, len(fields))
+	for _, field := range fields {
+		aggregate, ok := parseAggregateSpec(field, specs)
+		if ok {
+			aggregates = append(aggregates, aggregate)
+		}
+	}
+
+	type group struct {
+		keys    []interface{}
+		ranges  map[string]interface{}
+		members []Record
+	}
+	groups := make(map[string]*group)
+	var order []string
+	for _, item := range records {
+		record := item.(Record)
+		keys := make([]interface{}, len(specs))
+		ranges := map[string]interface{}{}
+		var id strings.Builder
+		for i, spec := range specs {
+			keys[i] = spec.key(record[spec.field])
+			if from, to, ok := spec.bounds(record[spec.field]); ok {
+				ranges[spec.raw] = map[string]interface{}{"from": from, "to": to}
+			}
+			fmt.Fprintf(&id, "%v|", spec.sortKey(record[spec.field]))
+		}
+		g, exists := groups[id.String()]
+		if !exists {
+			g = &group{keys: keys, ranges: ranges}
+			groups[id.String()] = g
+			order = append(order, id.String())
+		}
+		g.members = append(g.members, record)
+	}
+
+	// Groups are ordered by their groupby values, dates chronologically
+	sort.Strings(order)
+
+	result := []interface{}{}
+	for _, id := range order {
+		g := groups[id]
+		row := map[string]interface{}{}
+		for i, spec := range specs {
+			row[spec.raw] = g.keys[i]
+		}
+		if len(g.ranges) > 0 {
+			row["__range"] = g.ranges
+		}
+		if lazy && len(specs) == 1 {
+			row[specs[0].field+"_count"] = int64(len(g.members))
+		} else {
+			row["__count"] = int64(len(g.members))
+		}
+		for _, aggregate := range aggregates {
+			row[aggregate.alias] = aggregate.compute(g.members)
+		}
+		groupDomain := append([]interface{}{}, domain...)
+		for i, spec := range specs {
+			if bounds, ok := g.ranges[spec.raw].(map[string]interface{}); ok {
+				groupDomain = append(groupDomain,
+					[]interface{}{spec.field, ">=", bounds["from"]},
+					[]interface{}{spec.field, "<", bounds["to"]})
+				continue
+			}
+			groupDomain = append(groupDomain, []interface{}{spec.field, "=", g.keys[i]})
+		}
+		row["__domain"] = groupDomain
+		result = append(result, row)
+	}
+
+	if offset > len(result) {
+		offset = len(result)
+	}
+	result = result[offset:]
+	if limit > 0 && limit < len(result) {
+		result = result[:limit]
+	}
+	return result, nil
+}
+
+// groupSpec is one groupby entry such as "company_id" or "date_order:month"
+type groupSpec struct {
+	raw         string
+	field       string
+	granularity string
+}
+
+func parseGroupSpec(raw string) (groupSpec, error) {
+	field, granularity, _ := strings.Cut(raw, ":")
+	spec := groupSpec{raw: raw, field: field, granularity: granularity}
+	switch granularity {
+	case "", "day", "week", "month", "quarter", "year":
+		return spec, nil
+	}
+	return spec, fmt.Errorf("invalid groupby granularity %q", raw)
+}
+
+// dateValue parses a date or datetime field value
+func dateValue(v interface{}) (time.Time, bool) {
+	text, ok := v.(string)
+	if !ok {
+		return time.Time{}, false
+	}
+	format := dateFormat
+	if len(text) > len(dateFormat) {
+		format = datetimeFormat
+	}
+	t, err := time.Parse(format, text)
+	return t, err == nil
+}
+
+// start truncates t to the beginning of the spec's granularity (month by default)
+func (g groupSpec) start(t time.Time) time.Time {
+	switch g.granularity {
+	case "day":
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	case "week":
+		day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+		return day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+	case "quarter":
+		return time.Date(t.Year(), time.Month((int(t.Month())-1)/3*3+1), 1, 0, 0, 0, 0, time.UTC)
+	case "year":
+		return time.Date(t.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+	}
+}
+
+func (g groupSpec) end(start time.Time) time.Time {
+	switch g.granularity {
+	case "day":
+		return start.AddDate(0, 0, 1)
+	case "week":
+		return start.AddDate(0, 0, 7)
+	case "quarter":
+		return start.AddDate(0, 3, 0)
+	case "year":
+		return start.AddDate(1, 0, 0)
+	default:
+		return start.AddDate(0, 1, 0)
+	}
+}
+
+// key returns the group value Odoo reports: a label for dates, the raw value otherwise
+func (g groupSpec) key(v interface{}) interface{} {
+	t, ok := dateValue(v)
+	if !ok {
+		return v
+	}
+	start := g.start(t)
+	switch g.granularity {
+	case "day":
+		return start.Format("02 Jan 2006")
+	case "week":
+		year, week := start.ISOWeek()
+		return fmt.Sprintf("W%d %d", week, year)
+	case "quarter":
+		return fmt.Sprintf("Q%d %d", (int(start.Month())-1)/3+1, start.Year())
+	case "year":
+		return start.Format("2006")
+	default:
+		return start.Format("January 2006")
+	}
+}
+
+// bounds returns the __range of a date group
+func (g groupSpec) bounds(v interface{}) (string, string, bool) {
+	t, ok := dateValue(v)
+	if !ok {
+		return "", "", false
+	}
+	start := g.start(t)
+	format := datetimeFormat
+	if text, _ := v.(string); len(text) == len(dateFormat) {
+		format = dateFormat
+	}
+	return start.Format(format), g.end(start).Format(format), true
+}
+
+// sortKey orders groups: dates chronologically, many2one by ID, false first
+func (g groupSpec) sortKey(v interface{}) string {
+	if t, ok := dateValue(v); ok {
+		return g.start(t).Format(datetimeFormat)
+	}
+	if pair, ok := v.([]interface{}); ok && len(pair) == 2 {
+		id, _ := toInt(pair[0])
+		return fmt.Sprintf("%020d", id)
+	}
+	if isFalse(v) {
+		return ""
+	}
+	if n, ok := toFloat(v); ok {
+		return fmt.Sprintf("%030.6f", n)
+	}
+	return fmt.Sprint(v)
+}
+
+// aggregateSpec is one aggregated field such as "amount_total:sum"
+type aggregateSpec struct {
+	alias    string
+	field    string
+	function string
+}
+
+// parseAggregateSpec reads a read_group field spec; groupby fields are skipped
+func parseAggregateSpec(raw string, groupby []groupSpec) (aggregateSpec, bool) {
+	alias, function, hasFunction := strings.Cut(raw, ":")
+	field := alias
+	if open := strings.Index(function, "("); open >= 0 && strings.HasSuffix(function, ")") {
+		field = function[open+1 : len(function)-1]
+		function = function[:open]
+	}
+	if !hasFunction {
+		function = "sum"
+	}
+	for _, spec := range groupby {
+		if spec.field == field {
+			return aggregateSpec{}, false
+		}
+	}
+	if field == "id" || field == "__count" {
+		return aggregateSpec{}, false
+	}
+	return aggregateSpec{alias: alias, field: field, function: function}, true
+}
+
+func (a aggregateSpec) compute(records []Record) interface{} {
+	switch a.function {
+	case "count":
+		count := int64(0)
+		for _, record := range records {
+			if !isFalse(record[a.field]) {
+				count++
+			}
+		}
+		return count
+	case "count_distinct":
+		seen := make(map[string]bool)
+		for _, record := range records {
+			if !isFalse(record[a.field]) {
+				seen[fmt.Sprint(record[a.field])] = true
+			}
+		}
+		return int64(len(seen))
+	}
+
+	var values []float64
+	for _, record := range records {
+		if n, ok := toFloat(record[a.field]); ok {
+			values = append(values, n)
+		}
+	}
+	if len(values) == 0 {
+		return false
+	}
+	result := values[0]
+	switch a.function {
+	case "min":
+		for _, v := range values {
+			if v < result {
+				result = v
+			}
+		}
+	case "max":
+		for _, v := range values {
+			if v > result {
+				result = v
+			}
+		}
+	default:
+		sum := 0.0
+		for _, v := range values {
+			sum += v
+		}
+		result = sum
+		if a.function == "avg" {
+			result = sum / float64(len(values))
+		}
+	}
+	return result
+}
@@ -0,0 +1,141 @@
+package odootest
+
+import (
+	"nerp_wrapper/infrastructure/memory"
+	"time"
+)
+
+// Seed loads the fixture users, sale orders and invoices, with the partners,
+// companies, currencies and journals they refer to, so the fake serves the
+// same data as the wrapper's fixture mode. Invoices are stored both in
+// account.invoice.report and account.move.
+func (s *Server) Seed(fixtures *memory.Fixtures) {
+	names := make(map[int64]string)
+	for _, order := range fixtures.SaleOrders {
+		names[order.SalespersonID] = order.SalespersonName
+	}
+	var members []int64
+	for _, user := range fixtures.Users {
+		name := names[user.ID]
+		if name == "" {
+			name = user.Login
+		}
+		s.AddUser(user.ID, user.Login, user.Password, name, user.Groups...)
+		members = append(members, user.ID)
+	}
+	s.Insert("crm.team", Record{"id": int64(1), "name": "Sales", "user_id": false, "member_ids": members})
+
+	partners := make(map[int64]bool)
+	addPartner := func(id int64, name, vat, phone, mobile string) {
+		if id == 0 || partners[id] {
+			return
+		}
+		partners[id] = true
+		s.Insert("res.partner", Record{"id": id, "name": name, "vat": orFalse(vat), "phone": orFalse(phone), "mobile": orFalse(mobile)})
+	}
+	seen := make(map[string]bool)
+	addNamed := func(model string, id int64, name string) {
+		key := model + "/" + name
+		if id == 0 || seen[key] {
+			return
+		}
+		seen[key] = true
+		s.Insert(model, Record{"id": id, "name": name})
+	}
+
+	for _, order := range fixtures.SaleOrders {
+		addPartner(order.Partner, order.PartnerName, order.PartnerVat, order.PartnerPhone, order.PartnerMobile)
+		addNamed("res.company", order.CompanyID, order.CompanyName)
+		s.Insert("sale.order", Record{
+			"id":                  order.ID,
+			"name":                order.Name,
+			"partner_id":          many2one(order.Partner, order.PartnerName),
+			"partner_invoice_id":  many2one(order.PartnerInvoiceID, order.PartnerName),
+			"partner_shipping_id": many2one(order.PartnerShippingID, order.PartnerName),
+			"date_order":          datetime(order.DateOrder),
+			"validity_date":       date(order.ValidityDate),
+			"client_order_ref":    orFalse(order.ClientOrderRef),
+			"user_id":             many2one(order.SalespersonID, order.SalespersonName),
+			"team_id":             many2one(1, "Sales"),
+			"company_id":          many2one(order.CompanyID, order.CompanyName),
+			"amount_total":        order.AmountTotal,
+			"state":               order.State,
+			"note":                orFalse(order.Note),
+		})
+	}
+
+	for _, invoice := range fixtures.Invoices {
+		addPartner(invoice.Partner, invoice.PartnerName, invoice.PartnerVat, invoice.PartnerPhone, invoice.PartnerMobile)
+		addNamed("res.company", invoice.CompanyID, invoice.CompanyName)
+		addNamed("res.currency", invoice.CurrencyID, invoice.CurrencyName)
+		addNamed("account.journal", invoice.JournalID, invoice.JournalName)
+
+		common := Record{
+			"id":            invoice.ID,
+			"partner_id":    many2one(invoice.Partner, invoice.PartnerName),
+			"journal_id":    many2one(invoice.JournalID, invoice.JournalName),
+			"currency_id":   many2one(invoice.CurrencyID, invoice.CurrencyName),
+			"company_id":    many2one(invoice.CompanyID, invoice.CompanyName),
+			"state":         invoice.State,
+			"move_type":     invoice.Type,
+			"payment_state": invoice.DerivePaymentState(),
+		}
+		report := Record{
+			"display_name":              invoice.Name,
+			"type":                      invoice.Type,
+			"date":                      date(invoice.DateInvoice),
+			"invoice_date":              date(invoice.DateInvoice),
+			"date_due":                  date(invoice.DateDue),
+			"invoice_date_due":          date(invoice.DateDue),
+			"price_total":               invoice.AmountTotal,
+			"user_currency_price_total": invoice.AmountTotal,
+			"residual":                  invoice.AmountResidual,
+		}
+		move := Record{
+			"name":             invoice.Name,
+			"ref":              orFalse(invoice.Reference),
+			"date":             date(invoice.DateInvoice),
+			"invoice_date":     date(invoice.DateInvoice),
+			"invoice_date_due": date(invoice.DateDue),
+			"amount_untaxed":   invoice.AmountUntaxed,
+			"amount_tax":       invoice.AmountTax,
+			"amount_total":     invoice.AmountTotal,
+			"amount_residual":  invoice.AmountResidual,
+			"narration":        orFalse(invoice.Note),
+		}
+		for key, value := range common {
+			report[key] = value
+			move[key] = value
+		}
+		s.Insert("account.invoice.report", report)
+		s.Insert("account.move", move)
+	}
+}
+
+func many2one(id int64, name string) interface{} {
+	if id == 0 {
+		return false
+	}
+	return []interface{}{id, name}
+}
+
+func orFalse(value string) interface{} {
+	if value == "" {
+		return false
+	}
+	return value
+}
+
+func datetime(t time.Time) interface{} {
+	if t.IsZero() {
+		return false
+	}
+	return t.UTC().Format(datetimeFormat)
+}
+
+func date(t time.Time) interface{} {
+	if t.IsZero() {
+		return false
+	}
+	return t.UTC().Format(dateFormat)
+}
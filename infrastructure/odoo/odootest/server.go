@@ -0,0 +1,361 @@
+// Package odootest provides a fake Odoo XML-RPC server for integration tests
+// and offline development. It implements common.version, common.authenticate
+// and object.execute_kw (search, search_read, read, search_count, read_group,
+// write, create and fields_get) over an in-memory record store, without
+// access rights or record rules.
+package odootest
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Server is a fake Odoo instance serving one database
+type Server struct {
+	database string
+	version  string
+	store    *store
+
+	mu        sync.Mutex
+	logins    map[string]int64
+	passwords map[int64]string
+	calls     map[string]int
+	delay     time.Duration
+	failures  int
+}
+
+// NewServer creates an empty fake Odoo instance for database. Use it as an
+// http.Handler, e.g. with httptest.NewServer, and point the wrapper's Odoo URL at it.
+func NewServer(database string) *Server {
+	return &Server{
+		database:  database,
+		version:   "16.0",
+		store:     newStore(),
+		logins:    make(map[string]int64),
+		passwords: make(map[int64]string),
+		calls:     make(map[string]int),
+	}
+}
+
+// AddUser creates a res.users record that can log in with password and
+// belongs to the groups with the given external IDs (module.name). An id of
+// zero assigns the next free ID.
+func (s *Server) AddUser(id int64, login, password, name string, groups ...string) int64 {
+	groupIDs := make([]int64, 0, len(groups))
+	for _, xmlID := range groups {
+		groupIDs = append(groupIDs, s.groupID(xmlID))
+	}
+
+	uid := s.store.insert("res.users", Record{
+		"id":         id,
+		"login":      login,
+		"name":       name,
+		"email":      login + "@example.com",
+		"active":     true,
+		"groups_id":  groupIDs,
+		"login_date": false,
+	})
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.logins[login] = uid
+	s.passwords[uid] = password
+	return uid
+}
+
+// groupID returns the res.groups ID of an external ID, creating the group
+// and its ir.model.data entry on first use
+func (s *Server) groupID(xmlID string) int64 {
+	module, name, _ := strings.Cut(xmlID, ".")
+	ids, _ := s.store.search("ir.model.data", []interface{}{
+		[]interface{}{"model", "=", "res.groups"},
+		[]interface{}{"module", "=", module},
+		[]interface{}{"name", "=", name},
+	}, 0, 1, "")
+	if len(ids) > 0 {
+		records := s.store.read("ir.model.data", ids, []string{"res_id"})
+		id, _ := toInt(records[0].(Record)["res_id"])
+		return id
+	}
+
+	id := s.store.insert("res.groups", Record{"name": name})
+	s.store.insert("ir.model.data", Record{"model": "res.groups", "module": module, "name": name, "res_id": id})
+	return id
+}
+
+// Insert stores a record in model and returns its ID. Many2one values must
+// be given as []interface{}{id, name} and dates as Odoo formatted strings.
+func (s *Server) Insert(model string, record Record) int64 {
+	return s.store.insert(model, record)
+}
+
+// Records returns copies of every record of model ordered by ID
+func (s *Server) Records(model string) []Record {
+	return s.store.all(model)
+}
+
+// CallCount returns how often method was called on model through execute_kw
+func (s *Server) CallCount(model, method string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.calls[model+"."+method]
+}
+
+// SetDelay makes every object call wait d before answering, to simulate a
+// slow Odoo
+func (s *Server) SetDelay(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.delay = d
+}
+
+// SetFailures makes the next n object calls answer 502 Bad Gateway, as a
+// reverse proxy does while Odoo is down. A negative n fails every call.
+func (s *Server) SetFailures(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.failures = n
+}
+
+// ServeHTTP answers XML-RPC calls on /xmlrpc/2/common and /xmlrpc/2/object
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	method, params, err := decodeMethodCall(r.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	var result interface{}
+	switch r.URL.Path {
+	case "/xmlrpc/2/common":
+		result, err = s.common(method, params)
+	case "/xmlrpc/2/object":
+		s.mu.Lock()
+		delay, fail := s.delay, s.failures != 0
+		if s.failures > 0 {
+			s.failures--
+		}
+		s.mu.Unlock()
+		if fail {
+			http.Error(w, "bad gateway", http.StatusBadGateway)
+			return
+		}
+		select {
+		case <-time.After(delay):
+		case <-r.Context().Done():
+			return
+		}
+		result, err = s.object(method, params)
+	default:
+		http.NotFound(w, r)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/xml")
+	if err != nil {
+		w.Write(encodeFault(1, err.Error()))
+		return
+	}
+	w.Write(encodeResponse(result))
+}
+
+func (s *Server) common(method string, params []interface{}) (interface{}, error) {
+	switch method {
+	case "version":
+		return map[string]interface{}{
+			"server_version":      s.version,
+			"server_version_info": []interface{}{int64(16), int64(0), int64(0), "final", int64(0), ""},
+			"server_serie":        s.version,
+			"protocol_version":    int64(1),
+		}, nil
+	case "authenticate", "login":
+		if len(params) < 3 {
+			return nil, fmt.Errorf("%s expects database, login and password", method)
+		}
+		database, _ := params[0].(string)
+		login, _ := params[1].(string)
+		password, _ := params[2].(string)
+		if database != s.database {
+			return nil, fmt.Errorf("database %q does not exist", database)
+		}
+
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		uid, exists := s.logins[login]
+		if !exists || password == "" || s.passwords[uid] != password {
+			return false, nil
+		}
+		return uid, nil
+	}
+	return nil, fmt.Errorf("unknown method %q", method)
+}
+
+func (s *Server) object(method string, params []interface{}) (interface{}, error) {
+	if method != "execute_kw" && method != "execute" {
+		return nil, fmt.Errorf("unknown method %q", method)
+	}
+	if len(params) < 5 {
+		return nil, fmt.Errorf("%s expects database, uid, password, model and method", method)
+	}
+	database, _ := params[0].(string)
+	uid, _ := toInt(params[1])
+	password, _ := params[2].(string)
+	model, _ := params[3].(string)
+	modelMethod, _ := params[4].(string)
+	if database != s.database {
+		return nil, fmt.Errorf("database %q does not exist", database)
+	}
+
+	s.mu.Lock()
+	valid := password != "" && s.passwords[uid] == password
+	if valid {
+		s.calls[model+"."+modelMethod]++
+	}
+	s.mu.Unlock()
+	if !valid {
+		return nil, fmt.Errorf("Access Denied")
+	}
+
+	var args []interface{}
+	kwargs := map[string]interface{}{}
+	if method == "execute_kw" {
+		if len(params) > 5 {
+			args, _ = params[5].([]interface{})
+		}
+		if len(params) > 6 {
+			if m, ok := params[6].(map[string]interface{}); ok {
+				kwargs = m
+			}
+		}
+	} else {
+		args = params[5:]
+	}
+	return s.execute(model, modelMethod, call{args: args, kwargs: kwargs})
+}
+
+// call holds the positional and keyword arguments of an ORM method
+type call struct {
+	args   []interface{}
+	kwargs map[string]interface{}
+}
+
+// arg returns the argument given by keyword or at position i
+func (c call) arg(i int, name string) interface{} {
+	if value, exists := c.kwargs[name]; exists {
+		return value
+	}
+	if i < len(c.args) {
+		return c.args[i]
+	}
+	return nil
+}
+
+func (c call) domain(i int) []interface{} {
+	domain, _ := c.arg(i, "domain").([]interface{})
+	return domain
+}
+
+func (c call) int(i int, name string) int {
+	n, _ := toInt(c.arg(i, name))
+	return int(n)
+}
+
+func (c call) strings(i int, name string) []string {
+	var values []string
+	switch v := c.arg(i, name).(type) {
+	case []interface{}:
+		for _, item := range v {
+			if text, ok := item.(string); ok {
+				values = append(values, text)
+			}
+		}
+	case string:
+		if v != "" {
+			values = []string{v}
+		}
+	}
+	return values
+}
+
+func (c call) ids(i int, name string) []int64 {
+	var ids []int64
+	switch v := c.arg(i, name).(type) {
+	case []interface{}:
+		for _, item := range v {
+			if id, ok := toInt(item); ok {
+				ids = append(ids, id)
+			}
+		}
+	default:
+		if id, ok := toInt(v); ok {
+			ids = []int64{id}
+		}
+	}
+	return ids
+}
+
+func (s *Server) execute(model, method string, c call) (interface{}, error) {
+	switch method {
+	case "search":
+		order, _ := c.arg(3, "order").(string)
+		ids, err := s.store.search(model, c.domain(0), c.int(1, "offset"), c.int(2, "limit"), order)
+		if err != nil {
+			return nil, err
+		}
+		return ids, nil
+	case "search_count":
+		ids, err := s.store.search(model, c.domain(0), 0, 0, "")
+		if err != nil {
+			return nil, err
+		}
+		return int64(len(ids)), nil
+	case "search_read":
+		order, _ := c.arg(4, "order").(string)
+		ids, err := s.store.search(model, c.domain(0), c.int(2, "offset"), c.int(3, "limit"), order)
+		if err != nil {
+			return nil, err
+		}
+		return s.store.read(model, ids, c.strings(1, "fields")), nil
+	case "read":
+		return s.store.read(model, c.ids(0, "ids"), c.strings(1, "fields")), nil
+	case "read_group":
+		lazy := true
+		if value, ok := c.arg(6, "lazy").(bool); ok {
+			lazy = value
+		}
+		return s.store.readGroup(model, c.domain(0), c.strings(1, "fields"), c.strings(2, "groupby"), lazy, c.int(3, "offset"), c.int(4, "limit"))
+	case "write":
+		values, _ := c.arg(1, "vals").(map[string]interface{})
+		if err := s.store.write(model, c.ids(0, "ids"), values); err != nil {
+			return nil, err
+		}
+		return true, nil
+	case "create":
+		values, _ := c.arg(0, "vals_list").(map[string]interface{})
+		return s.store.insert(model, Record(values)), nil
+	case "fields_get":
+		wanted := c.strings(0, "allfields")
+		fields := map[string]interface{}{}
+		for name := range s.store.fieldNames(model) {
+			fields[name] = map[string]interface{}{"string": name, "type": "char"}
+		}
+		if len(wanted) > 0 {
+			filtered := map[string]interface{}{}
+			for _, name := range wanted {
+				if field, exists := fields[name]; exists {
+					filtered[name] = field
+				}
+			}
+			fields = filtered
+		}
+		return fields, nil
+	}
+	return nil, fmt.Errorf("the method %q does not exist on model %q", method, model)
+}
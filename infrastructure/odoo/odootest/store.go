@@ -0,0 +1,481 @@
+package odootest
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	dateFormat     = "2006-01-02"
+	datetimeFormat = "2006-01-02 15:04:05"
+)
+
+// Record is a model record in the shape Odoo's read returns: many2one fields
+// hold []interface{}{id, name}, x2many fields []int64 and empty fields false
+type Record map[string]interface{}
+
+// store keeps the records of every model in memory
+type store struct {
+	mu     sync.Mutex
+	models map[string]*model
+}
+
+type model struct {
+	records map[int64]Record
+	nextID  int64
+	order   string
+}
+
+// defaultOrders mirrors the _order of the models the wrapper reads
+var defaultOrders = map[string]string{
+	"sale.order":             "date_order desc, id desc",
+	"account.move":           "date desc, name desc, id desc",
+	"account.invoice.report": "date desc",
+	"res.partner":            "name",
+	"res.users":              "name",
+	"res.currency":           "name",
+	"account.journal":        "sequence, type, code",
+}
+
+func newStore() *store {
+	return &store{models: make(map[string]*model)}
+}
+
+// model returns the named model, creating it empty on first use. Callers hold s.mu.
+func (s *store) model(name string) *model {
+	m, exists := s.models[name]
+	if !exists {
+		order := defaultOrders[name]
+		if order == "" {
+			order = "id"
+		}
+		m = &model{records: make(map[int64]Record), nextID: 1, order: order}
+		s.models[name] = m
+	}
+	return m
+}
+
+// insert stores a copy of record, assigning the next ID unless it has one
+func (s *store) insert(modelName string, record Record) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m := s.model(modelName)
+	stored := Record{}
+	for key, value := range record {
+		stored[key] = value
+	}
+	id, _ := toInt(stored["id"])
+	if id == 0 {
+		id = m.nextID
+	}
+	if id >= m.nextID {
+		m.nextID = id + 1
+	}
+	stored["id"] = id
+	if _, exists := stored["display_name"]; !exists {
+		if name, ok := stored["name"].(string); ok {
+			stored["display_name"] = name
+		}
+	}
+	if _, exists := stored["write_date"]; !exists {
+		stored["write_date"] = time.Now().UTC().Format(datetimeFormat)
+	}
+	m.records[id] = stored
+	return id
+}
+
+// search returns the IDs of the records matching domain in the model's order
+func (s *store) search(modelName string, domain []interface{}, offset, limit int, order string) ([]int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m := s.model(modelName)
+	var matches []Record
+	for _, record := range m.records {
+		ok, err := s.matchDomain(domain, record)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			matches = append(matches, record)
+		}
+	}
+	if order == "" {
+		order = m.order
+	}
+	sortRecords(matches, order)
+
+	if offset > len(matches) {
+		offset = len(matches)
+	}
+	matches = matches[offset:]
+	if limit > 0 && limit < len(matches) {
+		matches = matches[:limit]
+	}
+
+	ids := make([]int64, len(matches))
+	for i, record := range matches {
+		ids[i], _ = toInt(record["id"])
+	}
+	return ids, nil
+}
+
+// read returns the records with the given IDs, restricted to fields when not
+// empty. Unknown IDs are skipped like records deleted in Odoo.
+func (s *store) read(modelName string, ids []int64, fields []string) []interface{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m := s.model(modelName)
+	result := []interface{}{}
+	for _, id := range ids {
+		record, exists := m.records[id]
+		if !exists {
+			continue
+		}
+		result = append(result, project(record, fields))
+	}
+	return result
+}
+
+// write updates the records with the given IDs and bumps their write_date
+func (s *store) write(modelName string, ids []int64, values map[string]interface{}) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	m := s.model(modelName)
+	for _, id := range ids {
+		if _, exists := m.records[id]; !exists {
+			return fmt.Errorf("record %s(%d) does not exist", modelName, id)
+		}
+	}
+	now := time.Now().UTC().Format(datetimeFormat)
+	for _, id := range ids {
+		record := m.records[id]
+		for key, value := range values {
+			// Many2one fields are written as IDs but read back as [id, name]
+			if current, ok := record[key].([]interface{}); ok && len(current) == 2 {
+				if newID, ok := toInt(value); ok {
+					value = []interface{}{newID, s.displayName(key, newID)}
+				}
+			}
+			record[key] = value
+		}
+		record["write_date"] = now
+	}
+	return nil
+}
+
+// relatedModels maps many2one field names to the model they refer to
+var relatedModels = map[string]string{
+	"partner_id":          "res.partner",
+	"partner_invoice_id":  "res.partner",
+	"partner_shipping_id": "res.partner",
+	"user_id":             "res.users",
+	"company_id":          "res.company",
+	"currency_id":         "res.currency",
+	"journal_id":          "account.journal",
+	"team_id":             "crm.team",
+}
+
+// related returns the record a many2one field refers to, or nil.
+// Callers hold s.mu.
+func (s *store) related(field string, id int64) Record {
+	if m, exists := s.models[relatedModels[field]]; exists {
+		return m.records[id]
+	}
+	return nil
+}
+
+// displayName looks up the name of a related record for many2one writes.
+// Callers hold s.mu.
+func (s *store) displayName(field string, id int64) string {
+	name, _ := s.related(field, id)["name"].(string)
+	return name
+}
+
+// fieldValue resolves a possibly dotted field path such as "partner_id.name"
+// by following many2one fields. Callers hold s.mu.
+func (s *store) fieldValue(record Record, path string) interface{} {
+	field, rest, dotted := strings.Cut(path, ".")
+	value := record[field]
+	if !dotted {
+		return value
+	}
+	pair, ok := value.([]interface{})
+	if !ok || len(pair) != 2 {
+		return false
+	}
+	id, _ := toInt(pair[0])
+	related := s.related(field, id)
+	if related == nil {
+		return false
+	}
+	return s.fieldValue(related, rest)
+}
+
+// fieldNames returns the fields present on any record of the model
+func (s *store) fieldNames(modelName string) map[string]bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	names := make(map[string]bool)
+	for _, record := range s.model(modelName).records {
+		for key := range record {
+			names[key] = true
+		}
+	}
+	return names
+}
+
+// all returns copies of every record of the model ordered by ID
+func (s *store) all(modelName string) []Record {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var records []Record
+	for _, record := range s.model(modelName).records {
+		records = append(records, project(record, nil))
+	}
+	sortRecords(records, "id")
+	return records
+}
+
+func project(record Record, fields []string) Record {
+	result := Record{}
+	if len(fields) == 0 {
+		for key, value := range record {
+			result[key] = value
+		}
+		return result
+	}
+	result["id"] = record["id"]
+	for _, field := range fields {
+		value, exists := record[field]
+		if !exists {
+			value = false
+		}
+		result[field] = value
+	}
+	return result
+}
+
+// sortRecords orders records by an Odoo order clause such as "date_order desc, id desc"
+func sortRecords(records []Record, order string) {
+	type key struct {
+		field string
+		desc  bool
+	}
+	var keys []key
+	for _, part := range strings.Split(order, ",") {
+		fields := strings.Fields(part)
+		if len(fields) == 0 {
+			continue
+		}
+		keys = append(keys, key{field: fields[0], desc: len(fields) > 1 && strings.EqualFold(fields[1], "desc")})
+	}
+	keys = append(keys, key{field: "id"})
+
+	sort.SliceStable(records, func(i, j int) bool {
+		for _, k := range keys {
+			c := compare(records[i][k.field], records[j][k.field])
+			if c == 0 {
+				continue
+			}
+			if k.desc {
+				return c > 0
+			}
+			return c < 0
+		}
+		return false
+	})
+}
+
+// matchDomain evaluates an Odoo domain in prefix notation against a record.
+// Leaves not joined by an operator are implicitly and-ed. Callers hold s.mu.
+func (s *store) matchDomain(domain []interface{}, record Record) (bool, error) {
+	var stack []bool
+	for i := len(domain) - 1; i >= 0; i-- {
+		switch term := domain[i].(type) {
+		case string:
+			switch term {
+			case "!":
+				if len(stack) < 1 {
+					return false, fmt.Errorf("invalid domain: %v", domain)
+				}
+				stack[len(stack)-1] = !stack[len(stack)-1]
+			case "&", "|":
+				if len(stack) < 2 {
+					return false, fmt.Errorf("invalid domain: %v", domain)
+				}
+				a, b := stack[len(stack)-1], stack[len(stack)-2]
+				stack = stack[:len(stack)-2]
+				if term == "&" {
+					stack = append(stack, a && b)
+				} else {
+					stack = append(stack, a || b)
+				}
+			default:
+				return false, fmt.Errorf("invalid domain operator %q", term)
+			}
+		case []interface{}:
+			if len(term) != 3 {
+				return false, fmt.Errorf("invalid domain leaf: %v", term)
+			}
+			field, _ := term[0].(string)
+			operator, _ := term[1].(string)
+			ok, err := matchLeaf(s.fieldValue(record, field), strings.ToLower(operator), term[2])
+			if err != nil {
+				return false, fmt.Errorf("invalid domain leaf %v: %v", term, err)
+			}
+			stack = append(stack, ok)
+		default:
+			return false, fmt.Errorf("invalid domain term: %v", term)
+		}
+	}
+	for _, ok := range stack {
+		if !ok {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+func matchLeaf(value interface{}, operator string, operand interface{}) (bool, error) {
+	// Many2one fields compare by ID, or by name against a string operand
+	if pair, ok := value.([]interface{}); ok && len(pair) == 2 {
+		if _, isName := operand.(string); isName {
+			value = pair[1]
+		} else {
+			value = pair[0]
+		}
+	}
+	// X2many fields match when any related ID matches
+	if ids, ok := value.([]int64); ok {
+		for _, id := range ids {
+			if matched, err := matchLeaf(id, operator, operand); err != nil || matched {
+				return matched, err
+			}
+		}
+		return operator == "not in" || operator == "!=", nil
+	}
+
+	switch operator {
+	case "=", "==":
+		return compare(value, operand) == 0, nil
+	case "!=", "<>":
+		return compare(value, operand) != 0, nil
+	case ">":
+		return !isFalse(value) && compare(value, operand) > 0, nil
+	case ">=":
+		return !isFalse(value) && compare(value, operand) >= 0, nil
+	case "<":
+		return !isFalse(value) && compare(value, operand) < 0, nil
+	case "<=":
+		return !isFalse(value) && compare(value, operand) <= 0, nil
+	case "in", "not in", "child_of":
+		list, ok := operand.([]interface{})
+		if !ok {
+			list = []interface{}{operand}
+		}
+		found := false
+		for _, item := range list {
+			if compare(value, item) == 0 {
+				found = true
+				break
+			}
+		}
+		return found == (operator != "not in"), nil
+	case "like", "ilike", "not like", "not ilike":
+		text, _ := value.(string)
+		pattern := fmt.Sprint(operand)
+		if !strings.HasPrefix(operator, "not") && isFalse(value) {
+			return false, nil
+		}
+		if strings.HasSuffix(operator, "ilike") {
+			text, pattern = strings.ToLower(text), strings.ToLower(pattern)
+		}
+		return strings.Contains(text, pattern) != strings.HasPrefix(operator, "not"), nil
+	case "=like", "=ilike":
+		text, _ := value.(string)
+		expr := "^" + strings.ReplaceAll(strings.ReplaceAll(regexp.QuoteMeta(fmt.Sprint(operand)), "%", ".*"), "_", ".") + "$"
+		if operator == "=ilike" {
+			expr = "(?i)" + expr
+		}
+		return regexp.MustCompile(expr).MatchString(text), nil
+	}
+	return false, fmt.Errorf("unsupported operator %q", operator)
+}
+
+// compare orders two field values: false sorts first, numbers numerically,
+// everything else as text
+func compare(a, b interface{}) int {
+	switch {
+	case isFalse(a) && isFalse(b):
+		return 0
+	case isFalse(a):
+		return -1
+	case isFalse(b):
+		return 1
+	}
+	if pair, ok := a.([]interface{}); ok && len(pair) == 2 {
+		a = pair[0]
+	}
+	if pair, ok := b.([]interface{}); ok && len(pair) == 2 {
+		b = pair[0]
+	}
+	if x, ok := toFloat(a); ok {
+		if y, ok := toFloat(b); ok {
+			switch {
+			case x < y:
+				return -1
+			case x > y:
+				return 1
+			}
+			return 0
+		}
+	}
+	if x, ok := a.(bool); ok {
+		if y, ok := b.(bool); ok && x == y {
+			return 0
+		}
+	}
+	return strings.Compare(fmt.Sprint(a), fmt.Sprint(b))
+}
+
+func isFalse(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	b, ok := v.(bool)
+	return ok && !b
+}
+
+func toInt(v interface{}) (int64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return n, true
+	case int:
+		return int64(n), true
+	case float64:
+		return int64(n), true
+	}
+	return 0, false
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case int:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+	return 0, false
+}
@@ -0,0 +1,244 @@
+package odootest
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// decodeMethodCall parses an XML-RPC methodCall into its method name and parameters
+func decodeMethodCall(r io.Reader) (string, []interface{}, error) {
+	dec := xml.NewDecoder(r)
+	var method string
+	var params []interface{}
+	for {
+		tok, err := dec.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", nil, fmt.Errorf("failed to parse method call: %v", err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		switch start.Name.Local {
+		case "methodName":
+			if err := dec.DecodeElement(&method, &start); err != nil {
+				return "", nil, fmt.Errorf("failed to parse method name: %v", err)
+			}
+		case "value":
+			value, err := decodeValue(dec)
+			if err != nil {
+				return "", nil, err
+			}
+			params = append(params, value)
+		}
+	}
+	if method == "" {
+		return "", nil, fmt.Errorf("failed to parse method call: no methodName")
+	}
+	return method, params, nil
+}
+
+// decodeValue reads the content of a <value> element whose start tag was consumed
+func decodeValue(dec *xml.Decoder) (interface{}, error) {
+	var text strings.Builder
+	var value interface{}
+	typed := false
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse value: %v", err)
+		}
+		switch t := tok.(type) {
+		case xml.CharData:
+			text.Write(t)
+		case xml.EndElement:
+			// An untyped <value> holds a string
+			if !typed {
+				return text.String(), nil
+			}
+			return value, nil
+		case xml.StartElement:
+			typed = true
+			if value, err = decodeTyped(dec, t); err != nil {
+				return nil, err
+			}
+		}
+	}
+}
+
+func decodeTyped(dec *xml.Decoder, start xml.StartElement) (interface{}, error) {
+	switch start.Name.Local {
+	case "array":
+		return decodeArray(dec)
+	case "struct":
+		return decodeStruct(dec)
+	case "nil":
+		return nil, dec.Skip()
+	}
+
+	var raw string
+	if err := dec.DecodeElement(&raw, &start); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", start.Name.Local, err)
+	}
+	raw = strings.TrimSpace(raw)
+	switch start.Name.Local {
+	case "int", "i4", "i8":
+		return strconv.ParseInt(raw, 10, 64)
+	case "double":
+		return strconv.ParseFloat(raw, 64)
+	case "boolean":
+		return raw == "1", nil
+	case "dateTime.iso8601":
+		return time.Parse("20060102T15:04:05", raw)
+	default:
+		// string and base64 are kept as text
+		return raw, nil
+	}
+}
+
+func decodeArray(dec *xml.Decoder) ([]interface{}, error) {
+	values := []interface{}{}
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse array: %v", err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			if t.Name.Local == "value" {
+				value, err := decodeValue(dec)
+				if err != nil {
+					return nil, err
+				}
+				values = append(values, value)
+			}
+		case xml.EndElement:
+			if t.Name.Local == "array" {
+				return values, nil
+			}
+		}
+	}
+}
+
+func decodeStruct(dec *xml.Decoder) (map[string]interface{}, error) {
+	values := map[string]interface{}{}
+	var name string
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse struct: %v", err)
+		}
+		switch t := tok.(type) {
+		case xml.StartElement:
+			switch t.Name.Local {
+			case "name":
+				if err := dec.DecodeElement(&name, &t); err != nil {
+					return nil, fmt.Errorf("failed to parse member name: %v", err)
+				}
+			case "value":
+				value, err := decodeValue(dec)
+				if err != nil {
+					return nil, err
+				}
+				values[name] = value
+			}
+		case xml.EndElement:
+			if t.Name.Local == "struct" {
+				return values, nil
+			}
+		}
+	}
+}
+
+// encodeResponse writes an XML-RPC methodResponse holding value
+func encodeResponse(value interface{}) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(`<?xml version="1.0"?><methodResponse><params><param>`)
+	encodeValue(&buf, value)
+	buf.WriteString(`</param></params></methodResponse>`)
+	return buf.Bytes()
+}
+
+// encodeFault writes an XML-RPC fault, as Odoo returns for server errors
+func encodeFault(code int, message string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString(`<?xml version="1.0"?><methodResponse><fault>`)
+	encodeValue(&buf, map[string]interface{}{"faultCode": int64(code), "faultString": message})
+	buf.WriteString(`</fault></methodResponse>`)
+	return buf.Bytes()
+}
+
+// encodeValue writes value the way Odoo does: nil as false, times as strings
+func encodeValue(buf *bytes.Buffer, value interface{}) {
+	if record, ok := value.(Record); ok {
+		value = map[string]interface{}(record)
+	}
+	buf.WriteString("<value>")
+	switch v := value.(type) {
+	case nil:
+		buf.WriteString("<boolean>0</boolean>")
+	case bool:
+		if v {
+			buf.WriteString("<boolean>1</boolean>")
+		} else {
+			buf.WriteString("<boolean>0</boolean>")
+		}
+	case int:
+		fmt.Fprintf(buf, "<int>%d</int>", v)
+	case int64:
+		fmt.Fprintf(buf, "<int>%d</int>", v)
+	case float64:
+		fmt.Fprintf(buf, "<double>%s</double>", strconv.FormatFloat(v, 'f', -1, 64))
+	case string:
+		buf.WriteString("<string>")
+		xml.EscapeText(buf, []byte(v))
+		buf.WriteString("</string>")
+	case time.Time:
+		encodeValue(buf, v.UTC().Format(datetimeFormat))
+	case []int64:
+		buf.WriteString("<array><data>")
+		for _, item := range v {
+			encodeValue(buf, item)
+		}
+		buf.WriteString("</data></array>")
+	case []interface{}:
+		buf.WriteString("<array><data>")
+		for _, item := range v {
+			encodeValue(buf, item)
+		}
+		buf.WriteString("</data></array>")
+	case []map[string]interface{}:
+		buf.WriteString("<array><data>")
+		for _, item := range v {
+			encodeValue(buf, item)
+		}
+		buf.WriteString("</data></array>")
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		buf.WriteString("<struct>")
+		for _, key := range keys {
+			buf.WriteString("<member><name>")
+			xml.EscapeText(buf, []byte(key))
+			buf.WriteString("</name>")
+			encodeValue(buf, v[key])
+			buf.WriteString("</member>")
+		}
+		buf.WriteString("</struct>")
+	default:
+		encodeValue(buf, fmt.Sprint(v))
+	}
+	buf.WriteString("</value>")
+}
@@ -0,0 +1,26 @@
+package policy
+
+import (
+	"fmt"
+	"nerp_wrapper/domain/entity"
+	"os"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LoadAccessPolicy reads an access policy from a YAML (or JSON) file
+func LoadAccessPolicy(path string) (*entity.AccessPolicy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read access policy: %v", err)
+	}
+
+	var policy entity.AccessPolicy
+	if err := yaml.Unmarshal(data, &policy); err != nil {
+		return nil, fmt.Errorf("failed to parse access policy: %v", err)
+	}
+	if len(policy.Groups) == 0 {
+		return nil, fmt.Errorf("access policy %s does not map any group", path)
+	}
+	return &policy, nil
+}
@@ -0,0 +1,65 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// Open opens (and creates if needed) the SQLite database file at path
+func Open(path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite", fmt.Sprintf("file:%s?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)", path))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open sqlite database: %v", err)
+	}
+	// SQLite allows a single writer; serialize access through one connection
+	db.SetMaxOpenConns(1)
+	if err := db.Ping(); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to open sqlite database: %v", err)
+	}
+	return db, nil
+}
+
+func nullTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return t.UnixNano()
+}
+
+func timePtr(v sql.NullInt64) *time.Time {
+	if !v.Valid {
+		return nil
+	}
+	t := time.Unix(0, v.Int64)
+	return &t
+}
+
+// hasColumn reports whether a table has a column
+func hasColumn(db *sql.DB, table, column string) (bool, error) {
+	var count int
+	err := db.QueryRow(`SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`, table, column).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("failed to inspect table %s: %v", table, err)
+	}
+	return count > 0, nil
+}
+
+// addTenantColumn adds tenant_id to a table created before tenants existed,
+// assigning its rows to the default tenant
+func addTenantColumn(db *sql.DB, table, defaultTenant string) error {
+	exists, err := hasColumn(db, table, "tenant_id")
+	if err != nil || exists {
+		return err
+	}
+	// ALTER TABLE takes no parameters, so the default is quoted as a literal
+	tenant := "'" + strings.ReplaceAll(defaultTenant, "'", "''") + "'"
+	if _, err := db.Exec(`ALTER TABLE ` + table + ` ADD COLUMN tenant_id TEXT NOT NULL DEFAULT ` + tenant); err != nil {
+		return fmt.Errorf("failed to add tenant_id to %s: %v", table, err)
+	}
+	return nil
+}
@@ -0,0 +1,156 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"nerp_wrapper/domain/entity"
+	"time"
+)
+
+// SQLiteAPIKeyRepository implements APIKeyRepository interface using a SQLite database
+type SQLiteAPIKeyRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteAPIKeyRepository creates a new instance of SQLiteAPIKeyRepository.
+// Keys stored before tenants existed are assigned to defaultTenant.
+func NewSQLiteAPIKeyRepository(db *sql.DB, defaultTenant string) (*SQLiteAPIKeyRepository, error) {
+	schema := `
+CREATE TABLE IF NOT EXISTS api_keys (
+	id           TEXT PRIMARY KEY,
+	tenant_id    TEXT NOT NULL,
+	name         TEXT NOT NULL,
+	prefix       TEXT NOT NULL,
+	key_hash     TEXT NOT NULL UNIQUE,
+	scopes       TEXT NOT NULL,
+	allowed_ips  TEXT NOT NULL,
+	created_by   INTEGER NOT NULL,
+	created_at   INTEGER NOT NULL,
+	expires_at   INTEGER,
+	last_used_at INTEGER,
+	revoked_at   INTEGER
+);`
+	if _, err := db.Exec(schema); err != nil {
+		return nil, fmt.Errorf("failed to create api key table: %v", err)
+	}
+	if err := addTenantColumn(db, "api_keys", defaultTenant); err != nil {
+		return nil, err
+	}
+	return &SQLiteAPIKeyRepository{db: db}, nil
+}
+
+// CreateAPIKey stores a new API key
+func (r *SQLiteAPIKeyRepository) CreateAPIKey(key *entity.APIKey) error {
+	scopes, err := json.Marshal(key.Scopes)
+	if err != nil {
+		return fmt.Errorf("failed to encode api key scopes: %v", err)
+	}
+	allowedIPs, err := json.Marshal(key.AllowedIPs)
+	if err != nil {
+		return fmt.Errorf("failed to encode api key allowed IPs: %v", err)
+	}
+
+	_, err = r.db.Exec(
+		`INSERT INTO api_keys (id, tenant_id, name, prefix, key_hash, scopes, allowed_ips, created_by, created_at, expires_at, last_used_at, revoked_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		key.ID, key.TenantID, key.Name, key.Prefix, key.KeyHash, string(scopes), string(allowedIPs),
+		key.CreatedBy, key.CreatedAt.UnixNano(),
+		nullTime(key.ExpiresAt), nullTime(key.LastUsedAt), nullTime(key.RevokedAt),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save api key: %v", err)
+	}
+	return nil
+}
+
+// ListAPIKeys retrieves every API key of a tenant, newest first
+func (r *SQLiteAPIKeyRepository) ListAPIKeys(tenantID string) ([]*entity.APIKey, error) {
+	rows, err := r.db.Query(apiKeySelect+` WHERE tenant_id = ? ORDER BY created_at DESC`, tenantID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %v", err)
+	}
+	defer rows.Close()
+
+	keys := []*entity.APIKey{}
+	for rows.Next() {
+		key, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, key)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list api keys: %v", err)
+	}
+	return keys, nil
+}
+
+// GetAPIKeyByHash retrieves an API key by the hash of its value
+func (r *SQLiteAPIKeyRepository) GetAPIKeyByHash(keyHash string) (*entity.APIKey, error) {
+	key, err := scanAPIKey(r.db.QueryRow(apiKeySelect+` WHERE key_hash = ?`, keyHash))
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	return key, err
+}
+
+// RevokeAPIKey marks an API key of a tenant as revoked
+func (r *SQLiteAPIKeyRepository) RevokeAPIKey(tenantID, id string, revokedAt time.Time) (bool, error) {
+	result, err := r.db.Exec(
+		`UPDATE api_keys SET revoked_at = COALESCE(revoked_at, ?) WHERE id = ? AND tenant_id = ?`,
+		revokedAt.UnixNano(), id, tenantID,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke api key: %v", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to revoke api key: %v", err)
+	}
+	return affected > 0, nil
+}
+
+// TouchAPIKey records the time an API key was last used
+func (r *SQLiteAPIKeyRepository) TouchAPIKey(id string, usedAt time.Time) error {
+	if _, err := r.db.Exec(`UPDATE api_keys SET last_used_at = ? WHERE id = ?`, usedAt.UnixNano(), id); err != nil {
+		return fmt.Errorf("failed to record api key usage: %v", err)
+	}
+	return nil
+}
+
+const apiKeySelect = `SELECT id, tenant_id, name, prefix, key_hash, scopes, allowed_ips, created_by, created_at, expires_at, last_used_at, revoked_at FROM api_keys`
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...interface{}) error
+}
+
+func scanAPIKey(row rowScanner) (*entity.APIKey, error) {
+	var key entity.APIKey
+	var scopes, allowedIPs string
+	var createdAt int64
+	var expiresAt, lastUsedAt, revokedAt sql.NullInt64
+
+	err := row.Scan(&key.ID, &key.TenantID, &key.Name, &key.Prefix, &key.KeyHash, &scopes, &allowedIPs,
+		&key.CreatedBy, &createdAt, &expiresAt, &lastUsedAt, &revokedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, err
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read api key: %v", err)
+	}
+	if err := json.Unmarshal([]byte(scopes), &key.Scopes); err != nil {
+		return nil, fmt.Errorf("failed to decode api key scopes: %v", err)
+	}
+	if err := json.Unmarshal([]byte(allowedIPs), &key.AllowedIPs); err != nil {
+		return nil, fmt.Errorf("failed to decode api key allowed IPs: %v", err)
+	}
+
+	key.CreatedAt = time.Unix(0, createdAt)
+	key.ExpiresAt = timePtr(expiresAt)
+	key.LastUsedAt = timePtr(lastUsedAt)
+	key.RevokedAt = timePtr(revokedAt)
+	return &key, nil
+}
@@ -0,0 +1,148 @@
+package sqlite
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"nerp_wrapper/domain/entity"
+	"strings"
+	"time"
+)
+
+// SQLiteAuditRepository implements AuditRepository interface using a SQLite database
+type SQLiteAuditRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteAuditRepository creates a new instance of SQLiteAuditRepository.
+// Events recorded before tenants existed are assigned to defaultTenant.
+func NewSQLiteAuditRepository(db *sql.DB, defaultTenant string) (*SQLiteAuditRepository, error) {
+	table := `
+CREATE TABLE IF NOT EXISTS audit_events (
+	seq       INTEGER PRIMARY KEY AUTOINCREMENT,
+	id        TEXT NOT NULL UNIQUE,
+	time      INTEGER NOT NULL,
+	action    TEXT NOT NULL,
+	tenant_id TEXT NOT NULL,
+	user_id   INTEGER NOT NULL,
+	login     TEXT NOT NULL,
+	client_ip TEXT NOT NULL,
+	route     TEXT NOT NULL,
+	filters   TEXT NOT NULL,
+	row_count INTEGER NOT NULL,
+	status    INTEGER NOT NULL,
+	detail    TEXT NOT NULL
+);`
+	if _, err := db.Exec(table); err != nil {
+		return nil, fmt.Errorf("failed to create audit table: %v", err)
+	}
+	// The tenant index needs the column, so the table is migrated first
+	if err := addTenantColumn(db, "audit_events", defaultTenant); err != nil {
+		return nil, err
+	}
+
+	// The triggers keep the table append-only
+	schema := `
+CREATE INDEX IF NOT EXISTS audit_events_time ON audit_events (time);
+CREATE INDEX IF NOT EXISTS audit_events_tenant ON audit_events (tenant_id, time);
+CREATE INDEX IF NOT EXISTS audit_events_login ON audit_events (login, time);
+CREATE INDEX IF NOT EXISTS audit_events_action ON audit_events (action, time);
+CREATE TRIGGER IF NOT EXISTS audit_events_no_update BEFORE UPDATE ON audit_events
+BEGIN
+	SELECT RAISE(ABORT, 'audit events are append-only');
+END;
+CREATE TRIGGER IF NOT EXISTS audit_events_no_delete BEFORE DELETE ON audit_events
+BEGIN
+	SELECT RAISE(ABORT, 'audit events are append-only');
+END;`
+	if _, err := db.Exec(schema); err != nil {
+		return nil, fmt.Errorf("failed to create audit table: %v", err)
+	}
+	return &SQLiteAuditRepository{db: db}, nil
+}
+
+// AppendAuditEvent stores an audit event
+func (r *SQLiteAuditRepository) AppendAuditEvent(event *entity.AuditEvent) error {
+	filters, err := json.Marshal(event.Filters)
+	if err != nil {
+		return fmt.Errorf("failed to encode audit filters: %v", err)
+	}
+
+	_, err = r.db.Exec(
+		`INSERT INTO audit_events (id, time, action, tenant_id, user_id, login, client_ip, route, filters, row_count, status, detail)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		event.ID, event.Time.UnixNano(), string(event.Action), event.TenantID, event.UserID, event.Login,
+		event.ClientIP, event.Route, string(filters), event.RowCount, event.Status, event.Detail,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save audit event: %v", err)
+	}
+	return nil
+}
+
+// ListAuditEvents retrieves the events matching the filter, newest first
+func (r *SQLiteAuditRepository) ListAuditEvents(filter entity.AuditFilter) ([]*entity.AuditEvent, error) {
+	var conditions []string
+	var args []interface{}
+	if filter.TenantID != "" {
+		conditions = append(conditions, "tenant_id = ?")
+		args = append(args, filter.TenantID)
+	}
+	if filter.UserID != 0 {
+		conditions = append(conditions, "user_id = ?")
+		args = append(args, filter.UserID)
+	}
+	if filter.Login != "" {
+		conditions = append(conditions, "login = ?")
+		args = append(args, filter.Login)
+	}
+	if filter.Action != "" {
+		conditions = append(conditions, "action = ?")
+		args = append(args, string(filter.Action))
+	}
+	if filter.From != nil {
+		conditions = append(conditions, "time >= ?")
+		args = append(args, filter.From.UnixNano())
+	}
+	if filter.To != nil {
+		conditions = append(conditions, "time <= ?")
+		args = append(args, filter.To.UnixNano())
+	}
+
+	query := `SELECT id, time, action, tenant_id, user_id, login, client_ip, route, filters, row_count, status, detail FROM audit_events`
+	if len(conditions) > 0 {
+		query += " WHERE " + strings.Join(conditions, " AND ")
+	}
+	query += " ORDER BY seq DESC"
+	if filter.Limit > 0 {
+		query += " LIMIT ?"
+		args = append(args, filter.Limit)
+	}
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list audit events: %v", err)
+	}
+	defer rows.Close()
+
+	events := []*entity.AuditEvent{}
+	for rows.Next() {
+		var event entity.AuditEvent
+		var eventTime int64
+		var action, filters string
+		if err := rows.Scan(&event.ID, &eventTime, &action, &event.TenantID, &event.UserID, &event.Login, &event.ClientIP,
+			&event.Route, &filters, &event.RowCount, &event.Status, &event.Detail); err != nil {
+			return nil, fmt.Errorf("failed to read audit event: %v", err)
+		}
+		if err := json.Unmarshal([]byte(filters), &event.Filters); err != nil {
+			return nil, fmt.Errorf("failed to decode audit filters: %v", err)
+		}
+		event.Time = time.Unix(0, eventTime)
+		event.Action = entity.AuditAction(action)
+		events = append(events, &event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to list audit events: %v", err)
+	}
+	return events, nil
+}
@@ -0,0 +1,35 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"nerp_wrapper/domain/entity"
+	"time"
+)
+
+// SQLiteHealthChecker probes the SQLite store
+type SQLiteHealthChecker struct {
+	db *sql.DB
+}
+
+// NewSQLiteHealthChecker creates a new instance of SQLiteHealthChecker
+func NewSQLiteHealthChecker(db *sql.DB) *SQLiteHealthChecker {
+	return &SQLiteHealthChecker{db: db}
+}
+
+// Name identifies the store in readiness reports
+func (h *SQLiteHealthChecker) Name() string {
+	return "store:sqlite"
+}
+
+// CheckHealth pings the database
+func (h *SQLiteHealthChecker) CheckHealth(ctx context.Context) entity.DependencyStatus {
+	start := time.Now()
+	status := entity.DependencyStatus{Name: h.Name(), Status: entity.DependencyUp}
+	if err := h.db.PingContext(ctx); err != nil {
+		status.Status = entity.DependencyDown
+		status.Error = err.Error()
+	}
+	status.LatencyMs = time.Since(start).Milliseconds()
+	return status
+}
@@ -0,0 +1,162 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"nerp_wrapper/domain/entity"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := Open(filepath.Join(t.TempDir(), "store.db"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+func TestTokenRepository(t *testing.T) {
+	repo, err := NewSQLiteTokenRepository(openTestDB(t), "default")
+	if err != nil {
+		t.Fatalf("NewSQLiteTokenRepository failed: %v", err)
+	}
+	now := time.Now()
+	for _, hash := range []string{"first", "second"} {
+		token := &entity.RefreshToken{TokenHash: hash, FamilyID: "family", TenantID: "acme", UserID: 6, Login: "sales", IssuedAt: now, ExpiresAt: now.Add(time.Hour)}
+		if err := repo.SaveRefreshToken(token); err != nil {
+			t.Fatalf("SaveRefreshToken failed: %v", err)
+		}
+	}
+
+	// Consuming returns the token as it was, so a second consumption shows the reuse
+	consumed, err := repo.ConsumeRefreshToken("first", now)
+	if err != nil || consumed == nil || consumed.UsedAt != nil || consumed.TenantID != "acme" {
+		t.Fatalf("first ConsumeRefreshToken returned %+v, %v", consumed, err)
+	}
+	reused, err := repo.ConsumeRefreshToken("first", now.Add(time.Second))
+	if err != nil || reused == nil || reused.UsedAt == nil || !reused.UsedAt.Equal(time.Unix(0, now.UnixNano())) {
+		t.Fatalf("second ConsumeRefreshToken returned %+v, %v", reused, err)
+	}
+	if missing, err := repo.ConsumeRefreshToken("unknown", now); missing != nil || err != nil {
+		t.Errorf("ConsumeRefreshToken of an unknown token returned %+v, %v", missing, err)
+	}
+
+	if err := repo.RevokeRefreshTokenFamily("family", now); err != nil {
+		t.Fatalf("RevokeRefreshTokenFamily failed: %v", err)
+	}
+	if second, err := repo.GetRefreshToken("second"); err != nil || second == nil || second.RevokedAt == nil {
+		t.Errorf("token of the revoked family is %+v, %v", second, err)
+	}
+
+	if err := repo.RevokeAccessToken("access", now.Add(time.Minute)); err != nil {
+		t.Fatalf("RevokeAccessToken failed: %v", err)
+	}
+	if revoked, err := repo.IsAccessTokenRevoked("access"); err != nil || !revoked {
+		t.Errorf("IsAccessTokenRevoked returned %v, %v", revoked, err)
+	}
+	if err := repo.DeleteExpired(now.Add(2 * time.Hour)); err != nil {
+		t.Fatalf("DeleteExpired failed: %v", err)
+	}
+	if token, err := repo.GetRefreshToken("second"); err != nil || token != nil {
+		t.Errorf("expired refresh token is %+v, %v", token, err)
+	}
+	if revoked, err := repo.IsAccessTokenRevoked("access"); err != nil || revoked {
+		t.Errorf("expired blacklist entry is still revoked: %v, %v", revoked, err)
+	}
+}
+
+func TestStoresFromBeforeTenantsAreMigrated(t *testing.T) {
+	db := openTestDB(t)
+	legacy := `
+CREATE TABLE refresh_tokens (
+	token_hash TEXT PRIMARY KEY,
+	family_id  TEXT NOT NULL,
+	user_id    INTEGER NOT NULL,
+	login      TEXT NOT NULL,
+	issued_at  INTEGER NOT NULL,
+	expires_at INTEGER NOT NULL,
+	used_at    INTEGER,
+	revoked_at INTEGER
+);
+CREATE TABLE totp_enrollments (
+	user_id        INTEGER PRIMARY KEY,
+	secret         TEXT NOT NULL,
+	confirmed      INTEGER NOT NULL,
+	created_at     INTEGER NOT NULL,
+	confirmed_at   INTEGER,
+	last_used_step INTEGER NOT NULL
+);
+INSERT INTO refresh_tokens VALUES ('hash', 'family', 6, 'sales', 1, 4102444800000000000, NULL, NULL);
+INSERT INTO totp_enrollments VALUES (6, 'SECRET', 1, 1, 1, 42);`
+	if _, err := db.Exec(legacy); err != nil {
+		t.Fatalf("failed to create legacy tables: %v", err)
+	}
+
+	tokens, err := NewSQLiteTokenRepository(db, "acme")
+	if err != nil {
+		t.Fatalf("NewSQLiteTokenRepository failed: %v", err)
+	}
+	token, err := tokens.GetRefreshToken("hash")
+	if err != nil || token == nil || token.TenantID != "acme" || token.UserID != 6 {
+		t.Errorf("migrated refresh token is %+v, %v", token, err)
+	}
+
+	totp, err := NewSQLiteTOTPRepository(db, "acme")
+	if err != nil {
+		t.Fatalf("NewSQLiteTOTPRepository failed: %v", err)
+	}
+	enrollment, err := totp.GetTOTPEnrollment("acme", 6)
+	if err != nil || enrollment == nil || enrollment.Secret != "SECRET" || !enrollment.Confirmed || enrollment.LastUsedStep != 42 {
+		t.Fatalf("migrated enrollment is %+v, %v", enrollment, err)
+	}
+	// The same user ID may now enroll in another tenant
+	other := &entity.TOTPEnrollment{TenantID: "beta", UserID: 6, Secret: "OTHER", CreatedAt: time.Now()}
+	if err := totp.SaveTOTPEnrollment(other); err != nil {
+		t.Fatalf("SaveTOTPEnrollment in another tenant failed: %v", err)
+	}
+	if enrollment, err := totp.GetTOTPEnrollment("acme", 6); err != nil || enrollment.Secret != "SECRET" {
+		t.Errorf("enrollment of another tenant replaced the migrated one: %+v, %v", enrollment, err)
+	}
+
+	// Opening a migrated store again leaves it as it is
+	if _, err := NewSQLiteTOTPRepository(db, "other"); err != nil {
+		t.Fatalf("reopening the migrated store failed: %v", err)
+	}
+	if enrollment, err := totp.GetTOTPEnrollment("acme", 6); err != nil || enrollment == nil {
+		t.Errorf("enrollment after reopening is %+v, %v", enrollment, err)
+	}
+}
+
+func TestAuditEventsAreAppendOnly(t *testing.T) {
+	db := openTestDB(t)
+	repo, err := NewSQLiteAuditRepository(db, "default")
+	if err != nil {
+		t.Fatalf("NewSQLiteAuditRepository failed: %v", err)
+	}
+	start := time.Now()
+	for i, login := range []string{"admin", "sales", "admin"} {
+		event := &entity.AuditEvent{ID: fmt.Sprintf("%s%d", login, i), Time: start.Add(time.Duration(i) * time.Second), Action: entity.AuditActionLoginSuccess, TenantID: "default", Login: login}
+		if err := repo.AppendAuditEvent(event); err != nil {
+			t.Fatalf("AppendAuditEvent failed: %v", err)
+		}
+	}
+
+	events, err := repo.ListAuditEvents(entity.AuditFilter{Login: "admin", Limit: 10})
+	if err != nil {
+		t.Fatalf("ListAuditEvents failed: %v", err)
+	}
+	if len(events) != 2 || events[0].ID != "admin2" || events[1].ID != "admin0" {
+		t.Errorf("events of admin are %+v, want admin2 then admin0", events)
+	}
+
+	if _, err := db.Exec(`UPDATE audit_events SET login = 'other'`); err == nil {
+		t.Error("audit events could be updated")
+	}
+	if _, err := db.Exec(`DELETE FROM audit_events`); err == nil {
+		t.Error("audit events could be deleted")
+	}
+}
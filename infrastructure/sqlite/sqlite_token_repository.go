@@ -0,0 +1,158 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"nerp_wrapper/domain/entity"
+	"time"
+)
+
+// SQLiteTokenRepository implements TokenRepository interface using a SQLite database
+type SQLiteTokenRepository struct {
+	db *sql.DB
+}
+
+// NewSQLiteTokenRepository creates a new instance of SQLiteTokenRepository.
+// Refresh tokens stored before tenants existed are assigned to defaultTenant.
+func NewSQLiteTokenRepository(db *sql.DB, defaultTenant string) (*SQLiteTokenRepository, error) {
+	schema := `
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+	token_hash TEXT PRIMARY KEY,
+	family_id  TEXT NOT NULL,
+	tenant_id  TEXT NOT NULL,
+	user_id    INTEGER NOT NULL,
+	login      TEXT NOT NULL,
+	issued_at  INTEGER NOT NULL,
+	expires_at INTEGER NOT NULL,
+	used_at    INTEGER,
+	revoked_at INTEGER
+);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family ON refresh_tokens (family_id);
+CREATE TABLE IF NOT EXISTS revoked_access_tokens (
+	token_id   TEXT PRIMARY KEY,
+	expires_at INTEGER NOT NULL
+);`
+	if _, err := db.Exec(schema); err != nil {
+		return nil, fmt.Errorf("failed to create token tables: %v", err)
+	}
+	if err := addTenantColumn(db, "refresh_tokens", defaultTenant); err != nil {
+		return nil, err
+	}
+	return &SQLiteTokenRepository{db: db}, nil
+}
+
+// SaveRefreshToken stores a newly issued refresh token
+func (r *SQLiteTokenRepository) SaveRefreshToken(token *entity.RefreshToken) error {
+	_, err := r.db.Exec(
+		`INSERT INTO refresh_tokens (token_hash, family_id, tenant_id, user_id, login, issued_at, expires_at, used_at, revoked_at)
+		 VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		token.TokenHash, token.FamilyID, token.TenantID, token.UserID, token.Login,
+		token.IssuedAt.UnixNano(), token.ExpiresAt.UnixNano(),
+		nullTime(token.UsedAt), nullTime(token.RevokedAt),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save refresh token: %v", err)
+	}
+	return nil
+}
+
+// ConsumeRefreshToken marks a refresh token as used and returns its previous state
+func (r *SQLiteTokenRepository) ConsumeRefreshToken(tokenHash string, usedAt time.Time) (*entity.RefreshToken, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to consume refresh token: %v", err)
+	}
+	defer tx.Rollback()
+
+	token, err := scanRefreshToken(tx.QueryRow(refreshTokenSelect+` WHERE token_hash = ?`, tokenHash))
+	if err != nil || token == nil {
+		return nil, err
+	}
+
+	if token.UsedAt == nil {
+		if _, err := tx.Exec(`UPDATE refresh_tokens SET used_at = ? WHERE token_hash = ?`, usedAt.UnixNano(), tokenHash); err != nil {
+			return nil, fmt.Errorf("failed to consume refresh token: %v", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to consume refresh token: %v", err)
+	}
+	return token, nil
+}
+
+// GetRefreshToken retrieves a refresh token by hash
+func (r *SQLiteTokenRepository) GetRefreshToken(tokenHash string) (*entity.RefreshToken, error) {
+	return scanRefreshToken(r.db.QueryRow(refreshTokenSelect+` WHERE token_hash = ?`, tokenHash))
+}
+
+// RevokeRefreshTokenFamily revokes every refresh token of a family
+func (r *SQLiteTokenRepository) RevokeRefreshTokenFamily(familyID string, revokedAt time.Time) error {
+	_, err := r.db.Exec(
+		`UPDATE refresh_tokens SET revoked_at = ? WHERE family_id = ? AND revoked_at IS NULL`,
+		revokedAt.UnixNano(), familyID,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to revoke refresh token family: %v", err)
+	}
+	return nil
+}
+
+// RevokeAccessToken blacklists an access token until it expires
+func (r *SQLiteTokenRepository) RevokeAccessToken(tokenID string, expiresAt time.Time) error {
+	_, err := r.db.Exec(
+		`INSERT OR REPLACE INTO revoked_access_tokens (token_id, expires_at) VALUES (?, ?)`,
+		tokenID, expiresAt.UnixNano(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to revoke access token: %v", err)
+	}
+	return nil
+}
+
+// IsAccessTokenRevoked reports whether an access token has been blacklisted
+func (r *SQLiteTokenRepository) IsAccessTokenRevoked(tokenID string) (bool, error) {
+	var exists int
+	err := r.db.QueryRow(`SELECT 1 FROM revoked_access_tokens WHERE token_id = ?`, tokenID).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check access token revocation: %v", err)
+	}
+	return true, nil
+}
+
+// DeleteExpired removes refresh tokens and blacklist entries expired before the given time
+func (r *SQLiteTokenRepository) DeleteExpired(before time.Time) error {
+	if _, err := r.db.Exec(`DELETE FROM refresh_tokens WHERE expires_at < ?`, before.UnixNano()); err != nil {
+		return fmt.Errorf("failed to delete expired refresh tokens: %v", err)
+	}
+	if _, err := r.db.Exec(`DELETE FROM revoked_access_tokens WHERE expires_at < ?`, before.UnixNano()); err != nil {
+		return fmt.Errorf("failed to delete expired access token revocations: %v", err)
+	}
+	return nil
+}
+
+const refreshTokenSelect = `SELECT token_hash, family_id, tenant_id, user_id, login, issued_at, expires_at, used_at, revoked_at FROM refresh_tokens`
+
+func scanRefreshToken(row *sql.Row) (*entity.RefreshToken, error) {
+	var token entity.RefreshToken
+	var issuedAt, expiresAt int64
+	var usedAt, revokedAt sql.NullInt64
+
+	err := row.Scan(&token.TokenHash, &token.FamilyID, &token.TenantID, &token.UserID, &token.Login, &issuedAt, &expiresAt, &usedAt, &revokedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read refresh token: %v", err)
+	}
+
+	token.IssuedAt = time.Unix(0, issuedAt)
+	token.ExpiresAt = time.Unix(0, expiresAt)
+	token.UsedAt = timePtr(usedAt)
+	token.RevokedAt = timePtr(revokedAt)
+	return &token, nil
+}
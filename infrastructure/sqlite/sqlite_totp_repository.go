@@ -0,0 +1,117 @@
+package sqlite
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"nerp_wrapper/domain/entity"
+	"time"
+)
+
+// SQLiteTOTPRepository implements TOTPRepository interface using a SQLite database
+type SQLiteTOTPRepository struct {
+	db *sql.DB
+}
+
+// totpSchema creates the enrollment table keyed by tenant and user
+const totpSchema = `
+CREATE TABLE IF NOT EXISTS totp_enrollments (
+	tenant_id      TEXT NOT NULL,
+	user_id        INTEGER NOT NULL,
+	secret         TEXT NOT NULL,
+	confirmed      INTEGER NOT NULL,
+	created_at     INTEGER NOT NULL,
+	confirmed_at   INTEGER,
+	last_used_step INTEGER NOT NULL,
+	PRIMARY KEY (tenant_id, user_id)
+);`
+
+// NewSQLiteTOTPRepository creates a new instance of SQLiteTOTPRepository.
+// Enrollments stored before tenants existed are assigned to defaultTenant.
+func NewSQLiteTOTPRepository(db *sql.DB, defaultTenant string) (*SQLiteTOTPRepository, error) {
+	if _, err := db.Exec(totpSchema); err != nil {
+		return nil, fmt.Errorf("failed to create totp table: %v", err)
+	}
+	if err := migrateTOTPTable(db, defaultTenant); err != nil {
+		return nil, err
+	}
+	return &SQLiteTOTPRepository{db: db}, nil
+}
+
+// migrateTOTPTable rebuilds an enrollment table keyed by user alone, from
+// before tenants existed, since SQLite cannot change a primary key in place
+func migrateTOTPTable(db *sql.DB, defaultTenant string) error {
+	exists, err := hasColumn(db, "totp_enrollments", "tenant_id")
+	if err != nil || exists {
+		return err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to migrate totp table: %v", err)
+	}
+	defer tx.Rollback()
+	steps := []struct {
+		statement string
+		args      []interface{}
+	}{
+		{`ALTER TABLE totp_enrollments RENAME TO totp_enrollments_untenanted`, nil},
+		{totpSchema, nil},
+		{`INSERT INTO totp_enrollments (tenant_id, user_id, secret, confirmed, created_at, confirmed_at, last_used_step)
+		  SELECT ?, user_id, secret, confirmed, created_at, confirmed_at, last_used_step FROM totp_enrollments_untenanted`, []interface{}{defaultTenant}},
+		{`DROP TABLE totp_enrollments_untenanted`, nil},
+	}
+	for _, step := range steps {
+		if _, err := tx.Exec(step.statement, step.args...); err != nil {
+			return fmt.Errorf("failed to migrate totp table: %v", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to migrate totp table: %v", err)
+	}
+	return nil
+}
+
+// GetTOTPEnrollment retrieves the enrollment of a user
+func (r *SQLiteTOTPRepository) GetTOTPEnrollment(tenantID string, userID int64) (*entity.TOTPEnrollment, error) {
+	var enrollment entity.TOTPEnrollment
+	var createdAt int64
+	var confirmedAt sql.NullInt64
+
+	err := r.db.QueryRow(
+		`SELECT tenant_id, user_id, secret, confirmed, created_at, confirmed_at, last_used_step FROM totp_enrollments WHERE tenant_id = ? AND user_id = ?`,
+		tenantID, userID,
+	).Scan(&enrollment.TenantID, &enrollment.UserID, &enrollment.Secret, &enrollment.Confirmed, &createdAt, &confirmedAt, &enrollment.LastUsedStep)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read totp enrollment: %v", err)
+	}
+
+	enrollment.CreatedAt = time.Unix(0, createdAt)
+	enrollment.ConfirmedAt = timePtr(confirmedAt)
+	return &enrollment, nil
+}
+
+// SaveTOTPEnrollment creates or replaces the enrollment of a user
+func (r *SQLiteTOTPRepository) SaveTOTPEnrollment(enrollment *entity.TOTPEnrollment) error {
+	_, err := r.db.Exec(
+		`INSERT OR REPLACE INTO totp_enrollments (tenant_id, user_id, secret, confirmed, created_at, confirmed_at, last_used_step)
+		 VALUES (?, ?, ?, ?, ?, ?, ?)`,
+		enrollment.TenantID, enrollment.UserID, enrollment.Secret, enrollment.Confirmed,
+		enrollment.CreatedAt.UnixNano(), nullTime(enrollment.ConfirmedAt), enrollment.LastUsedStep,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save totp enrollment: %v", err)
+	}
+	return nil
+}
+
+// DeleteTOTPEnrollment removes the enrollment of a user
+func (r *SQLiteTOTPRepository) DeleteTOTPEnrollment(tenantID string, userID int64) error {
+	if _, err := r.db.Exec(`DELETE FROM totp_enrollments WHERE tenant_id = ? AND user_id = ?`, tenantID, userID); err != nil {
+		return fmt.Errorf("failed to delete totp enrollment: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,125 @@
+package handler
+
+import (
+	"errors"
+	"nerp_wrapper/application/dto"
+	"nerp_wrapper/application/service"
+	"nerp_wrapper/domain/entity"
+	"nerp_wrapper/interfaces/http/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// APIKeyHandler handles HTTP requests for API key administration
+type APIKeyHandler struct {
+	apiKeyService *service.APIKeyService
+}
+
+// NewAPIKeyHandler creates a new instance of APIKeyHandler
+func NewAPIKeyHandler(apiKeyService *service.APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{apiKeyService: apiKeyService}
+}
+
+// CreateAPIKey handles POST request to create an API key
+func (h *APIKeyHandler) CreateAPIKey(c *fiber.Ctx) error {
+	var req dto.CreateAPIKeyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Success: false,
+			Message: "Invalid request body",
+		})
+	}
+
+	principal := middleware.PrincipalFrom(c)
+	scopes := make([]entity.Permission, 0, len(req.Scopes))
+	for _, scope := range req.Scopes {
+		scopes = append(scopes, entity.Permission(scope))
+	}
+
+	value, key, err := h.apiKeyService.CreateAPIKey(principal.TenantID, req.Name, scopes, req.AllowedIPs, req.ExpiresAt, principal.UserID)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidAPIKeyRequest) {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+				Success: false,
+				Message: err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Success: false,
+			Message: "Failed to create API key",
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.CreateAPIKeyResponse{
+		Success: true,
+		Message: "API key created, store the key now as it will not be shown again",
+		Key:     value,
+		APIKey:  newAPIKeyDTO(key),
+	})
+}
+
+// ListAPIKeys handles GET request to list API keys
+func (h *APIKeyHandler) ListAPIKeys(c *fiber.Ctx) error {
+	keys, err := h.apiKeyService.ListAPIKeys(middleware.PrincipalFrom(c).TenantID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Success: false,
+			Message: "Failed to list API keys",
+		})
+	}
+
+	items := make([]*dto.APIKey, 0, len(keys))
+	for _, key := range keys {
+		items = append(items, newAPIKeyDTO(key))
+	}
+	return c.JSON(dto.APIKeyListResponse{
+		Success: true,
+		Items:   items,
+	})
+}
+
+// RevokeAPIKey handles DELETE request to revoke an API key
+func (h *APIKeyHandler) RevokeAPIKey(c *fiber.Ctx) error {
+	found, err := h.apiKeyService.RevokeAPIKey(middleware.PrincipalFrom(c).TenantID, c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Success: false,
+			Message: "Failed to revoke API key",
+		})
+	}
+	if !found {
+		return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{
+			Success: false,
+			Message: "API key not found",
+		})
+	}
+
+	return c.JSON(dto.ErrorResponse{
+		Success: true,
+		Message: "API key revoked",
+	})
+}
+
+func newAPIKeyDTO(key *entity.APIKey) *dto.APIKey {
+	scopes := make([]string, 0, len(key.Scopes))
+	for _, scope := range key.Scopes {
+		scopes = append(scopes, string(scope))
+	}
+	allowedIPs := key.AllowedIPs
+	if allowedIPs == nil {
+		allowedIPs = []string{}
+	}
+
+	return &dto.APIKey{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     scopes,
+		AllowedIPs: allowedIPs,
+		CreatedBy:  key.CreatedBy,
+		CreatedAt:  key.CreatedAt,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		RevokedAt:  key.RevokedAt,
+	}
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"nerp_wrapper/application/dto"
+	"nerp_wrapper/application/service"
+	"nerp_wrapper/domain/entity"
+	"nerp_wrapper/interfaces/http/middleware"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// AuditHandler handles HTTP requests for the audit trail
+type AuditHandler struct {
+	auditService *service.AuditService
+}
+
+// NewAuditHandler creates a new instance of AuditHandler
+func NewAuditHandler(auditService *service.AuditService) *AuditHandler {
+	return &AuditHandler{auditService: auditService}
+}
+
+// ListEvents handles GET request to list the audit events of the caller's
+// tenant, filtered by user (login or numeric ID), action and time range
+func (h *AuditHandler) ListEvents(c *fiber.Ctx) error {
+	filter := entity.AuditFilter{
+		TenantID: middleware.PrincipalFrom(c).TenantID,
+		Action:   entity.AuditAction(c.Query("action")),
+		Limit:    c.QueryInt("limit", 0),
+	}
+	if user := c.Query("user"); user != "" {
+		if id, err := strconv.ParseInt(user, 10, 64); err == nil {
+			filter.UserID = id
+		} else {
+			filter.Login = user
+		}
+	}
+
+	var err error
+	if filter.From, err = parseAuditTime(c.Query("from"), false); err != nil {
+		return badAuditQuery(c, "Invalid from, use RFC 3339 or YYYY-MM-DD")
+	}
+	if filter.To, err = parseAuditTime(c.Query("to"), true); err != nil {
+		return badAuditQuery(c, "Invalid to, use RFC 3339 or YYYY-MM-DD")
+	}
+
+	events, err := h.auditService.ListEvents(filter)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Success: false,
+			Message: "Failed to list audit events",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"items":   events,
+	})
+}
+
+// parseAuditTime parses an RFC 3339 timestamp or a date. A date used as the end
+// of a range covers the whole day.
+func parseAuditTime(value string, endOfDay bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	t, err := time.ParseInLocation("2006-01-02", value, time.Local)
+	if err != nil {
+		return nil, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+	return &t, nil
+}
+
+func badAuditQuery(c *fiber.Ctx, message string) error {
+	return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+		Success: false,
+		Message: message,
+	})
+}
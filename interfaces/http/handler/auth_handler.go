@@ -1,9 +1,14 @@
 package handler
 
 import (
+	"errors"
 	"fmt"
+	"math"
 	"nerp_wrapper/application/dto"
 	"nerp_wrapper/application/service"
+	"nerp_wrapper/domain/entity"
+	"nerp_wrapper/interfaces/http/middleware"
+	"strconv"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -30,29 +35,126 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 		})
 	}
 
-	user, err := h.authService.Login(req.Username, req.Password)
+	result, err := h.authService.Login(c.UserContext(), middleware.TenantFrom(c), req.Username, req.Secret(), req.APIKey != "", c.IP())
 	if err != nil {
+		var throttled *service.LoginThrottledError
+		if errors.As(err, &throttled) {
+			return respondThrottled(c, throttled)
+		}
+		if isBackendFailure(err) {
+			return respondBackendFailure(c, err)
+		}
+		message := "Invalid credentials"
+		if errors.Is(err, service.ErrOdooTwoFactorRequiresAPIKey) {
+			message = "Two-factor authentication is enabled in Odoo, sign in with an Odoo API key"
+		}
 		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
 			Success: false,
-			Message: "Invalid credentials",
+			Message: message,
+		})
+	}
+
+	if result.Challenge != nil {
+		return c.JSON(dto.LoginResponse{
+			Success: true,
+			Message: "Two-factor authentication required",
+			TwoFactor: &dto.TwoFactorChallenge{
+				Required:       true,
+				ChallengeToken: result.Challenge.Token,
+				ExpiresAt:      result.Challenge.ExpiresAt,
+			},
+		})
+	}
+	return c.JSON(newLoginResponse(result))
+}
+
+// LoginTOTP completes a two-factor login with a TOTP code
+func (h *AuthHandler) LoginTOTP(c *fiber.Ctx) error {
+	var req dto.TOTPLoginRequest
+	if err := c.BodyParser(&req); err != nil || req.ChallengeToken == "" || req.Code == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Success: false,
+			Message: "Invalid request body",
+		})
+	}
+
+	result, err := h.authService.VerifyTwoFactor(c.UserContext(), req.ChallengeToken, req.Code, c.IP())
+	if err != nil {
+		var throttled *service.LoginThrottledError
+		if errors.As(err, &throttled) {
+			return respondThrottled(c, throttled)
+		}
+		if isBackendFailure(err) {
+			return respondBackendFailure(c, err)
+		}
+		message := "Invalid two-factor code"
+		switch {
+		case errors.Is(err, service.ErrInvalidToken):
+			message = "Invalid or expired challenge token"
+		case errors.Is(err, service.ErrTOTPNotEnrolled):
+			message = "Two-factor enrollment required"
+		}
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Success: false,
+			Message: message,
+		})
+	}
+	return c.JSON(newLoginResponse(result))
+}
+
+// Refresh exchanges a refresh token for a new token pair
+func (h *AuthHandler) Refresh(c *fiber.Ctx) error {
+	var req dto.RefreshRequest
+	if err := c.BodyParser(&req); err != nil || req.RefreshToken == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Success: false,
+			Message: "Invalid request body",
+		})
+	}
+
+	tokens, err := h.authService.Refresh(c.UserContext(), req.RefreshToken)
+	if err != nil {
+		if isBackendFailure(err) {
+			return respondBackendFailure(c, err)
+		}
+		message := "Invalid or expired refresh token"
+		if errors.Is(err, service.ErrRefreshTokenReused) {
+			message = "Refresh token reuse detected, session revoked"
+		}
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Success: false,
+			Message: message,
 		})
 	}
 
 	return c.JSON(dto.LoginResponse{
 		Success: true,
-		Message: "Login successful",
-		User: &dto.User{
-			ID:       user.ID,
-			Username: user.Username,
-			Email:    user.Email,
-			Active:   user.Active,
-		},
+		Message: "Token refreshed successfully",
+		Token:   newTokenDTO(tokens),
 	})
 }
 
 // Logout handles user logout
 func (h *AuthHandler) Logout(c *fiber.Ctx) error {
-	if err := h.authService.Logout(); err != nil {
+	var req dto.LogoutRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+				Success: false,
+				Message: "Invalid request body",
+			})
+		}
+	}
+
+	claims := middleware.ClaimsFrom(c)
+	if claims == nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Success: false,
+			Message: "Logout requires an access token",
+		})
+	}
+
+	if err := h.authService.Logout(c.UserContext(), claims, req.RefreshToken, c.IP()); err != nil {
 		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
 			Success: false,
 			Message: "Failed to logout",
@@ -84,14 +186,18 @@ func (h *AuthHandler) GetUserInfo(c *fiber.Ctx) error {
 		})
 	}
 
-	user, err := h.authService.GetUserInfo(id)
+	user, err := h.authService.GetUserInfo(c.UserContext(), middleware.PrincipalFrom(c).TenantID, id)
 	if err != nil {
+		if isBackendFailure(err) {
+			return respondBackendFailure(c, err)
+		}
 		return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{
 			Success: false,
 			Message: "User not found",
 		})
 	}
 
+	middleware.SetAuditRowCount(c, 1)
 	return c.JSON(dto.LoginResponse{
 		Success: true,
 		Message: "User info retrieved successfully",
@@ -103,3 +209,37 @@ func (h *AuthHandler) GetUserInfo(c *fiber.Ctx) error {
 		},
 	})
 }
+
+func newLoginResponse(result *entity.LoginResult) dto.LoginResponse {
+	return dto.LoginResponse{
+		Success: true,
+		Message: "Login successful",
+		User: &dto.User{
+			ID:       result.User.ID,
+			Username: result.User.Username,
+			Email:    result.User.Email,
+			Active:   result.User.Active,
+		},
+		Token: newTokenDTO(result.Tokens),
+	}
+}
+
+// respondThrottled writes the 429 response for a backed off login attempt
+func respondThrottled(c *fiber.Ctx, throttled *service.LoginThrottledError) error {
+	retryAfter := int(math.Ceil(throttled.RetryAfter.Seconds()))
+	c.Set(fiber.HeaderRetryAfter, strconv.Itoa(retryAfter))
+	return c.Status(fiber.StatusTooManyRequests).JSON(dto.ErrorResponse{
+		Success: false,
+		Message: "Too many failed login attempts, try again later",
+	})
+}
+
+func newTokenDTO(tokens *entity.AuthTokens) *dto.Token {
+	return &dto.Token{
+		AccessToken:      tokens.AccessToken,
+		TokenType:        "Bearer",
+		ExpiresAt:        tokens.AccessTokenExpiresAt,
+		RefreshToken:     tokens.RefreshToken,
+		RefreshExpiresAt: tokens.RefreshTokenExpiresAt,
+	}
+}
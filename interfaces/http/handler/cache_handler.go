@@ -0,0 +1,56 @@
+package handler
+
+import (
+	"errors"
+	"nerp_wrapper/application/dto"
+	"nerp_wrapper/application/service"
+	"nerp_wrapper/interfaces/http/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// CacheHandler handles HTTP requests for cache statistics and purges
+type CacheHandler struct {
+	cacheService         *service.CacheService
+	responseCacheService *service.ResponseCacheService
+}
+
+// NewCacheHandler creates a new instance of CacheHandler
+func NewCacheHandler(cacheService *service.CacheService, responseCacheService *service.ResponseCacheService) *CacheHandler {
+	return &CacheHandler{cacheService: cacheService, responseCacheService: responseCacheService}
+}
+
+// GetStats handles GET request for the hit and miss statistics of the
+// caches of the caller's tenant
+func (h *CacheHandler) GetStats(c *fiber.Ctx) error {
+	report := h.cacheService.Report(middleware.PrincipalFrom(c).TenantID)
+	return c.JSON(fiber.Map{
+		"success": true,
+		"backend": report.Backend,
+		"items":   report.Stats,
+	})
+}
+
+// PurgeResponses handles DELETE request to drop the cached responses of the
+// caller's tenant, of the route given in the route query parameter or of
+// every cached route
+func (h *CacheHandler) PurgeResponses(c *fiber.Ctx) error {
+	purged, err := h.responseCacheService.Purge(c.UserContext(), middleware.PrincipalFrom(c).TenantID, c.Query("route"))
+	if err != nil {
+		if errors.Is(err, service.ErrRouteNotCached) {
+			return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+				Success: false,
+				Message: "Responses of route " + c.Query("route") + " are not cached",
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+			Success: false,
+			Message: "Failed to purge cached responses",
+		})
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"purged":  purged,
+	})
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"context"
+	"errors"
+	"nerp_wrapper/application/dto"
+	"nerp_wrapper/application/service"
+	"nerp_wrapper/domain/repository"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// statusClientClosedRequest is the status recorded for requests whose client
+// disconnected before the answer was ready, as nginx does
+const statusClientClosedRequest = 499
+
+// respondServiceError writes the HTTP response for an error returned by a data service
+func respondServiceError(c *fiber.Ctx, err error) error {
+	if errors.Is(err, repository.ErrSessionExpired) {
+		return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+			Success: false,
+			Message: "Odoo session expired, please log in again",
+		})
+	}
+	if isBackendFailure(err) {
+		return respondBackendFailure(c, err)
+	}
+	if errors.Is(err, context.Canceled) {
+		// Nobody reads the response; the status marks the request in logs
+		return c.SendStatus(statusClientClosedRequest)
+	}
+	if errors.Is(err, service.ErrSalesScopeWithoutUser) {
+		return c.Status(fiber.StatusForbidden).JSON(dto.ErrorResponse{
+			Success: false,
+			Message: "API key needs the sales:read_all scope to read sale orders",
+		})
+	}
+
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": err.Error(),
+	})
+}
+
+// isBackendFailure reports whether err means Odoo could not serve the operation
+func isBackendFailure(err error) bool {
+	return errors.Is(err, repository.ErrBackendTimeout) || errors.Is(err, repository.ErrBackendUnavailable)
+}
+
+// respondBackendFailure writes the 504 response for an operation Odoo did not
+// complete before its deadline and the 503 response when Odoo is unavailable
+func respondBackendFailure(c *fiber.Ctx, err error) error {
+	if errors.Is(err, repository.ErrBackendTimeout) {
+		return c.Status(fiber.StatusGatewayTimeout).JSON(dto.ErrorResponse{
+			Success: false,
+			Message: "Odoo did not respond in time, try again later",
+			Code:    dto.ErrorCodeBackendTimeout,
+		})
+	}
+	return c.Status(fiber.StatusServiceUnavailable).JSON(dto.ErrorResponse{
+		Success: false,
+		Message: "Odoo is unavailable, try again later",
+		Code:    dto.ErrorCodeBackendUnavailable,
+	})
+}
@@ -0,0 +1,148 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// errConsolidatedDaily rejects consolidated=true on daily summaries, which
+// have no per-company breakdown
+var errConsolidatedDaily = errors.New("consolidated is only supported by period summaries")
+
+// parseIDList reads a list of positive record IDs from a query parameter.
+// The parameter may be repeated (?id=1&id=2), comma separated (?id=1,2) or both.
+func parseIDList(c *fiber.Ctx, name string) ([]int64, error) {
+	var ids []int64
+	seen := make(map[int64]bool)
+	for _, raw := range c.Context().QueryArgs().PeekMulti(name) {
+		for _, part := range strings.Split(string(raw), ",") {
+			part = strings.TrimSpace(part)
+			if part == "" {
+				continue
+			}
+			id, err := strconv.ParseInt(part, 10, 64)
+			if err != nil || id < 1 {
+				return nil, fmt.Errorf("invalid %s: %q is not a positive integer", name, part)
+			}
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids, nil
+}
+
+// parseID reads an optional positive record ID from a query parameter
+func parseID(c *fiber.Ctx, name string) (int64, error) {
+	raw := strings.TrimSpace(c.Query(name))
+	if raw == "" {
+		return 0, nil
+	}
+	id, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || id < 1 {
+		return 0, fmt.Errorf("invalid %s: %q is not a positive integer", name, raw)
+	}
+	return id, nil
+}
+
+// parseChoiceList reads a list of values from a query parameter, each one of
+// allowed. Like parseIDList it accepts repeated and comma separated values.
+func parseChoiceList(c *fiber.Ctx, name string, allowed []string) ([]string, error) {
+	var values []string
+	for _, raw := range c.Context().QueryArgs().PeekMulti(name) {
+		for _, part := range strings.Split(string(raw), ",") {
+			part = strings.TrimSpace(part)
+			if part == "" || slices.Contains(values, part) {
+				continue
+			}
+			if !slices.Contains(allowed, part) {
+				return nil, fmt.Errorf("invalid %s: %q is not one of %s", name, part, strings.Join(allowed, ", "))
+			}
+			values = append(values, part)
+		}
+	}
+	return values, nil
+}
+
+// parseDate reads an optional YYYY-MM-DD date from a query parameter
+func parseDate(c *fiber.Ctx, name string) (*time.Time, error) {
+	raw := strings.TrimSpace(c.Query(name))
+	if raw == "" {
+		return nil, nil
+	}
+	date, err := time.Parse(time.DateOnly, raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %q is not a YYYY-MM-DD date", name, raw)
+	}
+	return &date, nil
+}
+
+// parseDateRange reads an optional range of days from two query parameters
+func parseDateRange(c *fiber.Ctx, fromName, toName string) (*time.Time, *time.Time, error) {
+	from, err := parseDate(c, fromName)
+	if err != nil {
+		return nil, nil, err
+	}
+	to, err := parseDate(c, toName)
+	if err != nil {
+		return nil, nil, err
+	}
+	if from != nil && to != nil && from.After(*to) {
+		return nil, nil, fmt.Errorf("invalid date range: %s is after %s", fromName, toName)
+	}
+	return from, to, nil
+}
+
+// parseAmount reads an optional amount from a query parameter
+func parseAmount(c *fiber.Ctx, name string) (*float64, error) {
+	raw := strings.TrimSpace(c.Query(name))
+	if raw == "" {
+		return nil, nil
+	}
+	amount, err := strconv.ParseFloat(raw, 64)
+	if err != nil || math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return nil, fmt.Errorf("invalid %s: %q is not a number", name, raw)
+	}
+	return &amount, nil
+}
+
+// maxSearchLength bounds free-text search terms
+const maxSearchLength = 100
+
+// parseSearch reads an optional free-text search term from a query parameter
+func parseSearch(c *fiber.Ctx, name string) (string, error) {
+	term := strings.TrimSpace(c.Query(name))
+	if utf8.RuneCountInString(term) > maxSearchLength {
+		return "", fmt.Errorf("invalid %s: longer than %d characters", name, maxSearchLength)
+	}
+	return term, nil
+}
+
+// parseBool reads an optional boolean query parameter
+func parseBool(c *fiber.Ctx, name string) (bool, error) {
+	raw := c.Query(name)
+	if raw == "" {
+		return false, nil
+	}
+	value, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s: %q is not a boolean", name, raw)
+	}
+	return value, nil
+}
+
+// badRequest answers with 400 and the error message
+func badRequest(c *fiber.Ctx, err error) error {
+	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		"error": err.Error(),
+	})
+}
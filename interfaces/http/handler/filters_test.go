@@ -0,0 +1,44 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// parseQuery runs parse on a request with the given query string, answering
+// like the list handlers do, and returns the parsed filter and the status
+func parseQuery[T any](t *testing.T, query string, parse func(*fiber.Ctx) (T, error)) (T, int) {
+	t.Helper()
+	var filter T
+	app := fiber.New()
+	app.Get("/", func(c *fiber.Ctx) error {
+		parsed, err := parse(c)
+		if err != nil {
+			return badRequest(c, err)
+		}
+		filter = parsed
+		return c.SendStatus(fiber.StatusOK)
+	})
+	resp, err := app.Test(httptest.NewRequest(http.MethodGet, "/?"+query, nil))
+	if err != nil {
+		t.Fatalf("request failed: %v", err)
+	}
+	resp.Body.Close()
+	return filter, resp.StatusCode
+}
+
+func day(value string) *time.Time {
+	date, err := time.Parse(time.DateOnly, value)
+	if err != nil {
+		panic(err)
+	}
+	return &date
+}
+
+func amount(value float64) *float64 {
+	return &value
+}
@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"nerp_wrapper/application/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// HealthHandler handles liveness and readiness probes
+type HealthHandler struct {
+	healthService *service.HealthService
+}
+
+// NewHealthHandler creates a new instance of HealthHandler
+func NewHealthHandler(healthService *service.HealthService) *HealthHandler {
+	return &HealthHandler{healthService: healthService}
+}
+
+// Liveness handles GET request reporting that the process is serving HTTP.
+// It checks no dependency, so an Odoo outage does not get the pod restarted.
+func (h *HealthHandler) Liveness(c *fiber.Ctx) error {
+	return c.JSON(fiber.Map{"status": "ok"})
+}
+
+// Readiness handles GET request reporting whether Odoo and the store are
+// usable, answering 503 with the per-dependency status when one is down
+func (h *HealthHandler) Readiness(c *fiber.Ctx) error {
+	report := h.healthService.Readiness()
+	if !report.Ready() {
+		return c.Status(fiber.StatusServiceUnavailable).JSON(report)
+	}
+	return c.JSON(report)
+}
@@ -3,6 +3,7 @@ package handler
 import (
 	"nerp_wrapper/application/service"
 	"nerp_wrapper/domain/entity"
+	"nerp_wrapper/interfaces/http/middleware"
 	"time"
 
 	"github.com/gofiber/fiber/v2"
@@ -11,45 +12,63 @@ import (
 // InvoiceHandler handles HTTP requests for invoices
 type InvoiceHandler struct {
 	invoiceService *service.InvoiceService
+	pageLimits     PageSizeLimits
 }
 
 // NewInvoiceHandler creates a new instance of InvoiceHandler
-func NewInvoiceHandler(invoiceService *service.InvoiceService) *InvoiceHandler {
-	return &InvoiceHandler{invoiceService: invoiceService}
+func NewInvoiceHandler(invoiceService *service.InvoiceService, pageLimits PageSizeLimits) *InvoiceHandler {
+	return &InvoiceHandler{invoiceService: invoiceService, pageLimits: pageLimits}
 }
 
 // GetAllInvoices handles GET request to retrieve invoices with pagination
 func (h *InvoiceHandler) GetAllInvoices(c *fiber.Ctx) error {
-	page := c.QueryInt("page", 1)
-	pageSize := c.QueryInt("page_size", 500)
+	filter, err := parseInvoiceListFilter(c)
+	if err != nil {
+		return badRequest(c, err)
+	}
+	page, pageSize := h.pageLimits.parse(c)
 
-	invoices, err := h.invoiceService.GetAllInvoices(page, pageSize)
+	invoices, err := h.invoiceService.GetAllInvoices(c.UserContext(), middleware.PrincipalFrom(c), filter, page, pageSize)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return respondServiceError(c, err)
 	}
 
+	middleware.SetAuditRowCount(c, len(invoices.Items))
 	return c.JSON(invoices)
 }
 
 // GetDailyInvoiceSummary handles GET request to retrieve daily invoice summary
 func (h *InvoiceHandler) GetDailyInvoiceSummary(c *fiber.Ctx) error {
-	page := c.QueryInt("page", 1)
-	pageSize := c.QueryInt("page_size", 500)
+	filter, err := parseInvoiceFilter(c)
+	if err != nil {
+		return badRequest(c, err)
+	}
+	if filter.Consolidated {
+		return badRequest(c, errConsolidatedDaily)
+	}
+	page, pageSize := h.pageLimits.parse(c)
 
-	summary, err := h.invoiceService.GetDailyInvoiceSummary(page, pageSize)
+	summary, err := h.invoiceService.GetDailyInvoiceSummary(c.UserContext(), middleware.PrincipalFrom(c), filter, page, pageSize)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return respondServiceError(c, err)
+	}
+
+	rowCount := 0
+	for _, day := range summary.Items {
+		rowCount += len(day.Invoices)
 	}
+	middleware.SetAuditRowCount(c, rowCount)
 
 	return c.JSON(summary)
 }
 
 // GetPeriodInvoiceSummary handles GET request to retrieve period-based invoice summary
 func (h *InvoiceHandler) GetPeriodInvoiceSummary(c *fiber.Ctx) error {
+	filter, err := parseInvoiceFilter(c)
+	if err != nil {
+		return badRequest(c, err)
+	}
+
 	// Get period type from query param, default to 30D
 	periodType := entity.PeriodType(c.Query("period_type", string(entity.PeriodTypeMonth)))
 
@@ -87,12 +106,65 @@ func (h *InvoiceHandler) GetPeriodInvoiceSummary(c *fiber.Ctx) error {
 		}
 	}
 
-	summary, err := h.invoiceService.GetPeriodInvoiceSummary(periodType, startDate, endDate)
+	summary, err := h.invoiceService.GetPeriodInvoiceSummary(c.UserContext(), middleware.PrincipalFrom(c), filter, periodType, startDate, endDate)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return respondServiceError(c, err)
 	}
+	middleware.SetAuditRowCount(c, summary.InvoiceCount)
 
 	return c.JSON(summary)
 }
+
+// parseInvoiceFilter reads the company_id, consolidated and include_items
+// query parameters
+func parseInvoiceFilter(c *fiber.Ctx) (entity.InvoiceFilter, error) {
+	companyIDs, err := parseIDList(c, "company_id")
+	if err != nil {
+		return entity.InvoiceFilter{}, err
+	}
+	consolidated, err := parseBool(c, "consolidated")
+	if err != nil {
+		return entity.InvoiceFilter{}, err
+	}
+	includeItems, err := parseBool(c, "include_items")
+	if err != nil {
+		return entity.InvoiceFilter{}, err
+	}
+	return entity.InvoiceFilter{CompanyIDs: companyIDs, Consolidated: consolidated, IncludeItems: includeItems}, nil
+}
+
+// parseInvoiceListFilter reads the invoice filter and the list filters:
+// move_type, state and payment_state (repeated or comma separated),
+// journal_id, partner_id, currency_id, invoice_date_from, invoice_date_to,
+// due_date_from and due_date_to (YYYY-MM-DD, inclusive)
+func parseInvoiceListFilter(c *fiber.Ctx) (entity.InvoiceFilter, error) {
+	filter, err := parseInvoiceFilter(c)
+	if err != nil {
+		return filter, err
+	}
+	if filter.MoveTypes, err = parseChoiceList(c, "move_type", entity.InvoiceMoveTypes); err != nil {
+		return filter, err
+	}
+	if filter.States, err = parseChoiceList(c, "state", entity.InvoiceStates); err != nil {
+		return filter, err
+	}
+	if filter.PaymentStates, err = parseChoiceList(c, "payment_state", entity.InvoicePaymentStates); err != nil {
+		return filter, err
+	}
+	if filter.JournalID, err = parseID(c, "journal_id"); err != nil {
+		return filter, err
+	}
+	if filter.PartnerID, err = parseID(c, "partner_id"); err != nil {
+		return filter, err
+	}
+	if filter.CurrencyID, err = parseID(c, "currency_id"); err != nil {
+		return filter, err
+	}
+	if filter.InvoiceDateFrom, filter.InvoiceDateTo, err = parseDateRange(c, "invoice_date_from", "invoice_date_to"); err != nil {
+		return filter, err
+	}
+	if filter.DueDateFrom, filter.DueDateTo, err = parseDateRange(c, "due_date_from", "due_date_to"); err != nil {
+		return filter, err
+	}
+	return filter, nil
+}
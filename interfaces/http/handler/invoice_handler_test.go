@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"nerp_wrapper/domain/entity"
+	"net/http"
+	"reflect"
+	"testing"
+)
+
+func TestParseInvoiceListFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		want   entity.InvoiceFilter
+		status int
+	}{
+		{"no filters", "", entity.InvoiceFilter{}, http.StatusOK},
+		{
+			"repeated and comma separated choices",
+			"move_type=out_invoice,out_refund&state=posted&payment_state=not_paid&payment_state=partial,not_paid",
+			entity.InvoiceFilter{MoveTypes: []string{"out_invoice", "out_refund"}, States: []string{"posted"}, PaymentStates: []string{"not_paid", "partial"}},
+			http.StatusOK,
+		},
+		{"ids", "journal_id=1&partner_id=9&currency_id=2&company_id=1", entity.InvoiceFilter{CompanyIDs: []int64{1}, JournalID: 1, PartnerID: 9, CurrencyID: 2}, http.StatusOK},
+		{
+			"date ranges",
+			"invoice_date_from=2024-03-01&invoice_date_to=2024-03-31&due_date_from=2024-04-01&due_date_to=2024-04-01",
+			entity.InvoiceFilter{InvoiceDateFrom: day("2024-03-01"), InvoiceDateTo: day("2024-03-31"), DueDateFrom: day("2024-04-01"), DueDateTo: day("2024-04-01")},
+			http.StatusOK,
+		},
+		{"unknown move type", "move_type=entry", entity.InvoiceFilter{}, http.StatusBadRequest},
+		{"unknown state", "state=paid", entity.InvoiceFilter{}, http.StatusBadRequest},
+		{"unknown payment state", "payment_state=in_payment", entity.InvoiceFilter{}, http.StatusBadRequest},
+		{"non-numeric journal", "journal_id=sales", entity.InvoiceFilter{}, http.StatusBadRequest},
+		{"negative currency", "currency_id=-1", entity.InvoiceFilter{}, http.StatusBadRequest},
+		{"invalid invoice date", "invoice_date_to=2024-02-30", entity.InvoiceFilter{}, http.StatusBadRequest},
+		{"reversed due dates", "due_date_from=2024-04-02&due_date_to=2024-04-01", entity.InvoiceFilter{}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, status := parseQuery(t, tt.query, parseInvoiceListFilter)
+			if status != tt.status {
+				t.Fatalf("status is %d, want %d", status, tt.status)
+			}
+			if !reflect.DeepEqual(filter, tt.want) {
+				t.Errorf("filter is %+v, want %+v", filter, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package handler
+
+import (
+	"nerp_wrapper/application/dto"
+	"nerp_wrapper/application/service"
+	"nerp_wrapper/domain/entity"
+	"nerp_wrapper/interfaces/http/middleware"
+	"net/url"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// LoginGuardHandler handles HTTP requests for login lockout administration
+type LoginGuardHandler struct {
+	loginGuard *service.LoginGuardService
+}
+
+// NewLoginGuardHandler creates a new instance of LoginGuardHandler
+func NewLoginGuardHandler(loginGuard *service.LoginGuardService) *LoginGuardHandler {
+	return &LoginGuardHandler{loginGuard: loginGuard}
+}
+
+// ListLockouts handles GET request to list the usernames of the caller's tenant
+// and the IPs with failed logins
+func (h *LoginGuardHandler) ListLockouts(c *fiber.Ctx) error {
+	tenantID := middleware.PrincipalFrom(c).TenantID
+	lockouts := []entity.LoginLockout{}
+	for _, lockout := range h.loginGuard.ListLockouts() {
+		if service.LockoutInTenant(lockout.Key, tenantID) {
+			lockouts = append(lockouts, lockout)
+		}
+	}
+
+	return c.JSON(fiber.Map{
+		"success": true,
+		"items":   lockouts,
+	})
+}
+
+// ClearLockout handles DELETE request to clear the failures of a username or IP
+func (h *LoginGuardHandler) ClearLockout(c *fiber.Ctx) error {
+	// User keys contain a slash, which clients send escaped
+	key, err := url.PathUnescape(c.Params("key"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Success: false,
+			Message: "Invalid lockout key",
+		})
+	}
+	if !service.LockoutInTenant(key, middleware.PrincipalFrom(c).TenantID) || !h.loginGuard.ClearLockout(key) {
+		return c.Status(fiber.StatusNotFound).JSON(dto.ErrorResponse{
+			Success: false,
+			Message: "Lockout not found",
+		})
+	}
+
+	return c.JSON(dto.ErrorResponse{
+		Success: true,
+		Message: "Lockout cleared",
+	})
+}
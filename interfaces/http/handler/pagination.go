@@ -0,0 +1,26 @@
+package handler
+
+import "github.com/gofiber/fiber/v2"
+
+// PageSizeLimits holds the default and maximum page_size of list endpoints
+type PageSizeLimits struct {
+	Default int
+	Max     int
+}
+
+// parse reads the page and page_size query parameters, falling back to the
+// default page size and capping it at the maximum
+func (l PageSizeLimits) parse(c *fiber.Ctx) (int, int) {
+	page := c.QueryInt("page", 1)
+	if page < 1 {
+		page = 1
+	}
+	pageSize := c.QueryInt("page_size", l.Default)
+	if pageSize < 1 {
+		pageSize = l.Default
+	}
+	if pageSize > l.Max {
+		pageSize = l.Max
+	}
+	return page, pageSize
+}
@@ -1,8 +1,10 @@
 package handler
 
 import (
+	"fmt"
 	"nerp_wrapper/application/service"
 	"nerp_wrapper/domain/entity"
+	"nerp_wrapper/interfaces/http/middleware"
 
 	"time"
 
@@ -12,45 +14,63 @@ import (
 // SaleHandler handles HTTP requests for sale orders
 type SaleHandler struct {
 	saleService *service.SaleService
+	pageLimits  PageSizeLimits
 }
 
 // NewSaleHandler creates a new instance of SaleHandler
-func NewSaleHandler(saleService *service.SaleService) *SaleHandler {
-	return &SaleHandler{saleService: saleService}
+func NewSaleHandler(saleService *service.SaleService, pageLimits PageSizeLimits) *SaleHandler {
+	return &SaleHandler{saleService: saleService, pageLimits: pageLimits}
 }
 
 // GetAllSaleOrders handles GET request to retrieve sale orders with pagination
 func (h *SaleHandler) GetAllSaleOrders(c *fiber.Ctx) error {
-	page := c.QueryInt("page", 1)
-	pageSize := c.QueryInt("page_size", 500)
+	filter, err := parseSaleOrderListFilter(c)
+	if err != nil {
+		return badRequest(c, err)
+	}
+	page, pageSize := h.pageLimits.parse(c)
 
-	orders, err := h.saleService.GetAllSaleOrders(page, pageSize)
+	orders, err := h.saleService.GetAllSaleOrders(c.UserContext(), middleware.PrincipalFrom(c), filter, page, pageSize)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return respondServiceError(c, err)
 	}
 
+	middleware.SetAuditRowCount(c, len(orders.Items))
 	return c.JSON(orders)
 }
 
 // GetDailySalesSummary handles GET request to retrieve daily sales summary
 func (h *SaleHandler) GetDailySalesSummary(c *fiber.Ctx) error {
-	page := c.QueryInt("page", 1)
-	pageSize := c.QueryInt("page_size", 500)
+	filter, err := parseSaleOrderFilter(c)
+	if err != nil {
+		return badRequest(c, err)
+	}
+	if filter.Consolidated {
+		return badRequest(c, errConsolidatedDaily)
+	}
+	page, pageSize := h.pageLimits.parse(c)
 
-	summary, err := h.saleService.GetDailySalesSummary(page, pageSize)
+	summary, err := h.saleService.GetDailySalesSummary(c.UserContext(), middleware.PrincipalFrom(c), filter, page, pageSize)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return respondServiceError(c, err)
+	}
+
+	rowCount := 0
+	for _, day := range summary.Items {
+		rowCount += len(day.Orders)
 	}
+	middleware.SetAuditRowCount(c, rowCount)
 
 	return c.JSON(summary)
 }
 
 // GetPeriodSalesSummary handles GET request to retrieve period-based sales summary
 func (h *SaleHandler) GetPeriodSalesSummary(c *fiber.Ctx) error {
+	filter, err := parseSaleOrderFilter(c)
+	if err != nil {
+		return badRequest(c, err)
+	}
+
 	// Get period type from query param, default to 30D
 	periodType := entity.PeriodType(c.Query("period_type", string(entity.PeriodTypeMonth)))
 
@@ -88,12 +108,68 @@ func (h *SaleHandler) GetPeriodSalesSummary(c *fiber.Ctx) error {
 		}
 	}
 
-	summary, err := h.saleService.GetPeriodSalesSummary(periodType, startDate, endDate)
+	summary, err := h.saleService.GetPeriodSalesSummary(c.UserContext(), middleware.PrincipalFrom(c), filter, periodType, startDate, endDate)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return respondServiceError(c, err)
 	}
+	middleware.SetAuditRowCount(c, summary.OrderCount)
 
 	return c.JSON(summary)
 }
+
+// parseSaleOrderFilter reads the company_id, consolidated and include_items
+// query parameters
+func parseSaleOrderFilter(c *fiber.Ctx) (entity.SaleOrderFilter, error) {
+	companyIDs, err := parseIDList(c, "company_id")
+	if err != nil {
+		return entity.SaleOrderFilter{}, err
+	}
+	consolidated, err := parseBool(c, "consolidated")
+	if err != nil {
+		return entity.SaleOrderFilter{}, err
+	}
+	includeItems, err := parseBool(c, "include_items")
+	if err != nil {
+		return entity.SaleOrderFilter{}, err
+	}
+	return entity.SaleOrderFilter{CompanyIDs: companyIDs, Consolidated: consolidated, IncludeItems: includeItems}, nil
+}
+
+// parseSaleOrderListFilter reads the sale order filter and the list filters:
+// state (repeated or comma separated), partner_id, salesperson_id, team_id,
+// date_from and date_to (YYYY-MM-DD, inclusive), amount_min, amount_max and
+// the free-text search q
+func parseSaleOrderListFilter(c *fiber.Ctx) (entity.SaleOrderFilter, error) {
+	filter, err := parseSaleOrderFilter(c)
+	if err != nil {
+		return filter, err
+	}
+	if filter.States, err = parseChoiceList(c, "state", entity.SaleOrderStates); err != nil {
+		return filter, err
+	}
+	if filter.PartnerID, err = parseID(c, "partner_id"); err != nil {
+		return filter, err
+	}
+	if filter.SalespersonID, err = parseID(c, "salesperson_id"); err != nil {
+		return filter, err
+	}
+	if filter.TeamID, err = parseID(c, "team_id"); err != nil {
+		return filter, err
+	}
+	if filter.DateFrom, filter.DateTo, err = parseDateRange(c, "date_from", "date_to"); err != nil {
+		return filter, err
+	}
+	if filter.AmountMin, err = parseAmount(c, "amount_min"); err != nil {
+		return filter, err
+	}
+	if filter.AmountMax, err = parseAmount(c, "amount_max"); err != nil {
+		return filter, err
+	}
+	if filter.AmountMin != nil && filter.AmountMax != nil && *filter.AmountMin > *filter.AmountMax {
+		return filter, fmt.Errorf("invalid amount range: amount_min is above amount_max")
+	}
+	if filter.Search, err = parseSearch(c, "q"); err != nil {
+		return filter, err
+	}
+	return filter, nil
+}
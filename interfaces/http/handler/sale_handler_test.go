@@ -0,0 +1,43 @@
+package handler
+
+import (
+	"nerp_wrapper/domain/entity"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseSaleOrderListFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		query  string
+		want   entity.SaleOrderFilter
+		status int
+	}{
+		{"no filters", "", entity.SaleOrderFilter{}, http.StatusOK},
+		{"repeated and comma separated states", "state=sale,done&state=sent&state=sale", entity.SaleOrderFilter{States: []string{"sale", "done", "sent"}}, http.StatusOK},
+		{"ids", "partner_id=9&salesperson_id=6&team_id=1&company_id=1,2", entity.SaleOrderFilter{CompanyIDs: []int64{1, 2}, PartnerID: 9, SalespersonID: 6, TeamID: 1}, http.StatusOK},
+		{"single day", "date_from=2024-03-01&date_to=2024-03-01", entity.SaleOrderFilter{DateFrom: day("2024-03-01"), DateTo: day("2024-03-01")}, http.StatusOK},
+		{"amounts and search", "amount_min=100&amount_max=250.5&q=%20Azure%20", entity.SaleOrderFilter{AmountMin: amount(100), AmountMax: amount(250.5), Search: "Azure"}, http.StatusOK},
+		{"unknown state", "state=sale,shipped", entity.SaleOrderFilter{}, http.StatusBadRequest},
+		{"non-numeric id", "partner_id=abc", entity.SaleOrderFilter{}, http.StatusBadRequest},
+		{"zero id", "team_id=0", entity.SaleOrderFilter{}, http.StatusBadRequest},
+		{"invalid date", "date_from=01/03/2024", entity.SaleOrderFilter{}, http.StatusBadRequest},
+		{"reversed dates", "date_from=2024-03-02&date_to=2024-03-01", entity.SaleOrderFilter{}, http.StatusBadRequest},
+		{"reversed amounts", "amount_min=300&amount_max=200", entity.SaleOrderFilter{}, http.StatusBadRequest},
+		{"non-numeric amount", "amount_min=NaN", entity.SaleOrderFilter{}, http.StatusBadRequest},
+		{"search too long", "q=" + strings.Repeat("a", 101), entity.SaleOrderFilter{}, http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter, status := parseQuery(t, tt.query, parseSaleOrderListFilter)
+			if status != tt.status {
+				t.Fatalf("status is %d, want %d", status, tt.status)
+			}
+			if !reflect.DeepEqual(filter, tt.want) {
+				t.Errorf("filter is %+v, want %+v", filter, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,123 @@
+package handler
+
+import (
+	"errors"
+	"nerp_wrapper/application/dto"
+	"nerp_wrapper/application/service"
+	"nerp_wrapper/domain/entity"
+	"nerp_wrapper/interfaces/http/middleware"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// TOTPHandler handles HTTP requests for managing the caller's TOTP enrollment
+type TOTPHandler struct {
+	totpService *service.TOTPService
+	authService *service.AuthService
+}
+
+// NewTOTPHandler creates a new instance of TOTPHandler
+func NewTOTPHandler(totpService *service.TOTPService, authService *service.AuthService) *TOTPHandler {
+	return &TOTPHandler{totpService: totpService, authService: authService}
+}
+
+// BeginEnrollment handles POST request to start TOTP enrollment
+func (h *TOTPHandler) BeginEnrollment(c *fiber.Ctx) error {
+	principal := middleware.PrincipalFrom(c)
+	if principal.IsAPIKey() {
+		return apiKeyNotAllowed(c)
+	}
+
+	enrollment, uri, err := h.totpService.BeginEnrollment(principal.TenantID, principal.UserID, principal.Login)
+	if err != nil {
+		return respondEnrollmentError(c, err)
+	}
+	return c.JSON(newEnrollmentResponse(enrollment, uri))
+}
+
+// ConfirmEnrollment handles POST request confirming a pending enrollment with a code
+func (h *TOTPHandler) ConfirmEnrollment(c *fiber.Ctx) error {
+	principal := middleware.PrincipalFrom(c)
+	if principal.IsAPIKey() {
+		return apiKeyNotAllowed(c)
+	}
+
+	var req dto.TOTPCodeRequest
+	if err := c.BodyParser(&req); err != nil || req.Code == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Success: false,
+			Message: "Invalid request body",
+		})
+	}
+
+	if err := h.authService.ConfirmTOTPEnrollment(principal, req.Code, c.IP()); err != nil {
+		return respondEnrollmentError(c, err)
+	}
+	return c.JSON(dto.ErrorResponse{
+		Success: true,
+		Message: "Two-factor authentication enabled",
+	})
+}
+
+// Disable handles DELETE request removing the caller's enrollment
+func (h *TOTPHandler) Disable(c *fiber.Ctx) error {
+	principal := middleware.PrincipalFrom(c)
+	if principal.IsAPIKey() {
+		return apiKeyNotAllowed(c)
+	}
+
+	var req dto.TOTPCodeRequest
+	if err := c.BodyParser(&req); err != nil || req.Code == "" {
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Success: false,
+			Message: "Invalid request body",
+		})
+	}
+
+	if err := h.authService.DisableTOTP(principal, req.Code, c.IP()); err != nil {
+		return respondEnrollmentError(c, err)
+	}
+	return c.JSON(dto.ErrorResponse{
+		Success: true,
+		Message: "Two-factor authentication disabled",
+	})
+}
+
+func apiKeyNotAllowed(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusForbidden).JSON(dto.ErrorResponse{
+		Success: false,
+		Message: "Two-factor enrollment is not available to API keys",
+	})
+}
+
+// respondEnrollmentError writes the HTTP response for a TOTP enrollment error
+func respondEnrollmentError(c *fiber.Ctx, err error) error {
+	var throttled *service.LoginThrottledError
+	switch {
+	case errors.As(err, &throttled):
+		return respondThrottled(c, throttled)
+	case errors.Is(err, service.ErrInvalidTOTPCode):
+		return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+			Success: false,
+			Message: "Invalid two-factor code",
+		})
+	case errors.Is(err, service.ErrTOTPAlreadyEnrolled), errors.Is(err, service.ErrTOTPNotEnrolled):
+		return c.Status(fiber.StatusConflict).JSON(dto.ErrorResponse{
+			Success: false,
+			Message: err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(dto.ErrorResponse{
+		Success: false,
+		Message: "Failed to update two-factor enrollment",
+	})
+}
+
+func newEnrollmentResponse(enrollment *entity.TOTPEnrollment, uri string) dto.TOTPEnrollmentResponse {
+	return dto.TOTPEnrollmentResponse{
+		Success:    true,
+		Message:    "Add the secret to an authenticator app and confirm with a code",
+		Secret:     enrollment.Secret,
+		OTPAuthURI: uri,
+	}
+}
@@ -0,0 +1,44 @@
+package middleware
+
+import (
+	"errors"
+	"nerp_wrapper/application/service"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// auditRowCountKey is the Locals key where handlers report the rows they returned
+const auditRowCountKey = "audit_row_count"
+
+// AuditDataRead creates a middleware that records every request it wraps as a
+// data read in the audit trail, with the request path, query filters, row
+// count, status and client IP. It must run after the auth middleware and
+// before permission checks, so that denied requests are recorded too.
+func AuditDataRead(auditService *service.AuditService) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		err := c.Next()
+
+		principal := PrincipalFrom(c)
+		if principal == nil {
+			return err
+		}
+
+		status := c.Response().StatusCode()
+		if err != nil {
+			status = fiber.StatusInternalServerError
+			var fiberErr *fiber.Error
+			if errors.As(err, &fiberErr) {
+				status = fiberErr.Code
+			}
+		}
+
+		rowCount, _ := c.Locals(auditRowCountKey).(int)
+		auditService.RecordDataRead(principal, c.Path(), c.Queries(), rowCount, status, c.IP())
+		return err
+	}
+}
+
+// SetAuditRowCount reports the number of rows returned by a data read
+func SetAuditRowCount(c *fiber.Ctx, rowCount int) {
+	c.Locals(auditRowCountKey, rowCount)
+}
@@ -0,0 +1,117 @@
+package middleware
+
+import (
+	"nerp_wrapper/application/dto"
+	"nerp_wrapper/application/service"
+	"nerp_wrapper/domain/entity"
+	"nerp_wrapper/domain/repository"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Locals keys holding the verified access claims and the request principal
+const (
+	claimsKey    = "auth_claims"
+	principalKey = "auth_principal"
+)
+
+// apiKeyHeader is the request header carrying a machine-to-machine API key
+const apiKeyHeader = "X-API-Key"
+
+// NewAuthMiddleware creates a middleware that requires a valid Bearer access
+// token or X-API-Key header. Credentials of one tenant are rejected on
+// requests for another; credentials issued before tenants existed belong to
+// the default tenant.
+func NewAuthMiddleware(authService *service.AuthService, apiKeyService *service.APIKeyService, tenants repository.TenantRegistry) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if apiKey := c.Get(apiKeyHeader); apiKey != "" {
+			key, err := apiKeyService.Authenticate(apiKey, c.IP())
+			if err != nil {
+				return unauthorized(c, "Invalid API key")
+			}
+
+			tenantID := credentialTenant(tenants, key.TenantID)
+			if !sameTenant(c, tenantID) {
+				return wrongTenant(c)
+			}
+			c.Locals(principalKey, &entity.Principal{
+				TenantID:    tenantID,
+				Login:       "api-key:" + key.Name,
+				Permissions: key.Scopes,
+				APIKeyID:    key.ID,
+			})
+			return c.Next()
+		}
+
+		header := c.Get(fiber.HeaderAuthorization)
+		scheme, token, found := strings.Cut(header, " ")
+		if !found || !strings.EqualFold(scheme, "Bearer") || strings.TrimSpace(token) == "" {
+			return unauthorized(c, "Missing or malformed access token")
+		}
+
+		claims, err := authService.Authenticate(strings.TrimSpace(token))
+		if err != nil {
+			return unauthorized(c, "Invalid or expired access token")
+		}
+
+		claims.TenantID = credentialTenant(tenants, claims.TenantID)
+		if !sameTenant(c, claims.TenantID) {
+			return wrongTenant(c)
+		}
+		c.Locals(claimsKey, claims)
+		c.Locals(principalKey, &entity.Principal{
+			TenantID:    claims.TenantID,
+			UserID:      claims.UserID,
+			Login:       claims.Login,
+			Permissions: claims.Permissions,
+		})
+		return c.Next()
+	}
+}
+
+// ClaimsFrom returns the access claims stored by the auth middleware
+func ClaimsFrom(c *fiber.Ctx) *entity.AccessClaims {
+	claims, _ := c.Locals(claimsKey).(*entity.AccessClaims)
+	return claims
+}
+
+// PrincipalFrom returns the authenticated principal stored by the auth middleware
+func PrincipalFrom(c *fiber.Ctx) *entity.Principal {
+	principal, _ := c.Locals(principalKey).(*entity.Principal)
+	return principal
+}
+
+// credentialTenant returns the tenant of credentials, the default tenant for
+// those carrying none
+func credentialTenant(tenants repository.TenantRegistry, tenantID string) string {
+	if tenantID != "" {
+		return tenantID
+	}
+	if tenant, err := tenants.ResolveTenant(""); err == nil {
+		return tenant.ID
+	}
+	return ""
+}
+
+// sameTenant reports whether credentials of tenantID may serve the request.
+// Requests naming no tenant are served for the credentials' tenant.
+func sameTenant(c *fiber.Ctx, tenantID string) bool {
+	requested := TenantFrom(c)
+	return requested == "" || requested == tenantID
+}
+
+func wrongTenant(c *fiber.Ctx) error {
+	return c.Status(fiber.StatusForbidden).JSON(dto.ErrorResponse{
+		Success: false,
+		Message: "Credentials belong to another tenant",
+	})
+}
+
+func unauthorized(c *fiber.Ctx, message string) error {
+	c.Set(fiber.HeaderWWWAuthenticate, "Bearer")
+	return c.Status(fiber.StatusUnauthorized).JSON(dto.ErrorResponse{
+		Success: false,
+		Message: message,
+	})
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"nerp_wrapper/application/dto"
+	"nerp_wrapper/domain/entity"
+	"strconv"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequirePermission creates a middleware that rejects principals lacking the given permission.
+// It must run after the auth middleware.
+func RequirePermission(permission entity.Permission) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		principal := PrincipalFrom(c)
+		if principal == nil {
+			return unauthorized(c, "Missing or malformed access token")
+		}
+		if !principal.HasPermission(permission) {
+			return c.Status(fiber.StatusForbidden).JSON(dto.ErrorResponse{
+				Success: false,
+				Message: "Missing permission: " + string(permission),
+			})
+		}
+		return c.Next()
+	}
+}
+
+// RequirePermissionUnlessSelf creates a middleware that rejects principals
+// lacking the given permission, unless the user ID route parameter names the
+// principal itself. It must run after the auth middleware.
+func RequirePermissionUnlessSelf(permission entity.Permission, param string) fiber.Handler {
+	requirePermission := RequirePermission(permission)
+	return func(c *fiber.Ctx) error {
+		principal := PrincipalFrom(c)
+		if principal != nil && !principal.IsAPIKey() && c.Params(param) == strconv.FormatInt(principal.UserID, 10) {
+			return c.Next()
+		}
+		return requirePermission(c)
+	}
+}
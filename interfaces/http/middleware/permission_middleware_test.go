@@ -0,0 +1,62 @@
+package middleware
+
+import (
+	"nerp_wrapper/domain/entity"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// permissionApp serves the routes guarded like the real ones, with the
+// principal put in place by the test instead of the auth middleware
+func permissionApp(principal *entity.Principal) *fiber.App {
+	app := fiber.New()
+	app.Use(func(c *fiber.Ctx) error {
+		if principal != nil {
+			c.Locals(principalKey, principal)
+		}
+		return c.Next()
+	})
+	ok := func(c *fiber.Ctx) error { return c.SendStatus(fiber.StatusOK) }
+	app.Get("/invoices", RequirePermission(entity.PermissionInvoicesRead), ok)
+	app.Get("/auth/user-info/:id", RequirePermissionUnlessSelf(entity.PermissionUsersRead, "id"), ok)
+	return app
+}
+
+func TestPermissionMiddleware(t *testing.T) {
+	salesperson := &entity.Principal{UserID: 6, Permissions: []entity.Permission{entity.PermissionSalesRead}}
+	accountant := &entity.Principal{UserID: 7, Permissions: []entity.Permission{entity.PermissionInvoicesRead}}
+	admin := &entity.Principal{UserID: 2, Permissions: []entity.Permission{entity.PermissionAdmin}}
+	apiKey := &entity.Principal{APIKeyID: "key", Permissions: []entity.Permission{entity.PermissionSalesRead}}
+
+	tests := []struct {
+		name      string
+		principal *entity.Principal
+		path      string
+		status    int
+	}{
+		{"no principal", nil, "/invoices", http.StatusUnauthorized},
+		{"missing permission", salesperson, "/invoices", http.StatusForbidden},
+		{"granted permission", accountant, "/invoices", http.StatusOK},
+		{"admin", admin, "/invoices", http.StatusOK},
+		{"own profile", salesperson, "/auth/user-info/6", http.StatusOK},
+		{"other profile", salesperson, "/auth/user-info/7", http.StatusForbidden},
+		{"other profile with users:read", admin, "/auth/user-info/7", http.StatusOK},
+		{"api key has no profile of its own", apiKey, "/auth/user-info/0", http.StatusForbidden},
+		{"profile without principal", nil, "/auth/user-info/6", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := permissionApp(tt.principal).Test(httptest.NewRequest(http.MethodGet, tt.path, nil))
+			if err != nil {
+				t.Fatalf("request failed: %v", err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.status {
+				t.Errorf("GET %s answered %d, want %d", tt.path, resp.StatusCode, tt.status)
+			}
+		})
+	}
+}
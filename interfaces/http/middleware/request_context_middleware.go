@@ -0,0 +1,25 @@
+package middleware
+
+import (
+	"context"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// RequestContext gives each request the context handlers pass to the
+// services, cancelled once the request completes or its client disconnects.
+// fasthttp does not report disconnects, so the connection is watched for the
+// end of stream a departed client leaves behind. Connections that cannot be
+// watched, such as TLS ones, or clients that half-close after sending their
+// request leave the Odoo calls bounded by their deadlines alone.
+func RequestContext() fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ctx, cancel := context.WithCancel(c.UserContext())
+		defer cancel()
+		if stop := watchDisconnect(c.Context().Conn(), cancel); stop != nil {
+			defer stop()
+		}
+		c.SetUserContext(ctx)
+		return c.Next()
+	}
+}
@@ -0,0 +1,13 @@
+//go:build !unix
+
+package middleware
+
+import (
+	"context"
+	"net"
+)
+
+// watchDisconnect cannot watch connections on this platform
+func watchDisconnect(conn net.Conn, cancel context.CancelFunc) func() {
+	return nil
+}
@@ -0,0 +1,52 @@
+//go:build unix
+
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net"
+	"syscall"
+	"time"
+)
+
+// watchDisconnect calls cancel once the client closes conn. It peeks at the
+// socket while it waits, so a pipelined next request is left for the server
+// to read, and it stops watching as soon as data arrives. The returned
+// function stops the watch and must be called before the server reads from
+// conn again; it is nil when conn cannot be watched.
+func watchDisconnect(conn net.Conn, cancel context.CancelFunc) func() {
+	sysConn, ok := conn.(syscall.Conn)
+	if !ok {
+		return nil
+	}
+	raw, err := sysConn.SyscallConn()
+	if err != nil {
+		return nil
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		buf := make([]byte, 1)
+		// Returning false waits for the socket to turn readable
+		raw.Read(func(fd uintptr) bool {
+			n, _, err := syscall.Recvfrom(int(fd), buf, syscall.MSG_PEEK|syscall.MSG_DONTWAIT)
+			switch {
+			case errors.Is(err, syscall.EAGAIN), errors.Is(err, syscall.EINTR):
+				return false
+			case (n == 0 && err == nil), errors.Is(err, syscall.ECONNRESET):
+				cancel()
+			}
+			return true
+		})
+	}()
+
+	return func() {
+		// A passed read deadline wakes the watcher; the server sets its own
+		// deadlines before reading, and none when it has no timeouts
+		conn.SetReadDeadline(time.Now())
+		<-stopped
+		conn.SetReadDeadline(time.Time{})
+	}
+}
@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"nerp_wrapper/application/service"
+	"nerp_wrapper/domain/entity"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ResponseCache serves repeated GET requests of read routes from the
+// response cache. Responses carry a strong ETag of their body and a private
+// Cache-Control, and a request whose If-None-Match lists the current ETag
+// gets 304 Not Modified. A request sending Cache-Control: no-cache skips
+// the lookup and refreshes the entry.
+type ResponseCache struct {
+	cacheService *service.ResponseCacheService
+}
+
+// NewResponseCache creates a new instance of ResponseCache
+func NewResponseCache(cacheService *service.ResponseCacheService) *ResponseCache {
+	return &ResponseCache{cacheService: cacheService}
+}
+
+// Route creates a middleware caching the responses of route, the path the
+// TTLs are configured by. It must run after the auth middleware and inside
+// the audit middleware, which then records cache hits with their row count.
+func (m *ResponseCache) Route(route string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		ttl := m.cacheService.TTL(route)
+		principal := PrincipalFrom(c)
+		if ttl <= 0 || principal == nil || c.Method() != fiber.MethodGet {
+			return c.Next()
+		}
+		query, err := url.ParseQuery(string(c.Request().URI().QueryString()))
+		if err != nil {
+			return c.Next()
+		}
+
+		ctx := c.UserContext()
+		key := m.cacheService.Key(principal, route, query)
+		if !strings.Contains(strings.ToLower(c.Get(fiber.HeaderCacheControl)), "no-cache") {
+			if cached, found := m.cacheService.Get(ctx, principal.TenantID, route, key); found {
+				SetAuditRowCount(c, cached.RowCount)
+				age := time.Since(cached.StoredAt)
+				c.Set(fiber.HeaderAge, strconv.Itoa(int(age.Seconds())))
+				return m.respond(c, cached, ttl-age, "HIT")
+			}
+		}
+
+		if err := c.Next(); err != nil {
+			return err
+		}
+		if c.Response().StatusCode() != fiber.StatusOK {
+			return nil
+		}
+
+		body := append([]byte(nil), c.Response().Body()...)
+		rowCount, _ := c.Locals(auditRowCountKey).(int)
+		response := &entity.CachedResponse{
+			Body:        body,
+			ContentType: string(c.Response().Header.ContentType()),
+			ETag:        service.ETag(body),
+			RowCount:    rowCount,
+			StoredAt:    time.Now(),
+		}
+		m.cacheService.Put(ctx, principal.TenantID, route, key, response)
+		return m.respond(c, response, ttl, "MISS")
+	}
+}
+
+// respond writes response, or 304 when the client already holds it
+func (m *ResponseCache) respond(c *fiber.Ctx, response *entity.CachedResponse, maxAge time.Duration, status string) error {
+	c.Set(fiber.HeaderETag, response.ETag)
+	c.Set(fiber.HeaderCacheControl, "private, max-age="+strconv.Itoa(max(int(maxAge.Seconds()), 0)))
+	c.Set(fiber.HeaderVary, "Authorization, X-API-Key, X-Tenant")
+	c.Set("X-Cache", status)
+
+	if etagMatches(c.Get(fiber.HeaderIfNoneMatch), response.ETag) {
+		c.Response().ResetBody()
+		return c.SendStatus(fiber.StatusNotModified)
+	}
+	c.Set(fiber.HeaderContentType, response.ContentType)
+	return c.Status(fiber.StatusOK).Send(response.Body)
+}
+
+// etagMatches reports whether an If-None-Match header lists etag, comparing
+// weakly as RFC 9110 requires for If-None-Match
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"nerp_wrapper/application/dto"
+	"nerp_wrapper/domain/repository"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// tenantKey is the Locals key holding the tenant requested by the client
+const tenantKey = "tenant_id"
+
+// tenantHeader is the request header selecting a tenant explicitly
+const tenantHeader = "X-Tenant"
+
+// NewTenantMiddleware creates a middleware that records the tenant a request
+// asks for, from the X-Tenant header or else the subdomain of the host.
+// Requests naming no tenant fall back to the token's tenant or the default one.
+func NewTenantMiddleware(tenants repository.TenantRegistry) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if id := c.Get(tenantHeader); id != "" {
+			tenant, err := tenants.ResolveTenant(id)
+			if err != nil {
+				return c.Status(fiber.StatusBadRequest).JSON(dto.ErrorResponse{
+					Success: false,
+					Message: "Unknown tenant: " + id,
+				})
+			}
+			c.Locals(tenantKey, tenant.ID)
+		} else if tenant := tenants.TenantForHost(c.Hostname()); tenant != nil {
+			c.Locals(tenantKey, tenant.ID)
+		}
+		return c.Next()
+	}
+}
+
+// TenantFrom returns the tenant requested by the client, or an empty string
+func TenantFrom(c *fiber.Ctx) string {
+	id, _ := c.Locals(tenantKey).(string)
+	return id
+}
@@ -1,28 +1,50 @@
 package router
 
 import (
+	"nerp_wrapper/domain/entity"
 	"nerp_wrapper/interfaces/http/handler"
+	"nerp_wrapper/interfaces/http/middleware"
 
 	"github.com/gofiber/fiber/v2"
 )
 
 // SetupRouter sets up all the routes for the application
-func SetupRouter(app *fiber.App, authHandler *handler.AuthHandler, saleHandler *handler.SaleHandler, invoiceHandler *handler.InvoiceHandler) {
+func SetupRouter(app *fiber.App, authHandler *handler.AuthHandler, saleHandler *handler.SaleHandler, invoiceHandler *handler.InvoiceHandler, apiKeyHandler *handler.APIKeyHandler, loginGuardHandler *handler.LoginGuardHandler, totpHandler *handler.TOTPHandler, auditHandler *handler.AuditHandler, cacheHandler *handler.CacheHandler, healthHandler *handler.HealthHandler, authMiddleware, auditMiddleware fiber.Handler, responseCache *middleware.ResponseCache) {
+	// Probe routes
+	app.Get("/healthz", healthHandler.Liveness)
+	app.Get("/readyz", healthHandler.Readiness)
+
 	// Auth routes
 	auth := app.Group("/auth")
 	auth.Post("/login", authHandler.Login)
-	auth.Post("/logout", authHandler.Logout)
-	auth.Get("/user-info/:id", authHandler.GetUserInfo)
+	auth.Post("/login/totp", authHandler.LoginTOTP)
+	auth.Post("/refresh", authHandler.Refresh)
+	auth.Post("/logout", authMiddleware, authHandler.Logout)
+	auth.Post("/totp/enroll", authMiddleware, totpHandler.BeginEnrollment)
+	auth.Post("/totp/confirm", authMiddleware, totpHandler.ConfirmEnrollment)
+	auth.Delete("/totp", authMiddleware, totpHandler.Disable)
+	auth.Get("/user-info/:id", authMiddleware, auditMiddleware, middleware.RequirePermissionUnlessSelf(entity.PermissionUsersRead, "id"), authHandler.GetUserInfo)
 
 	// Sales routes
-	sales := app.Group("/sales")
+	sales := app.Group("/sales", authMiddleware, auditMiddleware, middleware.RequirePermission(entity.PermissionSalesRead))
 	sales.Get("/", saleHandler.GetAllSaleOrders)
-	sales.Get("/daily-summary", saleHandler.GetDailySalesSummary)
-	sales.Get("/period-summary", saleHandler.GetPeriodSalesSummary)
+	sales.Get("/daily-summary", responseCache.Route("/sales/daily-summary"), saleHandler.GetDailySalesSummary)
+	sales.Get("/period-summary", responseCache.Route("/sales/period-summary"), saleHandler.GetPeriodSalesSummary)
 
 	// Invoice routes
-	invoices := app.Group("/invoices")
+	invoices := app.Group("/invoices", authMiddleware, auditMiddleware, middleware.RequirePermission(entity.PermissionInvoicesRead))
 	invoices.Get("/", invoiceHandler.GetAllInvoices)
-	invoices.Get("/daily-summary", invoiceHandler.GetDailyInvoiceSummary)
-	invoices.Get("/period-summary", invoiceHandler.GetPeriodInvoiceSummary)
+	invoices.Get("/daily-summary", responseCache.Route("/invoices/daily-summary"), invoiceHandler.GetDailyInvoiceSummary)
+	invoices.Get("/period-summary", responseCache.Route("/invoices/period-summary"), invoiceHandler.GetPeriodInvoiceSummary)
+
+	// Admin routes
+	admin := app.Group("/admin", authMiddleware, middleware.RequirePermission(entity.PermissionAdmin))
+	admin.Post("/api-keys", apiKeyHandler.CreateAPIKey)
+	admin.Get("/api-keys", apiKeyHandler.ListAPIKeys)
+	admin.Delete("/api-keys/:id", apiKeyHandler.RevokeAPIKey)
+	admin.Get("/lockouts", loginGuardHandler.ListLockouts)
+	admin.Delete("/lockouts/:key", loginGuardHandler.ClearLockout)
+	admin.Get("/audit", auditHandler.ListEvents)
+	admin.Get("/cache", cacheHandler.GetStats)
+	admin.Delete("/cache/responses", cacheHandler.PurgeResponses)
 }
@@ -0,0 +1,381 @@
+// Package routetest checks every route of a running wrapper, one serving
+// the fixtures either through a fake Odoo or from memory. Expectations are
+// derived from the fixtures. cmd/e2e runs the checks against a wrapper over
+// the network and the tests run them against one started in-process.
+package routetest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"nerp_wrapper/application/dto"
+	"nerp_wrapper/domain/entity"
+	"nerp_wrapper/infrastructure/memory"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Reporter receives the outcome of every check; *testing.T satisfies it
+type Reporter interface {
+	Logf(format string, args ...interface{})
+	Errorf(format string, args ...interface{})
+}
+
+// checker sends requests to the API and reports the outcome of expectations
+type checker struct {
+	baseURL string
+	client  *http.Client
+	report  Reporter
+}
+
+// response is a decoded API response
+type response struct {
+	status int
+	body   map[string]interface{}
+	raw    []byte
+}
+
+// Run checks every route of the wrapper at baseURL, sending requests with
+// client. The wrapper must serve fixtures, from Odoo or from memory, and must
+// not have served logins yet, as failed ones back off the client.
+func Run(baseURL string, client *http.Client, fixtures *memory.Fixtures, report Reporter) {
+	c := &checker{baseURL: baseURL, client: client, report: report}
+	c.run(fixtures)
+}
+
+func (c *checker) run(fixtures *memory.Fixtures) {
+	// Probes
+	c.expectStatus("GET /healthz", c.do("GET", "/healthz", "", nil), http.StatusOK)
+	c.expectStatus("GET /readyz", c.do("GET", "/readyz", "", nil), http.StatusOK)
+
+	// Authentication
+	admin := c.login("admin", "admin")
+	sales := c.login("sales", "sales")
+	accounting := c.login("accounting", "accounting")
+	if admin == nil || sales == nil || accounting == nil {
+		return
+	}
+	c.expectStatus("GET /sales without a token", c.do("GET", "/sales/", "", nil), http.StatusUnauthorized)
+	c.expectStatus("GET /auth/user-info/:id", c.do("GET", fmt.Sprintf("/auth/user-info/%d", fixtures.Users[0].ID), admin.AccessToken, nil), http.StatusOK)
+	c.expectStatus("GET /auth/user-info/:id of oneself", c.do("GET", fmt.Sprintf("/auth/user-info/%d", fixtureUserID(fixtures, "sales")), sales.AccessToken, nil), http.StatusOK)
+	c.expectStatus("GET /auth/user-info/:id of another user", c.do("GET", fmt.Sprintf("/auth/user-info/%d", fixtureUserID(fixtures, "admin")), sales.AccessToken, nil), http.StatusForbidden)
+
+	// Sales
+	var ownOrders int
+	for _, order := range fixtures.SaleOrders {
+		if order.SalespersonID == fixtureUserID(fixtures, "sales") {
+			ownOrders++
+		}
+	}
+	resp := c.do("GET", "/sales/?page_size=2", admin.AccessToken, nil)
+	if c.expectStatus("GET /sales", resp, http.StatusOK) {
+		c.expect("GET /sales returns one page", len(items(resp)) == 2, "got %d items", len(items(resp)))
+	}
+	resp = c.do("GET", "/sales/?page_size=100", sales.AccessToken, nil)
+	if c.expectStatus("GET /sales as a salesperson", resp, http.StatusOK) {
+		c.expect("GET /sales is limited to own orders", len(items(resp)) <= ownOrders, "got %d items, own orders %d", len(items(resp)), ownOrders)
+		for _, item := range items(resp) {
+			if int64(item["SalespersonID"].(float64)) != fixtureUserID(fixtures, "sales") {
+				c.fail("GET /sales is limited to own orders", "order %v belongs to %v", item["ID"], item["SalespersonID"])
+			}
+		}
+	}
+	resp = c.do("GET", "/sales/?company_id=1&page_size=100", admin.AccessToken, nil)
+	if c.expectStatus("GET /sales?company_id", resp, http.StatusOK) {
+		for _, item := range items(resp) {
+			c.expect("GET /sales?company_id filters by company", item["CompanyID"].(float64) == 1, "order %v belongs to company %v", item["ID"], item["CompanyID"])
+		}
+	}
+	c.expectStatus("GET /sales?company_id=x", c.do("GET", "/sales/?company_id=x", admin.AccessToken, nil), http.StatusBadRequest)
+	c.checkSaleFilters(fixtures, admin.AccessToken)
+	c.expectStatus("GET /sales/daily-summary", c.do("GET", "/sales/daily-summary", admin.AccessToken, nil), http.StatusOK)
+	c.expectStatus("GET /sales/daily-summary?consolidated=true", c.do("GET", "/sales/daily-summary?consolidated=true", admin.AccessToken, nil), http.StatusBadRequest)
+	resp = c.do("GET", "/sales/period-summary?period_type=YEARLY&consolidated=true", admin.AccessToken, nil)
+	if c.expectStatus("GET /sales/period-summary", resp, http.StatusOK) {
+		c.expect("GET /sales/period-summary?consolidated reports companies", resp.body["companies"] != nil, "no companies in %s", resp.raw)
+	}
+	c.checkPeriodItems("/sales/period-summary", "orders", "order_count", admin.AccessToken)
+	c.expectStatus("GET /sales as accounting", c.do("GET", "/sales/", accounting.AccessToken, nil), http.StatusForbidden)
+
+	// Invoices
+	resp = c.do("GET", "/invoices/?page_size=100", admin.AccessToken, nil)
+	if c.expectStatus("GET /invoices", resp, http.StatusOK) {
+		c.expect("GET /invoices returns invoices", len(items(resp)) > 0, "no invoices")
+	}
+	c.checkInvoiceFilters(fixtures, admin.AccessToken)
+	c.expectStatus("GET /invoices/daily-summary", c.do("GET", "/invoices/daily-summary", accounting.AccessToken, nil), http.StatusOK)
+	c.expectStatus("GET /invoices/daily-summary?consolidated=true", c.do("GET", "/invoices/daily-summary?consolidated=true", accounting.AccessToken, nil), http.StatusBadRequest)
+	resp = c.do("GET", "/invoices/period-summary?period_type=YEARLY", admin.AccessToken, nil)
+	if c.expectStatus("GET /invoices/period-summary", resp, http.StatusOK) {
+		for _, item := range items(resp) {
+			c.expect("GET /invoices/period-summary groups by invoice date", item["date"] != "0001-01-01T00:00:00Z", "group without a date in %s", resp.raw)
+		}
+	}
+	c.checkPeriodItems("/invoices/period-summary", "invoices", "invoice_count", admin.AccessToken)
+	c.expectStatus("GET /invoices as a salesperson", c.do("GET", "/invoices/", sales.AccessToken, nil), http.StatusForbidden)
+
+	// Administration
+	resp = c.do("POST", "/admin/api-keys", admin.AccessToken, dto.CreateAPIKeyRequest{Name: "e2e", Scopes: []string{"sales:read", "sales:read_all"}})
+	if c.expectStatus("POST /admin/api-keys", resp, http.StatusCreated) {
+		key, _ := resp.body["key"].(string)
+		req := c.request("GET", "/sales/", "", nil)
+		req.Header.Set("X-API-Key", key)
+		c.expectStatus("GET /sales with an API key", c.send(req), http.StatusOK)
+		c.expectStatus("GET /invoices with a sales-only API key", c.sendWithKey("/invoices/", key), http.StatusForbidden)
+		if apiKey, ok := resp.body["api_key"].(map[string]interface{}); ok {
+			c.expectStatus("DELETE /admin/api-keys/:id", c.do("DELETE", fmt.Sprintf("/admin/api-keys/%v", apiKey["id"]), admin.AccessToken, nil), http.StatusOK)
+			c.expectStatus("GET /sales with a revoked API key", c.sendWithKey("/sales/", key), http.StatusUnauthorized)
+		}
+	}
+	for _, scopes := range [][]string{{"sales:read"}, {"sales:read", "sales:read_team"}} {
+		name := "POST /admin/api-keys with " + strings.Join(scopes, ",")
+		c.expectStatus(name, c.do("POST", "/admin/api-keys", admin.AccessToken, dto.CreateAPIKeyRequest{Name: "e2e", Scopes: scopes}), http.StatusBadRequest)
+	}
+	c.expectStatus("GET /admin/api-keys", c.do("GET", "/admin/api-keys", admin.AccessToken, nil), http.StatusOK)
+	c.expectStatus("GET /admin/lockouts", c.do("GET", "/admin/lockouts", admin.AccessToken, nil), http.StatusOK)
+	c.expectStatus("DELETE /admin/lockouts/:key", c.do("DELETE", "/admin/lockouts/user:nobody", admin.AccessToken, nil), http.StatusNotFound)
+	c.expectStatus("GET /admin/audit", c.do("GET", "/admin/audit?limit=10", admin.AccessToken, nil), http.StatusOK)
+	c.expectStatus("GET /admin/audit as a salesperson", c.do("GET", "/admin/audit", sales.AccessToken, nil), http.StatusForbidden)
+
+	// Token lifecycle
+	resp = c.do("POST", "/auth/refresh", "", dto.RefreshRequest{RefreshToken: sales.RefreshToken})
+	c.expectStatus("POST /auth/refresh", resp, http.StatusOK)
+	c.expectStatus("POST /auth/refresh with a used token", c.do("POST", "/auth/refresh", "", dto.RefreshRequest{RefreshToken: sales.RefreshToken}), http.StatusUnauthorized)
+	c.expectStatus("POST /auth/logout", c.do("POST", "/auth/logout", accounting.AccessToken, dto.LogoutRequest{RefreshToken: accounting.RefreshToken}), http.StatusOK)
+	c.expectStatus("GET /invoices after logout", c.do("GET", "/invoices/", accounting.AccessToken, nil), http.StatusUnauthorized)
+
+	// Failed logins back off further attempts from this client, so this runs last
+	c.expectStatus("login with a wrong password", c.do("POST", "/auth/login", "", dto.LoginRequest{Username: "admin", Password: "wrong"}), http.StatusUnauthorized)
+}
+
+// checkPeriodItems checks that a period summary lists records only with
+// include_items and that the listed records add up to the grouped totals
+func (c *checker) checkPeriodItems(path, listKey, countKey, token string) {
+	const allTime = "?start_date=2000-01-01&end_date=2100-12-31"
+	name := "GET " + path
+	grouped := c.do("GET", path+allTime, token, nil)
+	detailed := c.do("GET", path+allTime+"&include_items=true", token, nil)
+	if !c.expectStatus(name, grouped, http.StatusOK) || !c.expectStatus(name+"?include_items", detailed, http.StatusOK) {
+		return
+	}
+
+	for _, day := range items(grouped) {
+		c.expect(name+" omits records by default", day[listKey] == nil, "day lists %s in %s", listKey, grouped.raw)
+	}
+	c.expect(name+" totals do not depend on include_items",
+		grouped.body["total_amount"] == detailed.body["total_amount"] && grouped.body[countKey] == detailed.body[countKey],
+		"totals %v/%v with items, %v/%v without", detailed.body["total_amount"], detailed.body[countKey], grouped.body["total_amount"], grouped.body[countKey])
+
+	var amount, count float64
+	for _, day := range items(detailed) {
+		records, _ := day[listKey].([]interface{})
+		for _, record := range records {
+			amount += record.(map[string]interface{})["amount_total"].(float64)
+			count++
+		}
+	}
+	total, _ := detailed.body["total_amount"].(float64)
+	c.expect(name+"?include_items lists every record", count == detailed.body[countKey] && math.Abs(amount-total) < 0.005,
+		"%v records totalling %.2f, summary has %v totalling %.2f", count, amount, detailed.body[countKey], total)
+}
+
+// checkSaleFilters checks that each /sales list filter yields the total of
+// the fixture orders it should match, and that bad filters are rejected
+func (c *checker) checkSaleFilters(fixtures *memory.Fixtures, token string) {
+	day := func(value string) time.Time {
+		t, _ := time.Parse(time.DateOnly, value)
+		return t
+	}
+	cases := []struct {
+		query string
+		match func(order *entity.SaleOrder) bool
+	}{
+		{"state=sale", func(o *entity.SaleOrder) bool { return o.State == "sale" }},
+		{"state=draft,sent&state=cancel", func(o *entity.SaleOrder) bool { return o.State == "draft" || o.State == "sent" || o.State == "cancel" }},
+		{"partner_id=9", func(o *entity.SaleOrder) bool { return o.State != "cancel" && o.Partner == 9 }},
+		{"salesperson_id=6", func(o *entity.SaleOrder) bool { return o.State != "cancel" && o.SalespersonID == 6 }},
+		{"date_from=2026-09-05&date_to=2026-10-03", func(o *entity.SaleOrder) bool {
+			return o.State != "cancel" && !o.DateOrder.Before(day("2026-09-05")) && o.DateOrder.Before(day("2026-10-04"))
+		}},
+		{"amount_min=780&amount_max=2150", func(o *entity.SaleOrder) bool {
+			return o.State != "cancel" && o.AmountTotal >= 780 && o.AmountTotal <= 2150
+		}},
+		{"q=aZuRe", func(o *entity.SaleOrder) bool {
+			return o.State != "cancel" && strings.Contains(strings.ToLower(o.Name+" "+o.ClientOrderRef+" "+o.PartnerName), "azure")
+		}},
+		{"q=S00007&state=sale,done", func(o *entity.SaleOrder) bool { return o.Name == "S00007" && (o.State == "sale" || o.State == "done") }},
+	}
+	for _, tc := range cases {
+		var want float64
+		for _, order := range fixtures.SaleOrders {
+			if tc.match(order) {
+				want++
+			}
+		}
+		name := "GET /sales?" + tc.query
+		resp := c.do("GET", "/sales/?page_size=1&"+tc.query, token, nil)
+		if c.expectStatus(name, resp, http.StatusOK) {
+			c.expect(name+" counts matching orders", resp.body["total_items"] == want, "total_items %v, want %v", resp.body["total_items"], want)
+		}
+	}
+
+	for _, query := range []string{"state=open", "partner_id=0", "salesperson_id=x", "team_id=-1", "date_from=2026-02-30",
+		"date_from=2026-10-02&date_to=2026-10-01", "amount_min=abc", "amount_min=10&amount_max=5", "q=" + strings.Repeat("x", 101)} {
+		c.expectStatus("GET /sales?"+query, c.do("GET", "/sales/?"+query, token, nil), http.StatusBadRequest)
+	}
+}
+
+// checkInvoiceFilters checks that each /invoices list filter yields the
+// total of the fixture invoices it should match, and that bad filters are
+// rejected
+func (c *checker) checkInvoiceFilters(fixtures *memory.Fixtures, token string) {
+	day := func(value string) time.Time {
+		t, _ := time.Parse(time.DateOnly, value)
+		return t
+	}
+	cases := []struct {
+		query string
+		match func(invoice *entity.Invoice) bool
+	}{
+		{"move_type=in_invoice,in_refund", func(i *entity.Invoice) bool {
+			return i.State != "cancel" && (i.Type == "in_invoice" || i.Type == "in_refund")
+		}},
+		{"state=draft&state=cancel", func(i *entity.Invoice) bool { return i.State == "draft" || i.State == "cancel" }},
+		{"payment_state=partial,paid", func(i *entity.Invoice) bool {
+			state := i.DerivePaymentState()
+			return i.State != "cancel" && (state == "partial" || state == "paid")
+		}},
+		{"payment_state=not_paid&state=posted", func(i *entity.Invoice) bool {
+			return i.State == "posted" && i.DerivePaymentState() == "not_paid"
+		}},
+		{"journal_id=1&currency_id=1", func(i *entity.Invoice) bool {
+			return i.State != "cancel" && i.JournalID == 1 && i.CurrencyID == 1
+		}},
+		{"partner_id=10", func(i *entity.Invoice) bool { return i.State != "cancel" && i.Partner == 10 }},
+		{"invoice_date_from=2026-09-06&invoice_date_to=2026-10-04", func(i *entity.Invoice) bool {
+			return i.State != "cancel" && !i.DateInvoice.Before(day("2026-09-06")) && i.DateInvoice.Before(day("2026-10-05"))
+		}},
+		{"due_date_to=2026-10-20", func(i *entity.Invoice) bool { return i.State != "cancel" && i.DateDue.Before(day("2026-10-21")) }},
+	}
+	for _, tc := range cases {
+		var want float64
+		for _, invoice := range fixtures.Invoices {
+			if tc.match(invoice) {
+				want++
+			}
+		}
+		name := "GET /invoices?" + tc.query
+		resp := c.do("GET", "/invoices/?page_size=1&"+tc.query, token, nil)
+		if c.expectStatus(name, resp, http.StatusOK) {
+			c.expect(name+" counts matching invoices", resp.body["total_items"] == want, "total_items %v, want %v", resp.body["total_items"], want)
+		}
+	}
+
+	for _, query := range []string{"move_type=entry", "state=open", "payment_state=in_payment", "journal_id=0", "partner_id=x",
+		"currency_id=-2", "invoice_date_to=2026-13-01", "due_date_from=2026-11-02&due_date_to=2026-11-01"} {
+		c.expectStatus("GET /invoices?"+query, c.do("GET", "/invoices/?"+query, token, nil), http.StatusBadRequest)
+	}
+}
+
+// login signs in and returns the issued tokens, or nil when login fails
+func (c *checker) login(username, password string) *dto.Token {
+	name := "login as " + username
+	resp := c.do("POST", "/auth/login", "", dto.LoginRequest{Username: username, Password: password})
+	if !c.expectStatus(name, resp, http.StatusOK) {
+		return nil
+	}
+	var result dto.LoginResponse
+	if err := json.Unmarshal(resp.raw, &result); err != nil || result.Token == nil {
+		c.fail(name, "no token in %s", resp.raw)
+		return nil
+	}
+	return result.Token
+}
+
+// request builds a request with an optional bearer token and JSON body
+func (c *checker) request(method, path, token string, body interface{}) *http.Request {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			panic(fmt.Sprintf("failed to encode request body: %v", err))
+		}
+		reader = bytes.NewReader(data)
+	}
+	req, err := http.NewRequest(method, c.baseURL+path, reader)
+	if err != nil {
+		panic(fmt.Sprintf("failed to build request: %v", err))
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	return req
+}
+
+func (c *checker) do(method, path, token string, body interface{}) *response {
+	return c.send(c.request(method, path, token, body))
+}
+
+func (c *checker) sendWithKey(path, key string) *response {
+	req := c.request("GET", path, "", nil)
+	req.Header.Set("X-API-Key", key)
+	return c.send(req)
+}
+
+// send performs the request; transport errors are reported as status 0
+func (c *checker) send(req *http.Request) *response {
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return &response{raw: []byte(err.Error())}
+	}
+	defer resp.Body.Close()
+
+	raw, _ := io.ReadAll(resp.Body)
+	result := &response{status: resp.StatusCode, raw: raw}
+	_ = json.Unmarshal(raw, &result.body)
+	return result
+}
+
+func (c *checker) expectStatus(name string, resp *response, status int) bool {
+	return c.expect(name, resp.status == status, "status %d, want %d: %s", resp.status, status, resp.raw)
+}
+
+func (c *checker) expect(name string, ok bool, format string, args ...interface{}) bool {
+	if !ok {
+		c.fail(name, format, args...)
+		return false
+	}
+	c.report.Logf("PASS %s", name)
+	return true
+}
+
+func (c *checker) fail(name, format string, args ...interface{}) {
+	c.report.Errorf("FAIL %s: %s", name, fmt.Sprintf(format, args...))
+}
+
+// items returns the items array of a list or summary response
+func items(resp *response) []map[string]interface{} {
+	raw, _ := resp.body["items"].([]interface{})
+	result := make([]map[string]interface{}, 0, len(raw))
+	for _, item := range raw {
+		if m, ok := item.(map[string]interface{}); ok {
+			result = append(result, m)
+		}
+	}
+	return result
+}
+
+func fixtureUserID(fixtures *memory.Fixtures, login string) int64 {
+	for _, user := range fixtures.Users {
+		if user.Login == login {
+			return user.ID
+		}
+	}
+	return 0
+}
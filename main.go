@@ -1,57 +1,57 @@
 package main
 
 import (
+	"context"
 	"log"
-	"nerp_wrapper/application/service"
-	"nerp_wrapper/infrastructure/odoo"
-	"nerp_wrapper/interfaces/http/handler"
-	"nerp_wrapper/interfaces/http/router"
-
-	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/middleware/cors"
-	"github.com/gofiber/fiber/v2/middleware/logger"
-	"github.com/gofiber/fiber/v2/middleware/recover"
+	"nerp_wrapper/infrastructure/config"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
 func main() {
-	// Initialize Odoo repository
-	authRepo, err := odoo.NewOdooAuthRepository(
-		"sarawut.kh@needshopping.co",  // admin username
-		"$===(Amplication30)",         // admin password
-		"N_ERP",                       // database name
-		"https://erp.needshopping.co", // Odoo URL
-	)
+	// Load configuration from CONFIG_FILE (or config.yaml when present) and the environment
+	configPath := os.Getenv("CONFIG_FILE")
+	if configPath == "" {
+		if _, err := os.Stat("config.yaml"); err == nil {
+			configPath = "config.yaml"
+		}
+	}
+	cfg, err := config.Load(configPath)
 	if err != nil {
-		log.Fatalf("Failed to initialize Odoo repository: %v", err)
+		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
-	// Initialize repositories
-	saleRepo := odoo.NewOdooSaleRepository(authRepo.GetClient())
-	invoiceRepo := odoo.NewOdooInvoiceRepository(authRepo.GetClient())
-
-	// Initialize services
-	authService := service.NewAuthService(authRepo)
-	saleService := service.NewSaleService(saleRepo)
-	invoiceService := service.NewInvoiceService(invoiceRepo)
-
-	// Initialize handlers
-	authHandler := handler.NewAuthHandler(authService)
-	saleHandler := handler.NewSaleHandler(saleService)
-	invoiceHandler := handler.NewInvoiceHandler(invoiceService)
-
-	// Create Fiber app
-	app := fiber.New(fiber.Config{
-		AppName: "NERP Wrapper API",
-	})
+	srv, err := newServer(cfg)
+	if err != nil {
+		log.Fatalf("Failed to initialize server: %v", err)
+	}
+	defer srv.Close()
 
-	// Middleware
-	app.Use(recover.New())
-	app.Use(logger.New())
-	app.Use(cors.New())
+	// Start server and stop it gracefully on SIGINT or SIGTERM
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	// Setup routes
-	router.SetupRouter(app, authHandler, saleHandler, invoiceHandler)
+	listenErr := make(chan error, 1)
+	go func() {
+		listenErr <- srv.app.Listen(cfg.Server.ListenAddr)
+	}()
 
-	// Start server
-	log.Fatal(app.Listen(":3000"))
+	select {
+	case err := <-listenErr:
+		log.Fatalf("Failed to start server: %v", err)
+	case <-ctx.Done():
+	}
+	stop()
+
+	// Fail readiness long enough for load balancers to notice, then let
+	// in-flight Odoo calls finish before the stores close
+	srv.health.Drain()
+	log.Printf("Draining for %s before shutting down", cfg.Server.DrainPeriod)
+	time.Sleep(cfg.Server.DrainPeriod)
+	log.Printf("Shutting down, waiting up to %s for in-flight requests", cfg.Server.ShutdownTimeout)
+	if err := srv.app.ShutdownWithTimeout(cfg.Server.ShutdownTimeout); err != nil {
+		log.Printf("Graceful shutdown did not complete: %v", err)
+	}
 }
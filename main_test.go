@@ -0,0 +1,74 @@
+package main
+
+import (
+	"nerp_wrapper/infrastructure/config"
+	"nerp_wrapper/infrastructure/memory"
+	"nerp_wrapper/infrastructure/odoo/odootest"
+	"nerp_wrapper/interfaces/http/routetest"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// appTransport hands requests to the Fiber app in-process
+type appTransport struct {
+	srv *server
+}
+
+func (t appTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	return t.srv.app.Test(req, -1)
+}
+
+// startServer wires the wrapper from the environment set by the test
+func startServer(t *testing.T) *server {
+	t.Helper()
+	t.Setenv("JWT_SECRET", strings.Repeat("x", 40))
+	t.Setenv("AUDIT_LOG_PATH", filepath.Join(t.TempDir(), "audit.jsonl"))
+
+	cfg, err := config.Load("")
+	if err != nil {
+		t.Fatalf("failed to load configuration: %v", err)
+	}
+	srv, err := newServer(cfg)
+	if err != nil {
+		t.Fatalf("failed to initialize server: %v", err)
+	}
+	t.Cleanup(func() { srv.Close() })
+	return srv
+}
+
+func loadFixtures(t *testing.T) *memory.Fixtures {
+	t.Helper()
+	fixtures, err := memory.LoadFixtures("fixtures")
+	if err != nil {
+		t.Fatalf("failed to load fixtures: %v", err)
+	}
+	return fixtures
+}
+
+func TestRoutesAgainstFakeOdoo(t *testing.T) {
+	fixtures := loadFixtures(t)
+	fake := odootest.NewServer("nerp")
+	fake.Seed(fixtures)
+	odooServer := httptest.NewServer(fake)
+	defer odooServer.Close()
+
+	t.Setenv("ODOO_URL", odooServer.URL)
+	t.Setenv("ODOO_DATABASE", "nerp")
+	t.Setenv("ODOO_ADMIN_USERNAME", "admin")
+	t.Setenv("ODOO_ADMIN_PASSWORD", "admin")
+	srv := startServer(t)
+
+	routetest.Run("http://127.0.0.1", &http.Client{Transport: appTransport{srv}}, fixtures, t)
+}
+
+func TestRoutesAgainstFixtures(t *testing.T) {
+	fixtures := loadFixtures(t)
+	t.Setenv("DATA_SOURCE", "fixtures")
+	t.Setenv("FIXTURES_DIR", "fixtures")
+	srv := startServer(t)
+
+	routetest.Run("http://127.0.0.1", &http.Client{Transport: appTransport{srv}}, fixtures, t)
+}
@@ -0,0 +1,296 @@
+package main
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"log"
+	"nerp_wrapper/application/service"
+	"nerp_wrapper/domain/entity"
+	"nerp_wrapper/domain/repository"
+	"nerp_wrapper/infrastructure/cache"
+	"nerp_wrapper/infrastructure/config"
+	"nerp_wrapper/infrastructure/jsonl"
+	"nerp_wrapper/infrastructure/memory"
+	"nerp_wrapper/infrastructure/odoo"
+	"nerp_wrapper/infrastructure/odoo/cassette"
+	"nerp_wrapper/infrastructure/policy"
+	"nerp_wrapper/infrastructure/sqlite"
+	"nerp_wrapper/interfaces/http/handler"
+	"nerp_wrapper/interfaces/http/middleware"
+	"nerp_wrapper/interfaces/http/router"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/cors"
+	"github.com/gofiber/fiber/v2/middleware/logger"
+	"github.com/gofiber/fiber/v2/middleware/recover"
+)
+
+// server is the wired application
+type server struct {
+	app     *fiber.App
+	health  *service.HealthService
+	closers []func() error
+}
+
+// newServer wires the stores, services, handlers and routes configured by cfg
+// and starts their background maintenance
+func newServer(cfg *config.Config) (_ *server, err error) {
+	srv := &server{}
+	defer func() {
+		if err != nil {
+			srv.Close()
+		}
+	}()
+
+	// Initialize the cache store. The cache is best effort: a failing store
+	// counts errors in the cache statistics and requests read from Odoo.
+	var cacheStore repository.CacheStore
+	switch cfg.Cache.Backend {
+	case "redis":
+		redisStore := cache.NewRedisCacheStore(cfg.Cache.RedisAddr, cfg.Cache.RedisPassword, cfg.Cache.RedisDB, cfg.Cache.RedisPoolSize, cfg.Cache.RedisTimeout)
+		if err := redisStore.Ping(context.Background()); err != nil {
+			log.Printf("Cache server %s is not reachable yet: %v", cfg.Cache.RedisAddr, err)
+		}
+		cacheStore = redisStore
+	case "memory":
+		cacheStore = cache.NewLRUCacheStore(cfg.Cache.Capacity)
+	}
+	var cacheReporters []repository.CacheStatsReporter
+
+	// Initialize one Odoo session pool and admin connection per tenant, or
+	// fixture-backed repositories when running without Odoo
+	tenantRegistry := odoo.NewTenantRegistry(cfg.DefaultTenant)
+	var healthCheckers []repository.HealthChecker
+	var saleRepo repository.SaleRepository
+	var invoiceRepo repository.InvoiceRepository
+	if cfg.UsesFixtures() {
+		fixtures, err := memory.LoadFixtures(cfg.Data.FixturesDir)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load fixtures: %w", err)
+		}
+		log.Printf("Serving fixture data from %s instead of Odoo", cfg.Data.FixturesDir)
+
+		for _, tenantCfg := range cfg.Tenants {
+			tenant := &entity.Tenant{ID: tenantCfg.ID, Name: tenantCfg.Name, Subdomain: tenantCfg.Subdomain}
+			if err := tenantRegistry.Register(tenant, nil, memory.NewMemoryAuthRepository(fixtures.Users)); err != nil {
+				return nil, fmt.Errorf("failed to register tenant: %w", err)
+			}
+		}
+		saleRepo = memory.NewMemorySaleRepository(fixtures.SaleOrders)
+		invoiceRepo = memory.NewMemoryInvoiceRepository(fixtures.Invoices)
+	} else {
+		// Odoo clients use the default HTTP transport, so the cassette wraps it
+		if mode := cassette.Mode(cfg.Data.CassetteMode); mode != cassette.ModeOff {
+			transport, err := cassette.NewTransport(mode, cfg.Data.CassettePath, http.DefaultTransport)
+			if err != nil {
+				return nil, fmt.Errorf("failed to initialize cassette: %w", err)
+			}
+			http.DefaultTransport = transport
+			log.Printf("Odoo traffic is %sed with cassette %s", mode, cfg.Data.CassettePath)
+		}
+		// Calls abandoned at an operation's deadline still end in time
+		http.DefaultTransport = odoo.NewDeadlineTransport(http.DefaultTransport, cfg.Timeouts.Longest())
+
+		for _, tenantCfg := range cfg.Tenants {
+			resilience := odoo.NewResilience("odoo:"+tenantCfg.ID, odoo.ResilienceConfig{
+				MaxAttempts:      cfg.Resilience.MaxAttempts,
+				BaseDelay:        cfg.Resilience.BaseDelay,
+				MaxDelay:         cfg.Resilience.MaxDelay,
+				FailureThreshold: cfg.Resilience.BreakerThreshold,
+				Cooldown:         cfg.Resilience.BreakerCooldown,
+			})
+			clientPool := odoo.NewClientPool(tenantCfg.Odoo.URL, tenantCfg.Odoo.Database, tenantCfg.Odoo.SessionIdleTTL, resilience)
+			go clientPool.RunEviction(time.Minute)
+
+			authRepo, err := odoo.NewOdooAuthRepository(
+				tenantCfg.Odoo.AdminUsername,
+				tenantCfg.Odoo.AdminPassword,
+				tenantCfg.Odoo.Database,
+				tenantCfg.Odoo.URL,
+				clientPool,
+			)
+			if err != nil {
+				return nil, fmt.Errorf("failed to initialize Odoo repository for tenant %s: %w", tenantCfg.ID, err)
+			}
+
+			// API key callers have no Odoo user and query with the admin client
+			clientPool.UseServiceClients(authRepo.ServiceClients())
+
+			tenant := &entity.Tenant{ID: tenantCfg.ID, Name: tenantCfg.Name, Subdomain: tenantCfg.Subdomain}
+			if err := tenantRegistry.Register(tenant, clientPool, authRepo); err != nil {
+				return nil, fmt.Errorf("failed to register tenant: %w", err)
+			}
+
+			healthCheckers = append(healthCheckers, odoo.NewOdooHealthChecker(
+				tenantCfg.ID,
+				tenantCfg.Odoo.URL,
+				tenantCfg.Odoo.Database,
+				tenantCfg.Odoo.AdminUsername,
+				tenantCfg.Odoo.AdminPassword,
+				resilience,
+			))
+		}
+
+		// Partners, users, journals and currencies are shared by the lists
+		// of every user of a tenant
+		var masterData *odoo.MasterDataCache
+		if cacheStore != nil {
+			masterData = odoo.NewMasterDataCache(cacheStore, cfg.Cache.MasterDataTTL, cfg.Cache.MasterDataRevalidate)
+			cacheReporters = append(cacheReporters, masterData)
+		}
+		saleRepo = odoo.NewOdooSaleRepository(tenantRegistry, masterData)
+		invoiceRepo = odoo.NewOdooInvoiceRepository(tenantRegistry, masterData)
+	}
+
+	// Initialize token and API key stores
+	var tokenRepo repository.TokenRepository
+	var apiKeyRepo repository.APIKeyRepository
+	var totpRepo repository.TOTPRepository
+	var auditRepo repository.AuditRepository
+	switch cfg.Store.Backend {
+	case "sqlite":
+		db, err := sqlite.Open(cfg.Store.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open store: %w", err)
+		}
+		srv.closers = append(srv.closers, db.Close)
+		if tokenRepo, err = sqlite.NewSQLiteTokenRepository(db, cfg.DefaultTenant); err != nil {
+			return nil, fmt.Errorf("failed to initialize token store: %w", err)
+		}
+		if apiKeyRepo, err = sqlite.NewSQLiteAPIKeyRepository(db, cfg.DefaultTenant); err != nil {
+			return nil, fmt.Errorf("failed to initialize API key store: %w", err)
+		}
+		if totpRepo, err = sqlite.NewSQLiteTOTPRepository(db, cfg.DefaultTenant); err != nil {
+			return nil, fmt.Errorf("failed to initialize TOTP store: %w", err)
+		}
+		if auditRepo, err = sqlite.NewSQLiteAuditRepository(db, cfg.DefaultTenant); err != nil {
+			return nil, fmt.Errorf("failed to initialize audit store: %w", err)
+		}
+		healthCheckers = append(healthCheckers, sqlite.NewSQLiteHealthChecker(db))
+	default:
+		tokenRepo = memory.NewMemoryTokenRepository()
+		apiKeyRepo = memory.NewMemoryAPIKeyRepository()
+		totpRepo = memory.NewMemoryTOTPRepository()
+
+		// The audit trail always persists; without SQLite it goes to a JSONL file
+		jsonlAuditRepo, err := jsonl.NewJSONLAuditRepository(cfg.Store.AuditLogPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize audit store: %w", err)
+		}
+		srv.closers = append(srv.closers, jsonlAuditRepo.Close)
+		auditRepo = jsonlAuditRepo
+	}
+
+	// Initialize token signing secret
+	jwtSecret := []byte(cfg.Auth.JWTSecret)
+	if len(jwtSecret) == 0 {
+		log.Println("JWT_SECRET is not set, generating a random secret; tokens will not survive a restart")
+		jwtSecret = make([]byte, 32)
+		if _, err := rand.Read(jwtSecret); err != nil {
+			return nil, fmt.Errorf("failed to generate JWT secret: %w", err)
+		}
+	}
+
+	// Initialize access policy mapping Odoo groups to API permissions
+	accessPolicy := entity.DefaultAccessPolicy()
+	if cfg.Auth.AccessPolicyFile != "" {
+		accessPolicy, err = policy.LoadAccessPolicy(cfg.Auth.AccessPolicyFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load access policy: %w", err)
+		}
+	}
+
+	// Initialize services
+	tokenService := service.NewTokenService(jwtSecret, "nerp_wrapper", cfg.Auth.AccessTokenTTL, cfg.Auth.RefreshTokenTTL)
+	loginGuardConfig := service.DefaultLoginGuardConfig()
+	loginGuardConfig.UserThreshold = cfg.Auth.LoginMaxAttempts
+	loginGuardConfig.IPThreshold = cfg.Auth.LoginMaxAttemptsPerIP
+	loginGuard := service.NewLoginGuardService(loginGuardConfig)
+	auditService := service.NewAuditService(auditRepo)
+	totpService := service.NewTOTPService(totpRepo, "NERP Wrapper")
+	authService := service.NewAuthService(tenantRegistry, tokenRepo, tokenService, accessPolicy, loginGuard, totpService, auditService, cfg.Timeouts.Auth)
+	apiKeyService := service.NewAPIKeyService(apiKeyRepo)
+	saleService := service.NewSaleService(saleRepo, cfg.Timeouts.Read, cfg.Timeouts.Summary)
+	invoiceService := service.NewInvoiceService(invoiceRepo, cfg.Timeouts.Read, cfg.Timeouts.Summary)
+	responseCacheService := service.NewResponseCacheService(cacheStore, cfg.Cache.ResponseTTLs)
+	cacheService := service.NewCacheService(cfg.Cache.Backend, append(cacheReporters, responseCacheService)...)
+	healthService := service.NewHealthService(healthCheckers, cfg.Server.ReadinessCacheTTL, 5*time.Second)
+
+	// Periodically drop expired refresh tokens and blacklist entries
+	go func() {
+		for range time.Tick(time.Hour) {
+			if err := authService.PurgeExpiredTokens(); err != nil {
+				log.Printf("Failed to purge expired tokens: %v", err)
+			}
+		}
+	}()
+
+	// Periodically drop login failures that went stale
+	go func() {
+		for range time.Tick(loginGuardConfig.ResetAfter) {
+			loginGuard.PurgeStale()
+		}
+	}()
+
+	// Initialize handlers
+	authHandler := handler.NewAuthHandler(authService)
+	pageLimits := handler.PageSizeLimits{
+		Default: cfg.Pagination.DefaultPageSize,
+		Max:     cfg.Pagination.MaxPageSize,
+	}
+	saleHandler := handler.NewSaleHandler(saleService, pageLimits)
+	invoiceHandler := handler.NewInvoiceHandler(invoiceService, pageLimits)
+	apiKeyHandler := handler.NewAPIKeyHandler(apiKeyService)
+	loginGuardHandler := handler.NewLoginGuardHandler(loginGuard)
+	totpHandler := handler.NewTOTPHandler(totpService, authService)
+	auditHandler := handler.NewAuditHandler(auditService)
+	cacheHandler := handler.NewCacheHandler(cacheService, responseCacheService)
+	healthHandler := handler.NewHealthHandler(healthService)
+
+	// Initialize middleware
+	authMiddleware := middleware.NewAuthMiddleware(authService, apiKeyService, tenantRegistry)
+	auditMiddleware := middleware.AuditDataRead(auditService)
+	responseCache := middleware.NewResponseCache(responseCacheService)
+
+	// Create Fiber app
+	// Behind a reverse proxy, client IPs come from its header so that login
+	// throttling, API key allowlists and the audit trail see the real client
+	app := fiber.New(fiber.Config{
+		AppName:                 "NERP Wrapper API",
+		ProxyHeader:             cfg.Server.ProxyHeader,
+		EnableTrustedProxyCheck: cfg.Server.ProxyHeader != "",
+		TrustedProxies:          cfg.Server.TrustedProxies,
+		EnableIPValidation:      true,
+	})
+
+	// Middleware
+	app.Use(recover.New())
+	app.Use(logger.New())
+	app.Use(cors.New(cors.Config{
+		AllowOrigins: strings.Join(cfg.Server.CORSOrigins, ","),
+		AllowHeaders: "Origin, Content-Type, Accept, Authorization, X-API-Key, X-Tenant",
+	}))
+	app.Use(middleware.NewTenantMiddleware(tenantRegistry))
+	app.Use(middleware.RequestContext())
+
+	// Setup routes
+	router.SetupRouter(app, authHandler, saleHandler, invoiceHandler, apiKeyHandler, loginGuardHandler, totpHandler, auditHandler, cacheHandler, healthHandler, authMiddleware, auditMiddleware, responseCache)
+
+	srv.app = app
+	srv.health = healthService
+	return srv, nil
+}
+
+// Close closes the stores of the server, once requests have completed
+func (s *server) Close() error {
+	var errs []error
+	for i := len(s.closers) - 1; i >= 0; i-- {
+		errs = append(errs, s.closers[i]())
+	}
+	return errors.Join(errs...)
+}